@@ -27,11 +27,37 @@ func main() {
 
 	collectors = append(
 		collectors,
-		exporter.NewJobCollector(slog.Default(), nil, nil, nil, config.Load().Target, true, "", 0, 0, nil).Metrics()...,
+		exporter.NewJobCollector(slog.Default(), nil, nil, nil, config.Load().Target, config.Load().Collector).Metrics()...,
+	)
+
+	collectors = append(
+		collectors,
+		exporter.NewNodeCollector(slog.Default(), nil, nil, nil, config.Load().Target).Metrics()...,
+	)
+
+	collectors = append(
+		collectors,
+		exporter.NewQueueCollector(slog.Default(), nil, nil, nil, config.Load().Target).Metrics()...,
+	)
+
+	collectors = append(
+		collectors,
+		exporter.NewLoadCollector(slog.Default(), nil, nil, nil, config.Load().Target).Metrics()...,
+	)
+
+	collectors = append(
+		collectors,
+		exporter.NewTestResultCollector(slog.Default(), nil, nil, nil, config.Load().Target, config.Load().Collector, nil).Metrics()...,
 	)
 
 	metrics := make([]metric, 0)
 
+	metrics = append(metrics, metric{
+		Name:   "jenkins_exporter_build_info",
+		Help:   "A metric with a constant '1' value labeled by version, revision and goversion from which it was built.",
+		Labels: []string{"version", "revision", "goversion"},
+	})
+
 	metrics = append(metrics, metric{
 		Name:   "jenkins_request_duration_seconds",
 		Help:   "Histogram of latencies for requests to the api per collector",
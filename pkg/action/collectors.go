@@ -0,0 +1,38 @@
+package action
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// AvailableCollectors defines the collectors which can be enabled.
+var AvailableCollectors = []string{
+	"jobs",
+	"nodes",
+	"queue",
+	"load",
+	"tests",
+}
+
+// parseCollectors validates the list of enabled collectors and returns it
+// trimmed and deduplicated while keeping the given order.
+func parseCollectors(values []string) ([]string, error) {
+	result := make([]string, 0, len(values))
+
+	for _, value := range values {
+		name := strings.ToLower(strings.TrimSpace(value))
+
+		if name == "" || slices.Contains(result, name) {
+			continue
+		}
+
+		if !slices.Contains(AvailableCollectors, name) {
+			return nil, fmt.Errorf("unknown collector %q, available: %s", name, strings.Join(AvailableCollectors, ", "))
+		}
+
+		result = append(result, name)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,64 @@
+package action
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/promhippie/jenkins_exporter/pkg/config"
+	"github.com/promhippie/jenkins_exporter/pkg/exporter"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCollectors(t *testing.T) {
+	collectors, err := parseCollectors([]string{"nodes", " JOBS ", "nodes"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"nodes", "jobs"}, collectors)
+
+	_, err = parseCollectors([]string{"jobs", "unknown"})
+	assert.ErrorContains(t, err, `unknown collector "unknown"`)
+}
+
+func TestRegisterCollectorsOnlyListed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/computer/api/json":
+			_, _ = io.WriteString(w, `{"computer": [{"displayName": "agent-1", "offline": true, "offlineCauseReason": "maintenance"}]}`)
+		default:
+			_, _ = io.WriteString(w, `{"quietingDown": false, "jobs": []}`)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := jenkins.NewClient(
+		jenkins.WithEndpoint(srv.URL),
+		jenkins.WithTimeout(5*time.Second),
+	)
+	assert.NoError(t, err)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := config.Load()
+	cfg.Target.Timeout = 5 * time.Second
+	cfg.Collector.Collectors = []string{"nodes"}
+
+	jobCollector := exporter.NewJobCollector(logger, client, requestFailures, requestDuration, cfg.Target, cfg.Collector)
+
+	reg := prometheus.NewRegistry()
+	registerCollectors(reg, cfg, logger, client, jobCollector, nil, nil, nil)
+
+	families, err := reg.Gather()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, families)
+
+	for _, family := range families {
+		assert.True(t, strings.HasPrefix(family.GetName(), "jenkins_node_"), family.GetName())
+	}
+}
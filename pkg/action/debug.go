@@ -0,0 +1,54 @@
+package action
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
+)
+
+// debugJob defines a job record of the database as served by allJobs.
+type debugJob struct {
+	JobName       string     `json:"job_name"`
+	Enabled       bool       `json:"enabled"`
+	LastSeenBuild int64      `json:"last_seen_build"`
+	LastSyncTime  *time.Time `json:"last_sync_time"`
+}
+
+// allJobs serves all jobs of the database as JSON, including the jobs which
+// have been disabled by Discovery. It's only mounted in debug mode.
+func allJobs(logger *slog.Logger, buildCollector *jenkins.BuildCollector) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		records, err := buildCollector.AllJobs()
+
+		if err != nil {
+			logger.Error("读取 job 列表失败",
+				"错误", err,
+			)
+
+			http.Error(w, "failed to read jobs", http.StatusInternalServerError)
+			return
+		}
+
+		result := make([]debugJob, 0, len(records))
+		for _, record := range records {
+			result = append(result, debugJob{
+				JobName:       record.JobName,
+				Enabled:       record.Enabled,
+				LastSeenBuild: record.LastSeenBuild,
+				LastSyncTime:  record.LastSyncTime,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			logger.Warn("写入 job 列表失败",
+				"错误", err,
+			)
+		}
+	}
+}
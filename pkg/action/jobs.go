@@ -0,0 +1,56 @@
+package action
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/promhippie/jenkins_exporter/pkg/exporter"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
+)
+
+// jobs serves the current status of all jobs as JSON, it's based on the
+// database in SQLite mode or on the cached job list otherwise and never
+// requests Jenkins.
+func jobs(logger *slog.Logger, jobCollector *exporter.JobCollector, buildCollector *jenkins.BuildCollector) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		var statuses []jenkins.JobStatus
+
+		switch {
+		case buildCollector != nil:
+			result, err := buildCollector.Statuses()
+
+			if err != nil {
+				logger.Error("读取 job 状态失败",
+					"错误", err,
+				)
+
+				http.Error(w, "failed to read job statuses", http.StatusInternalServerError)
+				return
+			}
+
+			statuses = result
+		case jobCollector != nil:
+			result, ok := jobCollector.Statuses()
+
+			if !ok {
+				http.Error(w, "job statuses require SQLite or a cache file", http.StatusServiceUnavailable)
+				return
+			}
+
+			statuses = result
+		default:
+			http.Error(w, "job statuses are not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(w).Encode(statuses); err != nil {
+			logger.Warn("写入 job 状态失败",
+				"错误", err,
+			)
+		}
+	}
+}
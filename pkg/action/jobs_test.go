@@ -0,0 +1,116 @@
+package action
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/promhippie/jenkins_exporter/pkg/config"
+	"github.com/promhippie/jenkins_exporter/pkg/exporter"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobs(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "jobs.json")
+	assert.NoError(t, os.WriteFile(cacheFile, []byte(`[
+		{"fullName": "team/app", "color": "red", "lastBuild": {"number": 12}},
+		{"fullName": "fresh", "color": "notbuilt"}
+	]`), 0o600))
+
+	client, err := jenkins.NewClient(
+		jenkins.WithEndpoint("http://jenkins.invalid"),
+		jenkins.WithTimeout(5*time.Second),
+	)
+	assert.NoError(t, err)
+
+	registry = prometheus.NewRegistry()
+
+	cfg := config.Load()
+	cfg.Server.Path = "/metrics"
+	cfg.Collector.CacheFile = cacheFile
+	cfg.Collector.CacheTTL = time.Hour
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	jobCollector := exporter.NewJobCollector(logger, client, nil, nil, cfg.Target, cfg.Collector)
+
+	rec := httptest.NewRecorder()
+	handler(cfg, logger, client, nil, jobCollector, nil, nil, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var statuses []jenkins.JobStatus
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&statuses))
+	assert.Equal(t, []jenkins.JobStatus{
+		{JobName: "team/app", Status: "failure", LastBuild: 12},
+		{JobName: "fresh", Status: "not_built"},
+	}, statuses)
+
+	// 没有缓存文件时无法在不请求 Jenkins 的情况下返回状态
+	cfg.Collector.CacheFile = ""
+	registry = prometheus.NewRegistry()
+
+	rec = httptest.NewRecorder()
+	handler(cfg, logger, client, nil, exporter.NewJobCollector(logger, client, nil, nil, cfg.Target, cfg.Collector), nil, nil, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestAllJobs(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	db, err := storage.NewSQLite(filepath.Join(t.TempDir(), "jobs.db"), logger)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	repo := storage.NewJobRepo(db, logger)
+	assert.NoError(t, repo.SyncJobs([]string{"team/app", "gone"}))
+	assert.NoError(t, repo.SyncJobs([]string{"team/app"}))
+	assert.NoError(t, repo.UpdateLastSeenBatch(map[string]storage.BuildUpdate{"team/app": {LastSeenBuild: 12}}))
+
+	client, err := jenkins.NewClient(
+		jenkins.WithEndpoint("http://jenkins.invalid"),
+		jenkins.WithTimeout(5*time.Second),
+	)
+	assert.NoError(t, err)
+
+	buildCollector := jenkins.NewBuildCollector(client, repo, logger, 1)
+
+	cfg := config.Load()
+	cfg.Server.Path = "/metrics"
+	cfg.Collector.Collectors = nil
+
+	// 未启用调试模式时不提供完整的 job 列表
+	registry = prometheus.NewRegistry()
+
+	rec := httptest.NewRecorder()
+	handler(cfg, logger, client, nil, nil, buildCollector, nil, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs/all", nil))
+	assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+
+	cfg.Server.Pprof = true
+	registry = prometheus.NewRegistry()
+
+	rec = httptest.NewRecorder()
+	handler(cfg, logger, client, nil, nil, buildCollector, nil, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs/all", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var records []map[string]any
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&records))
+	assert.Len(t, records, 2)
+
+	assert.Equal(t, "gone", records[0]["job_name"])
+	assert.Equal(t, false, records[0]["enabled"])
+
+	assert.Equal(t, "team/app", records[1]["job_name"])
+	assert.Equal(t, true, records[1]["enabled"])
+	assert.Equal(t, float64(12), records[1]["last_seen_build"])
+	assert.NotNil(t, records[1]["last_sync_time"])
+}
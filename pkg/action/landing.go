@@ -0,0 +1,45 @@
+package action
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/promhippie/jenkins_exporter/pkg/config"
+	"github.com/promhippie/jenkins_exporter/pkg/version"
+)
+
+var landingTemplate = template.Must(template.New("landing").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Jenkins Exporter</title>
+</head>
+<body>
+<h1>Jenkins Exporter</h1>
+<p>Version {{ .Version }} (revision {{ .Revision }})</p>
+<ul>
+<li><a href="{{ .Path }}">Metrics</a></li>
+<li><a href="/healthz">Health</a></li>
+<li><a href="/readyz">Readiness</a></li>
+</ul>
+</body>
+</html>
+`))
+
+// landing serves a minimal landing page linking to the available endpoints.
+func landing(cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+
+		_ = landingTemplate.Execute(w, struct {
+			Version  string
+			Revision string
+			Path     string
+		}{
+			Version:  version.String,
+			Revision: version.Revision,
+			Path:     cfg.Server.Path,
+		})
+	}
+}
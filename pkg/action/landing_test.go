@@ -0,0 +1,31 @@
+package action
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/promhippie/jenkins_exporter/pkg/config"
+	"github.com/promhippie/jenkins_exporter/pkg/version"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLandingPage(t *testing.T) {
+	registry = prometheus.NewRegistry()
+
+	cfg := config.Load()
+	cfg.Server.Path = "/metrics"
+
+	mux := handler(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, nil, nil, nil)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, rec.Body.String(), `<a href="/metrics">`)
+	assert.Contains(t, rec.Body.String(), version.String)
+}
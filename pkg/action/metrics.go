@@ -3,6 +3,8 @@ package action
 import (
 	"fmt"
 	"log/slog"
+	"regexp"
+	"strings"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
@@ -33,18 +35,75 @@ var (
 		},
 		[]string{"collector"},
 	)
+
+	rateLimited = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rate_limited_total",
+			Help:      "Total number of requests to the api rejected with 429 Too Many Requests.",
+		},
+	)
+
+	authFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "request_auth_failures_total",
+			Help:      "Total number of requests to the api rejected with 401 Unauthorized or 403 Forbidden.",
+		},
+	)
+)
+
+var (
+	labelNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
 )
 
-func init() {
-	registry.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{
+// newRegisterer wraps the registry to attach the constant labels to every
+// metric and registers the default exporter metrics on it.
+func newRegisterer(labels prometheus.Labels) prometheus.Registerer {
+	reg := prometheus.WrapRegistererWith(labels, registry)
+
+	reg.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{
 		Namespace: namespace,
 	}))
 
-	registry.MustRegister(collectors.NewGoCollector())
-	registry.MustRegister(version.Collector(namespace))
+	reg.MustRegister(collectors.NewGoCollector())
+	reg.MustRegister(version.Collector(namespace))
+
+	// 使用 exporter 惯用的指标名称，保留上面的旧名称以兼容已有面板
+	reg.MustRegister(version.Collector(namespace + "_exporter"))
+
+	reg.MustRegister(requestDuration)
+	reg.MustRegister(requestFailures)
+	reg.MustRegister(rateLimited)
+	reg.MustRegister(authFailures)
+
+	return reg
+}
+
+// parseLabels parses and validates a list of key=value constant labels.
+func parseLabels(values []string) (prometheus.Labels, error) {
+	labels := prometheus.Labels{}
+
+	for _, value := range values {
+		name, val, found := strings.Cut(value, "=")
+		name = strings.TrimSpace(name)
+
+		if !found {
+			return nil, fmt.Errorf("invalid label %q, expected key=value", value)
+		}
+
+		if !labelNameRegexp.MatchString(name) || strings.HasPrefix(name, "__") {
+			return nil, fmt.Errorf("invalid label name %q", name)
+		}
+
+		if _, ok := labels[name]; ok {
+			return nil, fmt.Errorf("duplicate label name %q", name)
+		}
+
+		labels[name] = strings.TrimSpace(val)
+	}
 
-	registry.MustRegister(requestDuration)
-	registry.MustRegister(requestFailures)
+	return labels, nil
 }
 
 type promLogger struct {
@@ -0,0 +1,78 @@
+package action
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/promhippie/jenkins_exporter/pkg/version"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRegistererConstLabels(t *testing.T) {
+	registry = prometheus.NewRegistry()
+
+	labels, err := parseLabels([]string{"cluster=prod", "region=us-east"})
+	assert.NoError(t, err)
+
+	newRegisterer(labels)
+
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+
+	found := false
+
+	for _, family := range families {
+		if family.GetName() != "jenkins_build_info" {
+			continue
+		}
+
+		found = true
+		values := map[string]string{}
+
+		for _, pair := range family.GetMetric()[0].GetLabel() {
+			values[pair.GetName()] = pair.GetValue()
+		}
+
+		assert.Equal(t, "prod", values["cluster"])
+		assert.Equal(t, "us-east", values["region"])
+	}
+
+	assert.True(t, found)
+}
+
+func TestNewRegistererBuildInfo(t *testing.T) {
+	registry = prometheus.NewRegistry()
+	newRegisterer(prometheus.Labels{})
+
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+
+	found := false
+
+	for _, family := range families {
+		if family.GetName() != "jenkins_exporter_build_info" {
+			continue
+		}
+
+		found = true
+		values := map[string]string{}
+
+		for _, pair := range family.GetMetric()[0].GetLabel() {
+			values[pair.GetName()] = pair.GetValue()
+		}
+
+		assert.Equal(t, version.String, values["version"])
+		assert.Equal(t, version.Revision, values["revision"])
+		assert.Equal(t, version.Go, values["goversion"])
+		assert.Equal(t, 1.0, family.GetMetric()[0].GetGauge().GetValue())
+	}
+
+	assert.True(t, found)
+}
+
+func TestParseLabelsInvalid(t *testing.T) {
+	for _, value := range []string{"cluster", "bad-name=x", "__reserved=x", "0start=x"} {
+		_, err := parseLabels([]string{value})
+		assert.Error(t, err, value)
+	}
+}
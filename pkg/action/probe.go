@@ -0,0 +1,38 @@
+package action
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
+)
+
+// probe serves the last build result of the job given by the job parameter,
+// every request uses a fresh registry so labels don't leak between probes.
+func probe(logger *slog.Logger, labels prometheus.Labels, prober *jenkins.BuildCollector) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		job := r.URL.Query().Get("job")
+
+		if job == "" {
+			http.Error(w, "missing job parameter", http.StatusBadRequest)
+			return
+		}
+
+		if prober == nil {
+			http.Error(w, "probe is not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		probeRegistry := prometheus.NewRegistry()
+		prometheus.WrapRegistererWith(labels, probeRegistry).MustRegister(prober.Probe(r.Context(), job))
+
+		promhttp.HandlerFor(
+			probeRegistry,
+			promhttp.HandlerOpts{
+				ErrorLog: promLogger{logger},
+			},
+		).ServeHTTP(w, r)
+	}
+}
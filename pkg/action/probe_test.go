@@ -0,0 +1,56 @@
+package action
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/promhippie/jenkins_exporter/pkg/config"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbe(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch strings.TrimRight(r.URL.Path, "/") {
+		case "/job/app/api/json":
+			_, _ = io.WriteString(w, `{"_class": "hudson.model.FreeStyleProject", "name": "app", "lastCompletedBuild": {"number": 2, "url": "`+srv.URL+`/job/app/2/"}}`)
+		case "/job/app/2/api/json":
+			_, _ = io.WriteString(w, `{"number": 2, "result": "SUCCESS"}`)
+		default:
+			_, _ = io.WriteString(w, `{"jobs": []}`)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := jenkins.NewClient(
+		jenkins.WithEndpoint(srv.URL),
+		jenkins.WithTimeout(5*time.Second),
+	)
+	assert.NoError(t, err)
+
+	registry = prometheus.NewRegistry()
+
+	cfg := config.Load()
+	cfg.Server.Path = "/metrics"
+
+	mux := handler(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), client, nil, nil, nil, nil, nil)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/probe", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/probe?job=app", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `jenkins_build_last_result{check_commitID="",gitBranch="",job_name="app",status="success"} 1`)
+	assert.Contains(t, rec.Body.String(), "jenkins_probe_success 1")
+}
@@ -0,0 +1,59 @@
+package action
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/promhippie/jenkins_exporter/pkg/internal/storage"
+)
+
+// pruneInterval defines how often disabled jobs and old job changes get
+// deleted from the database.
+const pruneInterval = time.Hour
+
+// startPrune periodically deletes the jobs which have been disabled for longer
+// than olderThan, together with the job changes older than olderThan.
+func startPrune(ctx context.Context, repo *storage.JobRepo, olderThan time.Duration, logger *slog.Logger) error {
+	logger = logger.With("component", "prune")
+
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		pruneOnce(repo, olderThan, logger)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// pruneOnce deletes disabled jobs and old job changes a single time.
+func pruneOnce(repo *storage.JobRepo, olderThan time.Duration, logger *slog.Logger) {
+	jobs, err := repo.PruneDisabledJobs(olderThan)
+
+	if err != nil {
+		logger.Warn("删除已禁用的 job 失败",
+			"err", err,
+		)
+	}
+
+	changes, err := repo.PruneJobChanges(olderThan)
+
+	if err != nil {
+		logger.Warn("删除过期的 job 变更记录失败",
+			"err", err,
+		)
+	}
+
+	if jobs > 0 || changes > 0 {
+		logger.Info("已清理数据库",
+			"删除的 job", jobs,
+			"删除的变更记录", changes,
+			"保留时长", olderThan,
+		)
+	}
+}
@@ -2,17 +2,21 @@ package action
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/oklog/run"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/prometheus/exporter-toolkit/web"
 	"github.com/promhippie/jenkins_exporter/pkg/config"
@@ -32,6 +36,28 @@ func Server(cfg *config.Config, logger *slog.Logger) error {
 		"Go版本", version.Go,
 	)
 
+	labels, err := parseLabels(cfg.Server.Labels)
+
+	if err != nil {
+		logger.Error("解析全局标签失败",
+			"错误", err,
+		)
+
+		return err
+	}
+
+	collectors, err := parseCollectors(cfg.Collector.Collectors)
+
+	if err != nil {
+		logger.Error("解析收集器列表失败",
+			"错误", err,
+		)
+
+		return err
+	}
+
+	cfg.Collector.Collectors = collectors
+
 	username, err := config.Value(cfg.Target.Username)
 
 	if err != nil {
@@ -52,17 +78,81 @@ func Server(cfg *config.Config, logger *slog.Logger) error {
 		return err
 	}
 
+	token, err := config.Value(cfg.Target.Token)
+
+	if err != nil {
+		logger.Error("从文件加载 API token 失败",
+			"错误", err,
+		)
+
+		return err
+	}
+
 	logger.Info("正在连接 Jenkins",
 		"address", cfg.Target.Address,
 		"timeout", cfg.Target.Timeout,
+		"认证方式", credentialType(username, password, token),
 	)
 
-	client, err := jenkins.NewClient(
+	// 正则表达式已在启动时校验，这里不会失败
+	jobFilter, _ := jenkins.NewJobFilter(cfg.Collector.IncludeJobRegex, cfg.Collector.ExcludeJobRegex)
+
+	options := []jenkins.ClientOption{
 		jenkins.WithEndpoint(cfg.Target.Address),
 		jenkins.WithUsername(username),
 		jenkins.WithPassword(password),
+		jenkins.WithToken(token),
+		jenkins.WithAuthMode(cfg.Target.AuthMode),
 		jenkins.WithTimeout(cfg.Target.Timeout),
-	)
+		jenkins.WithTLSServerName(cfg.Target.TLSServerName),
+		jenkins.WithInsecureSkipVerify(cfg.Target.InsecureSkipVerify),
+		jenkins.WithCACertFile(cfg.Target.CACertFile),
+		jenkins.WithMaxResponseSize(cfg.Target.MaxResponseSize),
+		jenkins.WithCrumb(cfg.Target.Crumb),
+		jenkins.WithRetry(cfg.Target.RetryAttempts, cfg.Target.RetryDelay),
+		jenkins.WithBackpressure(cfg.Target.MaxInFlight, rateLimited),
+		jenkins.WithAuthFailures(authFailures),
+		jenkins.WithSDKFallback(cfg.Collector.SDKFallback),
+		jenkins.WithFolderGlob(cfg.Collector.FoldersGlob),
+		jenkins.WithFolderDepth(cfg.Collector.FolderDepth),
+		jenkins.WithFolderTimeout(cfg.Collector.FolderTimeout),
+		jenkins.WithJobFilter(jobFilter),
+		jenkins.WithLogger(logger),
+	}
+
+	// 保留原始 DSN，认证失败时重新解析以获取轮换后的凭据
+	if cfg.Target.ReloadCredentials {
+		options = append(options, jenkins.WithCredentialsReload(func() (string, string, error) {
+			username, err := config.Value(cfg.Target.Username)
+
+			if err != nil {
+				return "", "", err
+			}
+
+			// 与启动时一致，API token 优先于密码
+			if cfg.Target.Token != "" {
+				token, err := config.Value(cfg.Target.Token)
+
+				if err != nil {
+					return "", "", err
+				}
+
+				if token != "" {
+					return username, token, nil
+				}
+			}
+
+			password, err := config.Value(cfg.Target.Password)
+
+			if err != nil {
+				return "", "", err
+			}
+
+			return username, password, nil
+		}))
+	}
+
+	client, err := jenkins.NewClient(options...)
 
 	if err != nil {
 		logger.Error("连接 Jenkins 失败",
@@ -77,13 +167,20 @@ func Server(cfg *config.Config, logger *slog.Logger) error {
 		"address", cfg.Target.Address,
 	)
 
+	// 凭据错误时后续请求都会失败，只会表现为没有任何 job，启动时明确提示
+	checkConnection(client, cfg.Target.Timeout, logger)
+
 	var gr run.Group
 	var jobCollector *exporter.JobCollector
 	var buildCollector *jenkins.BuildCollector
 	var jobRepo *storage.JobRepo
+	var discoveryMetrics *jenkins.DiscoveryMetrics
+	var dbMetrics *storage.DBMetrics
+
+	jobsEnabled := slices.Contains(cfg.Collector.Collectors, "jobs")
 
 	// 如果启用了 SQLite，使用 SQLite 模式（推荐）
-	if cfg.Collector.Jobs && cfg.Collector.SQLitePath != "" {
+	if jobsEnabled && cfg.Collector.SQLitePath != "" {
 		logger.Info("正在初始化 SQLite 数据库",
 			"数据库路径", cfg.Collector.SQLitePath,
 		)
@@ -97,11 +194,13 @@ func Server(cfg *config.Config, logger *slog.Logger) error {
 		}
 
 		jobRepo = storage.NewJobRepo(db, logger)
+		dbMetrics = storage.NewDBMetrics(cfg.Collector.SQLitePath)
 
 		// 解析文件夹列表
 		folders := jenkins.GetJobNamesFromFolders(cfg.Collector.FoldersStr)
 
 		// 启动 Job Discovery（低频同步）
+		discoveryMetrics = jenkins.NewDiscoveryMetrics()
 		discoveryCtx, discoveryCancel := context.WithCancel(context.Background())
 		gr.Add(func() error {
 			return jenkins.StartDiscovery(
@@ -110,6 +209,10 @@ func Server(cfg *config.Config, logger *slog.Logger) error {
 				jobRepo,
 				cfg.Collector.DiscoveryInterval,
 				folders,
+				cfg.Collector.ExcludedJobs,
+				cfg.Collector.ExcludedFolders,
+				cfg.Collector.StrictFolders,
+				discoveryMetrics,
 				logger,
 			)
 		}, func(_ error) {
@@ -117,7 +220,29 @@ func Server(cfg *config.Config, logger *slog.Logger) error {
 		})
 
 		// 创建并启动 Build Collector（按需采集）
-		buildCollector = jenkins.NewBuildCollector(client, jobRepo, logger, cfg.Collector.CollectorConcurrency)
+		buildCollector = jenkins.NewBuildCollector(
+			client,
+			jobRepo,
+			logger,
+			cfg.Collector.CollectorConcurrency,
+			jenkins.WithDiscoveryWaitRetries(cfg.Collector.DiscoveryWaitRetries),
+			jenkins.WithBuildingResults(cfg.Collector.BuildingResults),
+			jenkins.WithEmptyResultStatus(cfg.Collector.EmptyResultStatus),
+			jenkins.WithConfigChanges(cfg.Collector.ConfigChanges),
+			jenkins.WithExcludedJobs(cfg.Collector.ExcludedJobs),
+			jenkins.WithExcludedFolders(cfg.Collector.ExcludedFolders),
+			jenkins.WithExclusionsFile(cfg.Collector.ExcludeFile, cfg.Collector.ExcludeFileInterval),
+			jenkins.WithMaxCollectionTime(cfg.Collector.MaxCollectionTime),
+			jenkins.WithStatusMaxAge(cfg.Collector.StatusMaxAge),
+			jenkins.WithMinCollectInterval(cfg.Collector.MinCollectInterval),
+			jenkins.WithPersistCounters(cfg.Collector.PersistCounters),
+			jenkins.WithPendingFirstBuild(cfg.Collector.PendingFirstBuild),
+			jenkins.WithResultLabel(cfg.Collector.ResultLabel),
+			jenkins.WithPriorityJobs(cfg.Collector.PriorityJobs),
+			jenkins.WithMinBuildNumber(int64(cfg.Collector.MinBuildNumber)),
+			jenkins.WithParamLabels(cfg.Collector.ParamLabels),
+			jenkins.WithMaxSeries(cfg.Collector.MaxSeries),
+		)
 		collectorCtx, collectorCancel := context.WithCancel(context.Background())
 		gr.Add(func() error {
 			return buildCollector.Start(collectorCtx, cfg.Collector.CollectorInterval)
@@ -125,25 +250,32 @@ func Server(cfg *config.Config, logger *slog.Logger) error {
 			collectorCancel()
 		})
 
+		// 定期删除已禁用的 job 和过期的审计日志
+		if cfg.Collector.PruneAfter > 0 {
+			pruneCtx, pruneCancel := context.WithCancel(context.Background())
+			gr.Add(func() error {
+				return startPrune(pruneCtx, jobRepo, cfg.Collector.PruneAfter, logger)
+			}, func(_ error) {
+				pruneCancel()
+			})
+		}
+
 		logger.Info("SQLite 模式已启用",
 			"Discovery 间隔", cfg.Collector.DiscoveryInterval,
 			"Collector 间隔", cfg.Collector.CollectorInterval,
 		)
-	} else if cfg.Collector.Jobs {
+	} else if jobsEnabled {
 		// 传统模式：使用 JSON 缓存（不推荐，仅用于兼容）
 		logger.Info("使用传统模式（JSON 缓存），建议使用 SQLite 模式以获得更好的性能",
 			"提示", "设置 --collector.jobs.sqlite-path 启用 SQLite 模式",
 		)
-		// 解析逗号分隔的文件夹字符串
-		var folders []string
-		if cfg.Collector.FoldersStr != "" {
-			parts := strings.Split(cfg.Collector.FoldersStr, ",")
-			for _, part := range parts {
-				trimmed := strings.TrimSpace(part)
-				if trimmed != "" {
-					folders = append(folders, trimmed)
-				}
-			}
+
+		// 排除列表文件只由 Build Collector 读取，传统模式下不生效
+		if cfg.Collector.ExcludeFile != "" {
+			logger.Warn("传统模式不支持排除列表文件，该配置将被忽略",
+				"排除列表文件", cfg.Collector.ExcludeFile,
+				"提示", "设置 --collector.jobs.sqlite-path 启用 SQLite 模式，或改用 --collector.jobs.exclude-jobs",
+			)
 		}
 
 		jobCollector = exporter.NewJobCollector(
@@ -152,11 +284,7 @@ func Server(cfg *config.Config, logger *slog.Logger) error {
 			requestFailures,
 			requestDuration,
 			cfg.Target,
-			cfg.Collector.FetchBuildDetails,
-			cfg.Collector.CacheFile,
-			cfg.Collector.CacheTTL,
-			cfg.Collector.CacheRefreshInterval,
-			folders,
+			cfg.Collector,
 		)
 
 		// 在启动时初始化缓存文件
@@ -191,7 +319,7 @@ func Server(cfg *config.Config, logger *slog.Logger) error {
 	{
 		server := &http.Server{
 			Addr:         cfg.Server.Addr,
-			Handler:      handler(cfg, logger, client, jobCollector, buildCollector),
+			Handler:      handler(cfg, logger, client, labels, jobCollector, buildCollector, discoveryMetrics, dbMetrics),
 			ReadTimeout:  5 * time.Second,
 			WriteTimeout: cfg.Server.Timeout,
 		}
@@ -257,7 +385,7 @@ func Server(cfg *config.Config, logger *slog.Logger) error {
 	return gr.Run()
 }
 
-func handler(cfg *config.Config, logger *slog.Logger, client *jenkins.Client, jobCollector *exporter.JobCollector, buildCollector *jenkins.BuildCollector) *chi.Mux {
+func handler(cfg *config.Config, logger *slog.Logger, client *jenkins.Client, labels prometheus.Labels, jobCollector *exporter.JobCollector, buildCollector *jenkins.BuildCollector, discoveryMetrics *jenkins.DiscoveryMetrics, dbMetrics *storage.DBMetrics) *chi.Mux {
 	mux := chi.NewRouter()
 	mux.Use(middleware.Recoverer(logger))
 	mux.Use(middleware.RealIP)
@@ -268,57 +396,72 @@ func handler(cfg *config.Config, logger *slog.Logger, client *jenkins.Client, jo
 		mux.Mount("/debug", middleware.Profiler())
 	}
 
-	// 如果使用 SQLite 模式，注册 Build Collector
-	if buildCollector != nil {
-		logger.Info("已注册 Build Collector（SQLite 模式）")
-		registry.MustRegister(buildCollector)
-	}
-
-	// 如果使用传统模式，注册 JobCollector（仅当未使用 SQLite 时）
-	if cfg.Collector.Jobs && jobCollector != nil && cfg.Collector.SQLitePath == "" {
-		// 解析逗号分隔的文件夹字符串（用于日志）
-		var folders []string
-		if cfg.Collector.FoldersStr != "" {
-			parts := strings.Split(cfg.Collector.FoldersStr, ",")
-			for _, part := range parts {
-				trimmed := strings.TrimSpace(part)
-				if trimmed != "" {
-					folders = append(folders, trimmed)
-				}
-			}
-		}
-
-		if len(folders) > 0 {
-			logger.Info("已注册作业收集器（传统模式）",
-				"获取构建详情", cfg.Collector.FetchBuildDetails,
-				"指定文件夹", folders,
-			)
-		} else {
-			logger.Info("已注册作业收集器（传统模式）",
-				"获取构建详情", cfg.Collector.FetchBuildDetails,
-				"说明", "将获取所有文件夹下的作业",
-			)
-		}
-
-		registry.MustRegister(jobCollector)
-	}
+	registerCollectors(newRegisterer(labels), cfg, logger, client, jobCollector, buildCollector, discoveryMetrics, dbMetrics)
 
-	reg := promhttp.HandlerFor(
+	metrics := promhttp.HandlerFor(
 		registry,
 		promhttp.HandlerOpts{
 			ErrorLog: promLogger{logger},
 		},
 	)
 
+	// 传统模式下没有 Build Collector，单独创建一个仅用于探测
+	prober := buildCollector
+	if prober == nil && client != nil {
+		prober = jenkins.NewBuildCollector(
+			client,
+			nil,
+			logger,
+			1,
+			jenkins.WithBuildingResults(cfg.Collector.BuildingResults),
+			jenkins.WithEmptyResultStatus(cfg.Collector.EmptyResultStatus),
+		)
+	}
+
 	mux.NotFound(func(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, cfg.Server.Path, http.StatusMovedPermanently)
 	})
 
 	mux.Route("/", func(root chi.Router) {
+		// 指标路径不是根路径时，在根路径提供简单的首页
+		if cfg.Server.Path != "/" {
+			root.Get("/", landing(cfg))
+		}
+
 		root.Get(cfg.Server.Path, func(w http.ResponseWriter, r *http.Request) {
-			reg.ServeHTTP(w, r)
+			// 指定 since 时只导出该时间之后有新构建的 job（仅 SQLite 模式）
+			if value := r.URL.Query().Get("since"); value != "" && buildCollector != nil {
+				since, err := parseSince(value)
+
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+
+				sinceRegistry := prometheus.NewRegistry()
+				prometheus.WrapRegistererWith(labels, sinceRegistry).MustRegister(buildCollector.Since(since))
+
+				promhttp.HandlerFor(
+					sinceRegistry,
+					promhttp.HandlerOpts{
+						ErrorLog: promLogger{logger},
+					},
+				).ServeHTTP(w, r)
+
+				return
+			}
+
+			metrics.ServeHTTP(w, r)
 		})
 
+		root.Get("/probe", probe(logger, labels, prober))
+		root.Get("/jobs", jobs(logger, jobCollector, buildCollector))
+
+		// 调试模式下提供数据库中的全部 job，包括已禁用的 job
+		if cfg.Server.Pprof && buildCollector != nil {
+			root.Get("/jobs/all", allJobs(logger, buildCollector))
+		}
+
 		root.Get("/healthz", func(w http.ResponseWriter, _ *http.Request) {
 			w.Header().Set("Content-Type", "text/plain")
 			w.WriteHeader(http.StatusOK)
@@ -336,3 +479,150 @@ func handler(cfg *config.Config, logger *slog.Logger, client *jenkins.Client, jo
 
 	return mux
 }
+
+// credentialType returns the type of the used credentials for logging,
+// without exposing the secret itself.
+func credentialType(username, password, token string) string {
+	switch {
+	case username == "":
+		return "none"
+	case token != "":
+		return "token"
+	case password != "":
+		return "password"
+	default:
+		return "none"
+	}
+}
+
+// parseSince parses the since query parameter, either as unix timestamp or
+// as RFC3339 formatted time.
+func parseSince(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+
+	since, err := time.Parse(time.RFC3339, value)
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid since parameter %q, expected unix timestamp or RFC3339", value)
+	}
+
+	return since, nil
+}
+
+// checkConnection requests the status of Jenkins once and logs a clear
+// message if the configured credentials are rejected, the exporter keeps
+// running as the credentials may be reloaded later.
+func checkConnection(client *jenkins.Client, timeout time.Duration, logger *slog.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err := client.Job.Status(ctx)
+
+	switch {
+	case errors.Is(err, jenkins.ErrUnauthorized):
+		logger.Error("Jenkins 认证失败，请检查用户名、密码或 API token",
+			"address", client.Endpoint(),
+			"err", err,
+		)
+	case errors.Is(err, jenkins.ErrForbidden):
+		logger.Error("Jenkins 拒绝访问，请检查用户是否拥有 Overall/Read 权限",
+			"address", client.Endpoint(),
+			"err", err,
+		)
+	case err != nil:
+		logger.Warn("无法访问 Jenkins，将在采集时重试",
+			"address", client.Endpoint(),
+			"err", err,
+		)
+	}
+}
+
+// registerCollectors registers the enabled collectors in the configured order.
+func registerCollectors(reg prometheus.Registerer, cfg *config.Config, logger *slog.Logger, client *jenkins.Client, jobCollector *exporter.JobCollector, buildCollector *jenkins.BuildCollector, discoveryMetrics *jenkins.DiscoveryMetrics, dbMetrics *storage.DBMetrics) {
+	for _, name := range cfg.Collector.Collectors {
+		switch name {
+		case "jobs":
+			// 如果使用 SQLite 模式，注册 Build Collector
+			if buildCollector != nil {
+				logger.Info("已注册 Build Collector（SQLite 模式）")
+				reg.MustRegister(buildCollector, discoveryMetrics, dbMetrics)
+				continue
+			}
+
+			// 如果使用传统模式，注册 JobCollector（仅当未使用 SQLite 时）
+			if jobCollector == nil {
+				continue
+			}
+
+			// 解析逗号分隔的文件夹字符串（用于日志）
+			var folders []string
+			if cfg.Collector.FoldersStr != "" {
+				parts := strings.Split(cfg.Collector.FoldersStr, ",")
+				for _, part := range parts {
+					trimmed := strings.TrimSpace(part)
+					if trimmed != "" {
+						folders = append(folders, trimmed)
+					}
+				}
+			}
+
+			if len(folders) > 0 {
+				logger.Info("已注册作业收集器（传统模式）",
+					"获取构建详情", cfg.Collector.FetchBuildDetails,
+					"指定文件夹", folders,
+				)
+			} else {
+				logger.Info("已注册作业收集器（传统模式）",
+					"获取构建详情", cfg.Collector.FetchBuildDetails,
+					"说明", "将获取所有文件夹下的作业",
+				)
+			}
+
+			reg.MustRegister(jobCollector)
+		case "nodes":
+			logger.Info("已注册节点收集器")
+
+			reg.MustRegister(exporter.NewNodeCollector(
+				logger,
+				client,
+				requestFailures,
+				requestDuration,
+				cfg.Target,
+			))
+		case "queue":
+			logger.Info("已注册队列收集器")
+
+			reg.MustRegister(exporter.NewQueueCollector(
+				logger,
+				client,
+				requestFailures,
+				requestDuration,
+				cfg.Target,
+			))
+		case "load":
+			logger.Info("已注册负载收集器")
+
+			reg.MustRegister(exporter.NewLoadCollector(
+				logger,
+				client,
+				requestFailures,
+				requestDuration,
+				cfg.Target,
+			))
+		case "tests":
+			logger.Info("已注册测试结果收集器")
+
+			reg.MustRegister(exporter.NewTestResultCollector(
+				logger,
+				client,
+				requestFailures,
+				requestDuration,
+				cfg.Target,
+				cfg.Collector,
+				jobCollector,
+			))
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCredentialType(t *testing.T) {
+	assert.Equal(t, "token", credentialType("admin", "password", "api-token"))
+	assert.Equal(t, "token", credentialType("admin", "", "api-token"))
+	assert.Equal(t, "password", credentialType("admin", "password", ""))
+	assert.Equal(t, "none", credentialType("", "password", "api-token"))
+	assert.Equal(t, "none", credentialType("admin", "", ""))
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/promhippie/jenkins_exporter/pkg/action"
 	"github.com/promhippie/jenkins_exporter/pkg/config"
+	"github.com/promhippie/jenkins_exporter/pkg/exporter"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
 	"github.com/promhippie/jenkins_exporter/pkg/version"
 	"github.com/urfave/cli/v3"
 )
@@ -35,6 +38,41 @@ func Run() error {
 				return fmt.Errorf("missing required jenkins.url")
 			}
 
+			if !jenkins.IsBuildStatus(cfg.Collector.EmptyResultStatus) {
+				logger.Error("Invalid collector.jobs.empty-result-status", "status", cfg.Collector.EmptyResultStatus)
+				return fmt.Errorf("invalid collector.jobs.empty-result-status: %s", cfg.Collector.EmptyResultStatus)
+			}
+
+			if cfg.Collector.NameSource != exporter.NameSourcePath && cfg.Collector.NameSource != exporter.NameSourceDisplay {
+				logger.Error("Invalid collector.jobs.name-source", "source", cfg.Collector.NameSource)
+				return fmt.Errorf("invalid collector.jobs.name-source: %s", cfg.Collector.NameSource)
+			}
+
+			if !exporter.IsCacheFormat(cfg.Collector.CacheFormat) {
+				logger.Error("Invalid collector.jobs.cache-format", "format", cfg.Collector.CacheFormat)
+				return fmt.Errorf("invalid collector.jobs.cache-format: %s", cfg.Collector.CacheFormat)
+			}
+
+			if _, err := exporter.ParseNameRules(cfg.Collector.NameRules); err != nil {
+				logger.Error("Invalid collector.jobs.name-rule", "err", err)
+				return fmt.Errorf("invalid collector.jobs.name-rule: %w", err)
+			}
+
+			if cfg.Collector.SQLitePath != "" && len(cfg.Collector.NameRules) > 0 {
+				logger.Error("Unsupported collector.jobs.name-rule in SQLite mode")
+				return fmt.Errorf("collector.jobs.name-rule is not supported with collector.jobs.sqlite-path")
+			}
+
+			if err := jenkins.ValidateParamLabels(cfg.Collector.ParamLabels); err != nil {
+				logger.Error("Invalid collector.jobs.param-label", "err", err)
+				return fmt.Errorf("invalid collector.jobs.param-label: %w", err)
+			}
+
+			if _, err := jenkins.NewJobFilter(cfg.Collector.IncludeJobRegex, cfg.Collector.ExcludeJobRegex); err != nil {
+				logger.Error("Invalid collector.jobs.include-regex or collector.jobs.exclude-regex", "err", err)
+				return fmt.Errorf("invalid job regex: %w", err)
+			}
+
 			return action.Server(cfg, logger)
 		},
 	}
@@ -60,7 +98,7 @@ func RootFlags(cfg *config.Config) []cli.Flag {
 		&cli.StringFlag{
 			Name:        "log.level",
 			Value:       "info",
-			Usage:       "Only log messages with given severity",
+			Usage:       "Only log messages with given severity, verbose is like debug without the messages for every single job",
 			Sources:     cli.EnvVars("JENKINS_EXPORTER_LOG_LEVEL"),
 			Destination: &cfg.Logs.Level,
 		},
@@ -106,6 +144,13 @@ func RootFlags(cfg *config.Config) []cli.Flag {
 			Sources:     cli.EnvVars("JENKINS_EXPORTER_WEB_CONFIG"),
 			Destination: &cfg.Server.Web,
 		},
+		&cli.StringSliceFlag{
+			Name:        "label",
+			Value:       []string{},
+			Usage:       "Constant label added to all metrics in key=value format, can be repeated",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_LABELS"),
+			Destination: &cfg.Server.Labels,
+		},
 		&cli.DurationFlag{
 			Name:        "request.timeout",
 			Value:       120 * time.Second,
@@ -134,12 +179,89 @@ func RootFlags(cfg *config.Config) []cli.Flag {
 			Sources:     cli.EnvVars("JENKINS_EXPORTER_PASSWORD"),
 			Destination: &cfg.Target.Password,
 		},
+		&cli.StringFlag{
+			Name:        "jenkins.token",
+			Value:       "",
+			Usage:       "API token for the Jenkins authentication, takes precedence over the password",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_TOKEN"),
+			Destination: &cfg.Target.Token,
+		},
+		&cli.StringFlag{
+			Name:        "jenkins.auth-mode",
+			Value:       jenkins.AuthBasic,
+			Usage:       "How to send the credentials, basic for basic auth or bearer to send the token or password as bearer token",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_AUTH_MODE"),
+			Destination: &cfg.Target.AuthMode,
+		},
+		&cli.StringFlag{
+			Name:        "jenkins.tls-server-name",
+			Value:       "",
+			Usage:       "Server name used to verify the TLS certificate of Jenkins if it differs from the URL host",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_TLS_SERVER_NAME"),
+			Destination: &cfg.Target.TLSServerName,
+		},
 		&cli.BoolFlag{
-			Name:        "collector.jobs",
-			Value:       true,
-			Usage:       "Enable collector for jobs",
-			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS"),
-			Destination: &cfg.Collector.Jobs,
+			Name:        "jenkins.insecure-skip-verify",
+			Value:       false,
+			Usage:       "Skip the verification of the TLS certificate of Jenkins",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_INSECURE_SKIP_VERIFY"),
+			Destination: &cfg.Target.InsecureSkipVerify,
+		},
+		&cli.StringFlag{
+			Name:        "jenkins.ca-cert-file",
+			Value:       "",
+			Usage:       "Path to a PEM encoded CA certificate file to verify the TLS certificate of Jenkins",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_CA_CERT_FILE"),
+			Destination: &cfg.Target.CACertFile,
+		},
+		&cli.Int64Flag{
+			Name:        "jenkins.max-response-size",
+			Value:       0,
+			Usage:       "Maximum size in bytes of a single API response, 0 disables the limit",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_MAX_RESPONSE_SIZE"),
+			Destination: &cfg.Target.MaxResponseSize,
+		},
+		&cli.BoolFlag{
+			Name:        "jenkins.crumb",
+			Value:       false,
+			Usage:       "Attach a CSRF crumb from the crumb issuer to non-GET requests, required if CSRF protection is enabled",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_CRUMB"),
+			Destination: &cfg.Target.Crumb,
+		},
+		&cli.BoolFlag{
+			Name:        "jenkins.reload-credentials",
+			Value:       false,
+			Usage:       "Read the username and password again on unauthorized responses, to pick up rotated secret files",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_RELOAD_CREDENTIALS"),
+			Destination: &cfg.Target.ReloadCredentials,
+		},
+		&cli.IntFlag{
+			Name:        "jenkins.retry-attempts",
+			Value:       1,
+			Usage:       "Maximum attempts of GET requests failing with server or network errors, 1 disables retries",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_RETRY_ATTEMPTS"),
+			Destination: &cfg.Target.RetryAttempts,
+		},
+		&cli.DurationFlag{
+			Name:        "jenkins.retry-delay",
+			Value:       500 * time.Millisecond,
+			Usage:       "Delay before the first retry, it grows exponentially with random jitter",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_RETRY_DELAY"),
+			Destination: &cfg.Target.RetryDelay,
+		},
+		&cli.IntFlag{
+			Name:        "jenkins.max-in-flight",
+			Value:       0,
+			Usage:       "Maximum requests in flight, halved on 429 responses for the Retry-After cooldown and increased again on success, 0 disables the limit",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_MAX_IN_FLIGHT"),
+			Destination: &cfg.Target.MaxInFlight,
+		},
+		&cli.StringSliceFlag{
+			Name:        "collectors",
+			Value:       []string{"jobs"},
+			Usage:       "List of collectors to enable in the given order, available: " + strings.Join(action.AvailableCollectors, ", "),
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTORS"),
+			Destination: &cfg.Collector.Collectors,
 		},
 		&cli.BoolFlag{
 			Name:        "collector.jobs.build-details",
@@ -148,6 +270,13 @@ func RootFlags(cfg *config.Config) []cli.Flag {
 			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_BUILD_DETAILS"),
 			Destination: &cfg.Collector.FetchBuildDetails,
 		},
+		&cli.StringSliceFlag{
+			Name:        "collector.jobs.build-details-folders",
+			Value:       []string{},
+			Usage:       "Limit fetching build details to jobs within these folders, all jobs if empty",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_BUILD_DETAILS_FOLDERS"),
+			Destination: &cfg.Collector.BuildDetailsFolders,
+		},
 		&cli.StringFlag{
 			Name:        "collector.jobs.cache-file",
 			Value:       "",
@@ -155,6 +284,13 @@ func RootFlags(cfg *config.Config) []cli.Flag {
 			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_CACHE_FILE"),
 			Destination: &cfg.Collector.CacheFile,
 		},
+		&cli.StringFlag{
+			Name:        "collector.jobs.cache-format",
+			Value:       "",
+			Usage:       "Format of the cache file, either json, json.gz or gob. If empty, it gets detected from the file extension and defaults to json",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_CACHE_FORMAT"),
+			Destination: &cfg.Collector.CacheFormat,
+		},
 		&cli.DurationFlag{
 			Name:        "collector.jobs.cache-ttl",
 			Value:       30 * time.Minute,
@@ -169,6 +305,13 @@ func RootFlags(cfg *config.Config) []cli.Flag {
 			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_CACHE_REFRESH_INTERVAL"),
 			Destination: &cfg.Collector.CacheRefreshInterval,
 		},
+		&cli.FloatFlag{
+			Name:        "collector.jobs.cache-refresh-jitter",
+			Value:       0,
+			Usage:       "Fraction of the cache refresh interval added as random delay to spread refreshes of replicas, e.g. 0.1 for up to 10%",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_CACHE_REFRESH_JITTER"),
+			Destination: &cfg.Collector.CacheRefreshJitter,
+		},
 		&cli.StringFlag{
 			Name:        "collector.jobs.folders",
 			Value:       "",
@@ -176,6 +319,237 @@ func RootFlags(cfg *config.Config) []cli.Flag {
 			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_FOLDERS"),
 			Destination: &cfg.Collector.FoldersStr,
 		},
+		&cli.BoolFlag{
+			Name:        "collector.jobs.strict-folders",
+			Value:       false,
+			Usage:       "Fail the discovery at startup if a configured folder doesn't exist instead of only logging an error",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_STRICT_FOLDERS"),
+			Destination: &cfg.Collector.StrictFolders,
+		},
+		&cli.BoolFlag{
+			Name:        "collector.jobs.folders-glob",
+			Value:       false,
+			Usage:       "Treat the configured folders as glob patterns matched against the folder paths, e.g. team-* or */prod",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_FOLDERS_GLOB"),
+			Destination: &cfg.Collector.FoldersGlob,
+		},
+		&cli.IntFlag{
+			Name:        "collector.jobs.folder-depth",
+			Value:       0,
+			Usage:       "Maximum depth of nested folders to recurse into below the configured folders, deeper folders are skipped, 0 disables the limit",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_FOLDER_DEPTH"),
+			Destination: &cfg.Collector.FolderDepth,
+		},
+		&cli.DurationFlag{
+			Name:        "collector.jobs.folder-timeout",
+			Value:       10 * time.Second,
+			Usage:       "Timeout for the requests of every single folder while recursing through the folders, 0 disables the limit",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_FOLDER_TIMEOUT"),
+			Destination: &cfg.Collector.FolderTimeout,
+		},
+		&cli.StringSliceFlag{
+			Name:        "collector.jobs.exclude-jobs",
+			Value:       []string{},
+			Usage:       "Top-level job names to exclude, jobs within folders are not matched",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_EXCLUDE_JOBS"),
+			Destination: &cfg.Collector.ExcludedJobs,
+		},
+		&cli.StringFlag{
+			Name:        "collector.jobs.include-regex",
+			Value:       "",
+			Usage:       "Only collect jobs whose full path matches the regular expression",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_INCLUDE_REGEX"),
+			Destination: &cfg.Collector.IncludeJobRegex,
+		},
+		&cli.StringFlag{
+			Name:        "collector.jobs.exclude-regex",
+			Value:       "",
+			Usage:       "Skip jobs whose full path matches the regular expression, takes precedence over the include regex",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_EXCLUDE_REGEX"),
+			Destination: &cfg.Collector.ExcludeJobRegex,
+		},
+		&cli.StringSliceFlag{
+			Name:        "collector.jobs.exclude-folders",
+			Value:       []string{},
+			Usage:       "Top-level folder names to exclude, all jobs within them are skipped",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_EXCLUDE_FOLDERS"),
+			Destination: &cfg.Collector.ExcludedFolders,
+		},
+		&cli.StringSliceFlag{
+			Name:        "collector.jobs.priority",
+			Value:       []string{},
+			Usage:       "Path prefixes of jobs or folders to fetch before all other jobs, so they are up to date even if the collection runs out of time",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_PRIORITY"),
+			Destination: &cfg.Collector.PriorityJobs,
+		},
+		&cli.StringFlag{
+			Name:        "collector.jobs.exclude-file",
+			Value:       "",
+			Usage:       "File with additional top-level jobs to exclude in SQLite mode, one per line and folders ending with a slash, re-read periodically to apply changes without restart",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_EXCLUDE_FILE"),
+			Destination: &cfg.Collector.ExcludeFile,
+		},
+		&cli.DurationFlag{
+			Name:        "collector.jobs.exclude-file-interval",
+			Value:       30 * time.Second,
+			Usage:       "Interval to re-read the exclude file in SQLite mode",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_EXCLUDE_FILE_INTERVAL"),
+			Destination: &cfg.Collector.ExcludeFileInterval,
+		},
+		&cli.BoolFlag{
+			Name:        "collector.jobs.artifacts",
+			Value:       false,
+			Usage:       "Export the number of artifacts of the last build, requires build details",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_ARTIFACTS"),
+			Destination: &cfg.Collector.Artifacts,
+		},
+		&cli.FloatFlag{
+			Name:        "collector.jobs.hung-multiplier",
+			Value:       2,
+			Usage:       "Multiple of the estimated duration after which a running build is considered hung, requires build details, disabled if 0",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_HUNG_MULTIPLIER"),
+			Destination: &cfg.Collector.HungMultiplier,
+		},
+		&cli.BoolFlag{
+			Name:        "collector.jobs.build-node",
+			Value:       false,
+			Usage:       "Export the node the last build ran on, requires build details",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_BUILD_NODE"),
+			Destination: &cfg.Collector.BuildNode,
+		},
+		&cli.IntFlag{
+			Name:        "collector.jobs.min-build-number",
+			Value:       0,
+			Usage:       "Builds with a lower number are treated as not built yet, e.g. for jobs migrated from another system, disabled if 0",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_MIN_BUILD_NUMBER"),
+			Destination: &cfg.Collector.MinBuildNumber,
+		},
+		&cli.IntFlag{
+			Name:        "collector.jobs.description-length",
+			Value:       0,
+			Usage:       "Export jenkins_job_info with the job description as label truncated to this number of characters, disabled if 0",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_DESCRIPTION_LENGTH"),
+			Destination: &cfg.Collector.DescriptionLength,
+		},
+		&cli.StringSliceFlag{
+			Name:        "collector.jobs.param-label",
+			Value:       []string{},
+			Usage:       "Build parameters to export as additional labels of jenkins_build_last_result, missing parameters are exported as empty value",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_PARAM_LABEL"),
+			Destination: &cfg.Collector.ParamLabels,
+		},
+		&cli.IntFlag{
+			Name:        "collector.jobs.max-series",
+			Value:       0,
+			Usage:       "Maximum number of distinct label combinations of jenkins_build_last_result set within the last two hours in SQLite mode, new series beyond are dropped while existing ones keep updating, disabled if 0",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_MAX_SERIES"),
+			Destination: &cfg.Collector.MaxSeries,
+		},
+		&cli.IntFlag{
+			Name:        "collector.jobs.recent-builds",
+			Value:       0,
+			Usage:       "Number of recent builds to count successes and failures for, disabled if 0",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_RECENT_BUILDS"),
+			Destination: &cfg.Collector.RecentBuilds,
+		},
+		&cli.BoolFlag{
+			Name:        "collector.jobs.last-success-commit",
+			Value:       false,
+			Usage:       "Export commit and branch of the last successful build, requires an additional request per job",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_LAST_SUCCESS_COMMIT"),
+			Destination: &cfg.Collector.LastSuccessCommit,
+		},
+		&cli.BoolFlag{
+			Name:        "collector.jobs.builds-retained",
+			Value:       false,
+			Usage:       "Export the number of retained builds per job, requires an additional request per job",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_BUILDS_RETAINED"),
+			Destination: &cfg.Collector.BuildsRetained,
+		},
+		&cli.BoolFlag{
+			Name:        "collector.jobs.scm-poll",
+			Value:       false,
+			Usage:       "Export the result of the last SCM poll per job, requires an additional request per job",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_SCM_POLL"),
+			Destination: &cfg.Collector.SCMPoll,
+		},
+		&cli.BoolFlag{
+			Name:        "collector.jobs.nondefault-params",
+			Value:       false,
+			Usage:       "Export the number of last build parameters differing from the job defaults, requires an additional request per job",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_NONDEFAULT_PARAMS"),
+			Destination: &cfg.Collector.NondefaultParams,
+		},
+		&cli.BoolFlag{
+			Name:        "collector.jobs.upstream",
+			Value:       false,
+			Usage:       "Export the upstream job which triggered the last build, only used without SQLite",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_UPSTREAM"),
+			Destination: &cfg.Collector.Upstream,
+		},
+		&cli.StringFlag{
+			Name:        "collector.jobs.name-source",
+			Value:       exporter.NameSourcePath,
+			Usage:       "Source of the job_name label, path or display, only used without SQLite",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_NAME_SOURCE"),
+			Destination: &cfg.Collector.NameSource,
+		},
+		&cli.StringSliceFlag{
+			Name:        "collector.jobs.name-rule",
+			Value:       []string{},
+			Usage:       "Replace rule applied to the job_name label in regexp=replacement format, can be repeated and gets applied in order, not supported with SQLite",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_NAME_RULE"),
+			Destination: &cfg.Collector.NameRules,
+		},
+		&cli.BoolFlag{
+			Name:        "collector.jobs.pending-first-build",
+			Value:       false,
+			Usage:       "Export never built jobs as pending first build instead of a not_built build status",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_PENDING_FIRST_BUILD"),
+			Destination: &cfg.Collector.PendingFirstBuild,
+		},
+		&cli.BoolFlag{
+			Name:        "collector.jobs.access-limited",
+			Value:       false,
+			Usage:       "Flag jobs whose build details are forbidden for the configured credentials",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_ACCESS_LIMITED"),
+			Destination: &cfg.Collector.AccessLimited,
+		},
+		&cli.BoolFlag{
+			Name:        "collector.jobs.result-label",
+			Value:       false,
+			Usage:       "Add the result reported by Jenkins, like UNSTABLE or NOT_BUILT, as result label to the last build result next to the normalized status",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_RESULT_LABEL"),
+			Destination: &cfg.Collector.ResultLabel,
+		},
+		&cli.IntFlag{
+			Name:        "collector.jobs.max-concurrent-scrapes",
+			Value:       1,
+			Usage:       "Maximum number of concurrent scrapes crawling Jenkins without SQLite, further scrapes wait for a free slot, unlimited if 0",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_MAX_CONCURRENT_SCRAPES"),
+			Destination: &cfg.Collector.MaxConcurrentScrapes,
+		},
+		&cli.StringSliceFlag{
+			Name:        "collector.jobs.building-results",
+			Value:       []string{"IN_PROGRESS", "RUNNING"},
+			Usage:       "Build results treated as in progress, for plugins not setting the building flag",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_BUILDING_RESULTS"),
+			Destination: &cfg.Collector.BuildingResults,
+		},
+		&cli.StringFlag{
+			Name:        "collector.jobs.empty-result-status",
+			Value:       "not_built",
+			Usage:       "Status for finished builds without result but with a recorded duration, e.g. aborted for pipelines",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_EMPTY_RESULT_STATUS"),
+			Destination: &cfg.Collector.EmptyResultStatus,
+		},
+		&cli.BoolFlag{
+			Name:        "collector.jobs.config-changes",
+			Value:       false,
+			Usage:       "Count job config changes by hashing config.xml, requires an additional request per job and SQLite",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_CONFIG_CHANGES"),
+			Destination: &cfg.Collector.ConfigChanges,
+		},
 		&cli.StringFlag{
 			Name:        "collector.jobs.sqlite-path",
 			Value:       "",
@@ -200,9 +574,58 @@ func RootFlags(cfg *config.Config) []cli.Flag {
 		&cli.IntFlag{
 			Name:        "collector.jobs.collector-concurrency",
 			Value:       10,
-			Usage:       "Concurrency for build collector (number of concurrent goroutines to process jobs). Default: 10",
+			Usage:       "Concurrency for build collector and build detail fetching (number of concurrent goroutines to process jobs, at most 100). Default: 10",
 			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_COLLECTOR_CONCURRENCY"),
 			Destination: &cfg.Collector.CollectorConcurrency,
 		},
+		&cli.DurationFlag{
+			Name:        "collector.jobs.max-collection-time",
+			Value:       0,
+			Usage:       "Maximum duration of a single build collection cycle before it gets canceled, 0 disables the limit",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_MAX_COLLECTION_TIME"),
+			Destination: &cfg.Collector.MaxCollectionTime,
+		},
+		&cli.DurationFlag{
+			Name:        "collector.jobs.status-max-age",
+			Value:       0,
+			Usage:       "Skip jobs whose last build is older than this age when counting the jobs by status, 0 disables the limit",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_STATUS_MAX_AGE"),
+			Destination: &cfg.Collector.StatusMaxAge,
+		},
+		&cli.DurationFlag{
+			Name:        "collector.jobs.prune-after",
+			Value:       0,
+			Usage:       "Delete disabled jobs and job change records from SQLite once they are older than this duration, 0 disables pruning",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_PRUNE_AFTER"),
+			Destination: &cfg.Collector.PruneAfter,
+		},
+		&cli.DurationFlag{
+			Name:        "collector.jobs.min-collect-interval",
+			Value:       5 * time.Second,
+			Usage:       "Minimum duration between two build collections triggered by scrapes. Default: 5s",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_MIN_COLLECT_INTERVAL"),
+			Destination: &cfg.Collector.MinCollectInterval,
+		},
+		&cli.BoolFlag{
+			Name:        "collector.jobs.persist-counters",
+			Value:       false,
+			Usage:       "Persist counters within the SQLite database on shutdown and restore them on startup, keeps them monotonic across restarts",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_PERSIST_COUNTERS"),
+			Destination: &cfg.Collector.PersistCounters,
+		},
+		&cli.IntFlag{
+			Name:        "collector.jobs.discovery-wait-retries",
+			Value:       5,
+			Usage:       "Consecutive database errors tolerated while waiting for the first discovery sync before startup fails. Default: 5",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_DISCOVERY_WAIT_RETRIES"),
+			Destination: &cfg.Collector.DiscoveryWaitRetries,
+		},
+		&cli.IntFlag{
+			Name:        "collector.jobs.sdk-fallback",
+			Value:       0,
+			Usage:       "Consecutive SDK init failures after which discovery and collection switch to the REST API, disabled if 0",
+			Sources:     cli.EnvVars("JENKINS_EXPORTER_COLLECTOR_JOBS_SDK_FALLBACK"),
+			Destination: &cfg.Collector.SDKFallback,
+		},
 	}
 }
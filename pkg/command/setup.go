@@ -1,26 +1,33 @@
 package command
 
 import (
+	"io"
 	"log/slog"
 	"os"
 	"strings"
 
 	"github.com/promhippie/jenkins_exporter/pkg/config"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
 )
 
 func setupLogger(cfg *config.Config) *slog.Logger {
+	return newLogger(os.Stdout, cfg)
+}
+
+func newLogger(w io.Writer, cfg *config.Config) *slog.Logger {
+	opts := &slog.HandlerOptions{
+		Level:       loggerLevel(cfg),
+		ReplaceAttr: replaceLevel,
+	}
+
 	if cfg.Logs.Pretty {
 		return slog.New(
-			slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-				Level: loggerLevel(cfg),
-			}),
+			slog.NewTextHandler(w, opts),
 		)
 	}
 
 	return slog.New(
-		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-			Level: loggerLevel(cfg),
-		}),
+		slog.NewJSONHandler(w, opts),
 	)
 }
 
@@ -32,9 +39,24 @@ func loggerLevel(cfg *config.Config) slog.Leveler {
 		return slog.LevelWarn
 	case "info":
 		return slog.LevelInfo
-	case "debug":
+	case "verbose":
+		// 与 debug 相同，但不输出每个 job 的调试日志
 		return slog.LevelDebug
+	case "debug":
+		return jenkins.LevelTrace
 	}
 
 	return slog.LevelInfo
 }
+
+// replaceLevel writes the per-job messages with the debug level, slog would
+// print them as DEBUG-4 otherwise.
+func replaceLevel(_ []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.LevelKey {
+		if level, ok := a.Value.Any().(slog.Level); ok && level == jenkins.LevelTrace {
+			a.Value = slog.StringValue(slog.LevelDebug.String())
+		}
+	}
+
+	return a
+}
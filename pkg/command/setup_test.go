@@ -1,9 +1,13 @@
 package command
 
 import (
+	"bytes"
+	"context"
+	"strings"
 	"testing"
 
 	"github.com/promhippie/jenkins_exporter/pkg/config"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -11,3 +15,23 @@ func TestSetupLogger(t *testing.T) {
 	logger := setupLogger(config.Load())
 	assert.NotNil(t, logger)
 }
+
+func TestLoggerPerJobMessages(t *testing.T) {
+	for level, expected := range map[string]bool{
+		"info":    false,
+		"verbose": false,
+		"debug":   true,
+	} {
+		cfg := config.Load()
+		cfg.Logs.Level = level
+
+		buf := &bytes.Buffer{}
+		newLogger(buf, cfg).Log(context.Background(), jenkins.LevelTrace, "已更新 job 构建信息", "job_name", "app")
+
+		assert.Equal(t, expected, strings.Contains(buf.String(), `"job_name":"app"`), level)
+
+		if expected {
+			assert.Contains(t, buf.String(), `"level":"DEBUG"`)
+		}
+	}
+}
@@ -15,6 +15,7 @@ type Server struct {
 	Timeout time.Duration
 	Web     string
 	Pprof   bool
+	Labels  []string
 }
 
 // Logs defines the level and color for log configuration.
@@ -25,26 +26,80 @@ type Logs struct {
 
 // Target defines the target specific configuration.
 type Target struct {
-	Address  string
-	Username string
-	Password string
-	Timeout  time.Duration
+	Address       string
+	Username      string
+	Password      string
+	Token         string // API token，设置后优先于密码使用
+	AuthMode      string // 凭据的传递方式，basic 使用 Basic Auth，bearer 将 token 或密码作为 Bearer token，默认basic
+	Timeout       time.Duration
+	TLSServerName string
+	InsecureSkipVerify bool   // 是否跳过 Jenkins TLS 证书校验，默认false
+	CACertFile    string // 额外信任的 CA 证书文件，用于私有 CA 签发的证书
+	MaxResponseSize int64 // 响应体的最大字节数，0 表示不限制
+	Crumb         bool  // 是否为非 GET 请求获取 CSRF crumb，默认false
+	ReloadCredentials bool // 认证失败（401）时是否重新读取用户名和密码，用于轮换的 secret 文件
+	RetryAttempts int // GET 请求遇到 5xx 或网络错误时的最大尝试次数，默认1表示不重试
+	RetryDelay    time.Duration // 首次重试前的等待时间，之后指数增长，默认500毫秒
+	MaxInFlight   int // 同时进行的请求数量上限，遇到 429 时自动减半，0 表示不限制
 }
 
 // Collector defines the collector specific configuration.
 type Collector struct {
-	Jobs            bool
+	Collectors      []string // 启用的收集器列表（按顺序注册），例如 jobs,nodes
 	FetchBuildDetails bool // 是否获取构建详情（包括参数），默认true
+	BuildDetailsFolders []string // 只获取这些文件夹下作业的构建详情，为空则获取所有作业
 	CacheFile      string // 缓存文件路径，如果为空则不使用缓存
+	CacheFormat    string // 缓存文件格式（json、json.gz、gob），为空则根据文件扩展名判断
 	CacheTTL       time.Duration // 缓存过期时间，默认30分钟
 	CacheRefreshInterval time.Duration // 定时刷新缓存的间隔，如果为0则不启用定时刷新
+	CacheRefreshJitter float64 // 刷新间隔上增加的随机延迟比例，例如 0.1 表示最多延迟 10%
 	FoldersStr     string // 要获取的文件夹列表（逗号分隔），如果为空则获取所有文件夹
+	StrictFolders  bool   // 配置的文件夹不存在时是否启动失败，默认只记录错误日志
+	FoldersGlob    bool   // 是否将配置的文件夹作为 glob 模式匹配文件夹路径，例如 team-* 或 */prod
+	FolderDepth    int    // 文件夹的最大递归深度，超过的文件夹不再递归，0 表示不限制
+	FolderTimeout  time.Duration // 递归时每个文件夹请求的超时，不超过整体的超时，0 表示不限制
+	ExcludedJobs   []string // 要排除的顶层 job 名称（不在任何文件夹中的 job）
+	IncludeJobRegex string // 只采集完整路径匹配该正则表达式的 job，为空则不限制
+	ExcludeJobRegex string // 不采集完整路径匹配该正则表达式的 job，优先于包含的正则表达式
+	ExcludedFolders []string // 要排除的顶层文件夹名称，这些文件夹下的 job 不会被采集
+	ExcludeFile    string // 额外的排除列表文件，每行一个顶层 job，文件夹以 / 结尾，定期重新读取，仅 SQLite 模式
+	ExcludeFileInterval time.Duration // 重新读取排除列表文件的间隔，默认30秒
+	Artifacts      bool   // 是否导出最后一次构建的制品数量，默认false
+	HungMultiplier float64 // 正在进行的构建超过估算耗时多少倍视为卡住，默认2，0 表示不导出
+	BuildNode      bool   // 是否导出最后一次构建运行的节点，默认false
+	MinBuildNumber int    // 小于该编号的构建视为尚未构建，用于迁移后遗留的构建编号，0 表示不限制
+	DescriptionLength int // 导出带有作业描述标签的 jenkins_job_info，描述截断到该长度，0 表示不导出
+	ParamLabels    []string // 作为构建结果指标额外标签导出的构建参数名称，缺少的参数为空字符串
+	MaxSeries      int    // 构建结果指标的最大序列数量，超过后不再添加新的序列，0 表示不限制
+	RecentBuilds   int    // 统计最近多少次构建的成功/失败数量，0 表示不启用
+	LastSuccessCommit bool // 是否导出最后一次成功构建的提交和分支，默认false
+	BuildsRetained bool   // 是否导出作业保留的构建数量，默认false
+	SCMPoll        bool   // 是否导出作业最后一次 SCM 轮询的结果，默认false
+	NondefaultParams bool // 是否导出最后一次构建中与默认值不同的参数数量，默认false
+	Upstream       bool   // 是否导出触发最后一次构建的上游作业，默认false
+	NameSource     string // job_name 标签的来源，path 使用完整路径，display 使用显示名称，默认path
+	NameRules      []string // 按顺序应用到 job_name 标签的 regexp=replacement 替换规则
+	PendingFirstBuild bool // 从未构建的 job 是否只导出 jenkins_job_pending_first_build，不导出构建状态，默认false
+	AccessLimited  bool   // 构建详情无权限访问时是否导出受限标记，默认false
+	ResultLabel    bool   // 是否在构建结果指标上添加 Jenkins 原始结果的 result 标签，默认false
+	PriorityJobs   []string // 优先采集的作业或文件夹路径前缀，采集超时时这些作业的指标也是最新的
+	MaxConcurrentScrapes int // 传统模式下同时进行的采集数量上限，0 表示不限制
+	BuildingResults []string // 视为正在构建的构建结果字符串，用于未正确设置 building 的插件
+	EmptyResultStatus string // 已有耗时但结果为空的构建所使用的状态，默认 not_built
+	ConfigChanges  bool   // 是否跟踪 job 配置变更（需要额外请求 config.xml），默认false
 	
 	// SQLite 相关配置
 	SQLitePath     string // SQLite 数据库路径，如果为空则不使用 SQLite
 	DiscoveryInterval time.Duration // Job Discovery 同步间隔，默认5分钟
 	CollectorInterval time.Duration // Build Collector 采集间隔，默认15秒（已废弃，不再使用定时采集）
-	CollectorConcurrency int // Build Collector 并发数，默认10
+	CollectorConcurrency int // Build Collector 和传统模式下获取构建详情的并发数，默认10，最大100
+	MaxCollectionTime time.Duration // 单次采集的最长时间，超过后取消采集，0 表示不限制
+	StatusMaxAge time.Duration // 按状态统计 job 数量时忽略最后构建早于该时长的 job，0 表示不限制
+	MinCollectInterval time.Duration // 两次按需采集之间的最小间隔，默认5秒
+	PersistCounters bool // 是否将计数器持久化到 SQLite，重启后恢复，默认false
+	DiscoveryWaitRetries int // 等待 Discovery 首次同步时允许的连续数据库错误次数，默认5
+	SDKFallback int // SDK 连续初始化失败多少次后回退到 REST 客户端，0 表示不回退
+	PruneAfter time.Duration // 删除已禁用超过该时长的 job 及更早的审计日志，0 表示不删除
 }
 
 // Config is a combination of all available configurations.
@@ -0,0 +1,86 @@
+package exporter
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
+)
+
+// maxBuildCacheSize defines the upper bound of cached builds per collection.
+const maxBuildCacheSize = 1000
+
+// buildFetcher defines the function used to fetch a single build.
+type buildFetcher func(ctx context.Context, build *jenkins.BuildNumber) (jenkins.Build, error)
+
+// buildCache deduplicates build requests by URL within a single collection,
+// concurrent requests for the same URL wait for the first one.
+type buildCache struct {
+	mu      sync.Mutex
+	size    int
+	fetch   buildFetcher
+	order   *list.List
+	entries map[string]*buildCacheEntry
+}
+
+// buildCacheEntry defines a single cached build result.
+type buildCacheEntry struct {
+	element *list.Element
+	done    chan struct{}
+	build   jenkins.Build
+	err     error
+}
+
+// newBuildCache creates a build cache holding up to size builds.
+func newBuildCache(size int, fetch buildFetcher) *buildCache {
+	if size <= 0 || size > maxBuildCacheSize {
+		size = maxBuildCacheSize
+	}
+
+	return &buildCache{
+		size:    size,
+		fetch:   fetch,
+		order:   list.New(),
+		entries: make(map[string]*buildCacheEntry, size),
+	}
+}
+
+// Get returns the build for the given build number, it gets fetched only if
+// the URL has not been requested before.
+func (c *buildCache) Get(ctx context.Context, build *jenkins.BuildNumber) (jenkins.Build, error) {
+	c.mu.Lock()
+
+	if entry, ok := c.entries[build.URL]; ok {
+		c.order.MoveToFront(entry.element)
+		c.mu.Unlock()
+
+		select {
+		case <-entry.done:
+			return entry.build, entry.err
+		case <-ctx.Done():
+			return jenkins.Build{}, ctx.Err()
+		}
+	}
+
+	entry := &buildCacheEntry{
+		done: make(chan struct{}),
+	}
+
+	entry.element = c.order.PushFront(build.URL)
+	c.entries[build.URL] = entry
+
+	// 超出容量时淘汰最久未使用的条目
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(string))
+	}
+
+	c.mu.Unlock()
+
+	entry.build, entry.err = c.fetch(ctx, build)
+	close(entry.done)
+
+	return entry.build, entry.err
+}
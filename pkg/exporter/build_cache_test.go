@@ -0,0 +1,44 @@
+package exporter
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildCacheDeduplicatesURLs(t *testing.T) {
+	var fetches atomic.Int32
+
+	cache := newBuildCache(2, func(_ context.Context, build *jenkins.BuildNumber) (jenkins.Build, error) {
+		fetches.Add(1)
+		return jenkins.Build{Result: "SUCCESS", Duration: int64(build.Number)}, nil
+	})
+
+	last := &jenkins.BuildNumber{Number: 7, URL: "http://jenkins/job/app/7/"}
+	completed := &jenkins.BuildNumber{Number: 7, URL: "http://jenkins/job/app/7/"}
+
+	var wg sync.WaitGroup
+	for _, build := range []*jenkins.BuildNumber{last, completed, last} {
+		wg.Add(1)
+		go func(build *jenkins.BuildNumber) {
+			defer wg.Done()
+
+			result, err := cache.Get(context.Background(), build)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(7), result.Duration)
+		}(build)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), fetches.Load())
+
+	// 超出容量后最久未使用的 URL 会被淘汰并重新请求
+	_, _ = cache.Get(context.Background(), &jenkins.BuildNumber{Number: 8, URL: "http://jenkins/job/app/8/"})
+	_, _ = cache.Get(context.Background(), &jenkins.BuildNumber{Number: 9, URL: "http://jenkins/job/app/9/"})
+	_, _ = cache.Get(context.Background(), last)
+	assert.Equal(t, int32(4), fetches.Load())
+}
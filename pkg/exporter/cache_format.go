@@ -0,0 +1,121 @@
+package exporter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
+)
+
+const (
+	// CacheFormatJSON stores the cache file as indented JSON.
+	CacheFormatJSON = "json"
+
+	// CacheFormatJSONGzip stores the cache file as gzip compressed JSON.
+	CacheFormatJSONGzip = "json.gz"
+
+	// CacheFormatGob stores the cache file encoded with encoding/gob.
+	CacheFormatGob = "gob"
+)
+
+// IsCacheFormat checks if the given value is a supported cache format, an
+// empty value detects the format from the extension of the cache file.
+func IsCacheFormat(format string) bool {
+	switch format {
+	case "", CacheFormatJSON, CacheFormatJSONGzip, CacheFormatGob:
+		return true
+	}
+
+	return false
+}
+
+// cacheFormat returns the configured format or detects it from the extension
+// of the cache file, JSON is used for unknown extensions.
+func cacheFormat(format, file string) string {
+	if format != "" {
+		return format
+	}
+
+	switch {
+	case strings.HasSuffix(file, ".gz"):
+		return CacheFormatJSONGzip
+	case strings.HasSuffix(file, ".gob"):
+		return CacheFormatGob
+	}
+
+	return CacheFormatJSON
+}
+
+// encodeJobs encodes the jobs for the cache file in the given format.
+func encodeJobs(format string, jobs []jenkins.Job) ([]byte, error) {
+	switch format {
+	case CacheFormatGob:
+		buf := &bytes.Buffer{}
+
+		if err := gob.NewEncoder(buf).Encode(jobs); err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	case CacheFormatJSONGzip:
+		buf := &bytes.Buffer{}
+		writer := gzip.NewWriter(buf)
+
+		if err := json.NewEncoder(writer).Encode(jobs); err != nil {
+			return nil, err
+		}
+
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	case CacheFormatJSON:
+		return json.MarshalIndent(jobs, "", "  ")
+	}
+
+	return nil, fmt.Errorf("unknown cache format: %s", format)
+}
+
+// decodeJobs decodes the jobs of a cache file in the given format.
+func decodeJobs(format string, data []byte) ([]jenkins.Job, error) {
+	var jobs []jenkins.Job
+
+	switch format {
+	case CacheFormatGob:
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&jobs); err != nil {
+			return nil, err
+		}
+	case CacheFormatJSONGzip:
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+
+		if err != nil {
+			return nil, err
+		}
+
+		defer func() { _ = reader.Close() }()
+
+		content, err := io.ReadAll(reader)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(content, &jobs); err != nil {
+			return nil, err
+		}
+	case CacheFormatJSON:
+		if err := json.Unmarshal(data, &jobs); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown cache format: %s", format)
+	}
+
+	return jobs, nil
+}
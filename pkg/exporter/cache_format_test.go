@@ -0,0 +1,60 @@
+package exporter
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/promhippie/jenkins_exporter/pkg/config"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkinstest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheFormatDetection(t *testing.T) {
+	assert.Equal(t, CacheFormatJSON, cacheFormat("", "/tmp/jobs.json"))
+	assert.Equal(t, CacheFormatJSONGzip, cacheFormat("", "/tmp/jobs.json.gz"))
+	assert.Equal(t, CacheFormatGob, cacheFormat("", "/tmp/jobs.gob"))
+	assert.Equal(t, CacheFormatGob, cacheFormat(CacheFormatGob, "/tmp/jobs.json"))
+
+	assert.True(t, IsCacheFormat(""))
+	assert.False(t, IsCacheFormat("yaml"))
+}
+
+func TestCacheFormatRoundTrip(t *testing.T) {
+	jobs := []jenkins.Job{
+		{Class: "hudson.model.FreeStyleProject", Path: "team/app", URL: "http://jenkins/job/team/job/app/", Color: "blue", LastBuild: &jenkins.BuildNumber{Number: 7, URL: "http://jenkins/job/team/job/app/7/"}},
+		{Class: "hudson.model.FreeStyleProject", Path: "lib", URL: "http://jenkins/job/lib/", Disabled: true},
+	}
+
+	for _, format := range []string{CacheFormatJSON, CacheFormatJSONGzip, CacheFormatGob} {
+		t.Run(format, func(t *testing.T) {
+			cacheFile := filepath.Join(t.TempDir(), "jobs.cache")
+			c := newTestCollector(t, jenkinstest.NewServer(t, nil), config.Collector{CacheFile: cacheFile, CacheFormat: format, CacheTTL: 30 * time.Minute})
+
+			assert.NoError(t, c.saveJobsToCache(jobs))
+
+			loaded, fromCache, needsUpdate := c.loadJobsFromCache()
+			assert.True(t, fromCache)
+			assert.False(t, needsUpdate)
+			assert.Equal(t, jobs, loaded)
+		})
+	}
+}
+
+func TestCacheFormatGzipDetected(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "jobs.json.gz")
+	c := newTestCollector(t, jenkinstest.NewServer(t, nil), config.Collector{CacheFile: cacheFile, CacheTTL: 30 * time.Minute})
+
+	assert.NoError(t, c.saveJobsToCache([]jenkins.Job{{Path: "app"}}))
+
+	// 根据扩展名写入 gzip 压缩的 JSON
+	file, err := os.Open(cacheFile)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = file.Close() })
+
+	_, err = gzip.NewReader(file)
+	assert.NoError(t, err)
+}
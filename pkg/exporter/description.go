@@ -0,0 +1,48 @@
+package exporter
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
+)
+
+var (
+	// descriptionTags matches HTML tags, descriptions are often formatted
+	// with the markup formatter of Jenkins.
+	descriptionTags = regexp.MustCompile(`<[^>]*>`)
+)
+
+// collectInfo exports the info metric of the job with its description, if
+// the description length is configured.
+func (c *JobCollector) collectInfo(ch chan<- prometheus.Metric, job jenkins.Job) {
+	if c.descriptionLength <= 0 {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.Info,
+		prometheus.GaugeValue,
+		1.0,
+		c.jobName(job),
+		sanitizeDescription(job.Description, c.descriptionLength),
+	)
+}
+
+// sanitizeDescription strips HTML tags and collapses whitespace like line
+// breaks, the result is truncated to the given number of characters with an
+// ellipsis marking the truncation.
+func sanitizeDescription(description string, length int) string {
+	description = strings.ToValidUTF8(description, "")
+	description = descriptionTags.ReplaceAllString(description, " ")
+	description = strings.Join(strings.Fields(description), " ")
+
+	if utf8.RuneCountInString(description) <= length {
+		return description
+	}
+
+	runes := []rune(description)
+	return strings.TrimSpace(string(runes[:length])) + "…"
+}
@@ -0,0 +1,47 @@
+package exporter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/promhippie/jenkins_exporter/pkg/config"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkinstest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobCollectorDescription(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json": `{"jobs": [
+			{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/app/"},
+			{"_class": "hudson.model.FreeStyleProject", "name": "long", "url": "$URL/job/long/"},
+			{"_class": "hudson.model.FreeStyleProject", "name": "empty", "url": "$URL/job/empty/"}
+		]}`,
+		"/job/app/api/json":   `{"_class": "hudson.model.FreeStyleProject", "fullName": "app", "url": "$URL/job/app/", "color": "blue", "description": "Deploys the\n<b>payment</b> service"}`,
+		"/job/long/api/json":  `{"_class": "hudson.model.FreeStyleProject", "fullName": "long", "url": "$URL/job/long/", "color": "blue", "description": "Builds all artifacts of the monorepo and publishes them"}`,
+		"/job/empty/api/json": `{"_class": "hudson.model.FreeStyleProject", "fullName": "empty", "url": "$URL/job/empty/", "color": "blue"}`,
+	})
+
+	c := newTestCollector(t, srv, config.Collector{DescriptionLength: 30})
+
+	expected := `
+# HELP jenkins_job_info Constant 1 for every job, the description label contains the sanitized and truncated description of the job
+# TYPE jenkins_job_info gauge
+jenkins_job_info{description="",job_name="empty"} 1
+jenkins_job_info{description="Builds all artifacts of the mo…",job_name="long"} 1
+jenkins_job_info{description="Deploys the payment service",job_name="app"} 1
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_job_info"))
+
+	// 未配置描述长度时不导出
+	c = newTestCollector(t, srv, config.Collector{})
+	assert.Equal(t, 0, testutil.CollectAndCount(c, "jenkins_job_info"))
+}
+
+func TestSanitizeDescription(t *testing.T) {
+	assert.Equal(t, "", sanitizeDescription("", 10))
+	assert.Equal(t, "a b c", sanitizeDescription("  a\n\tb <br/>c ", 10))
+	assert.Equal(t, "部署支付…", sanitizeDescription("部署支付服务", 4))
+	assert.Equal(t, "abc", sanitizeDescription("ab\xffc", 10))
+}
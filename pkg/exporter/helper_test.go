@@ -0,0 +1,36 @@
+package exporter
+
+import (
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/promhippie/jenkins_exporter/pkg/config"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
+)
+
+// newTestCollector creates a job collector against the fake Jenkins.
+func newTestCollector(t *testing.T, srv *httptest.Server, collector config.Collector) *JobCollector {
+	t.Helper()
+
+	client, err := jenkins.NewClient(
+		jenkins.WithEndpoint(srv.URL),
+		jenkins.WithTimeout(5*time.Second),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewJobCollector(
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		client,
+		prometheus.NewCounterVec(prometheus.CounterOpts{Name: "failures"}, []string{"collector"}),
+		prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "duration"}, []string{"collector"}),
+		config.Target{Timeout: 5 * time.Second},
+		collector,
+	)
+}
@@ -0,0 +1,19 @@
+package exporter
+
+import (
+	"time"
+
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
+)
+
+// buildHung checks if the build is still running and the elapsed time since
+// it started exceeds the estimated duration by the multiplier. Builds without
+// an estimate, e.g. the first build of a job, are never considered hung.
+func buildHung(build jenkins.Build, now time.Time, multiplier float64) bool {
+	if !build.Building || build.EstimatedDuration <= 0 || multiplier <= 0 {
+		return false
+	}
+
+	elapsed := now.UnixMilli() - build.Timestamp
+	return float64(elapsed) > float64(build.EstimatedDuration)*multiplier
+}
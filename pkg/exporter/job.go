@@ -3,13 +3,16 @@ package exporter
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math/rand/v2"
 	"os"
 	"path/filepath"
+	"runtime/debug"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -17,6 +20,14 @@ import (
 	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
 )
 
+const (
+	// NameSourcePath uses the full path of the job as job_name label.
+	NameSourcePath = "path"
+
+	// NameSourceDisplay uses the display name of the job as job_name label.
+	NameSourceDisplay = "display"
+)
+
 // JobCollector collects metrics about the servers.
 type JobCollector struct {
 	client               *jenkins.Client
@@ -25,27 +36,81 @@ type JobCollector struct {
 	duration             *prometheus.HistogramVec
 	config               config.Target
 	fetchBuildDetails    bool
+	buildDetailsFolders  []string // 只获取这些文件夹下作业的构建详情，为空则获取所有作业
 	cacheFile            string
+	cacheFormat          string // 缓存文件的格式：json、json.gz 或 gob
 	cacheTTL             time.Duration
-	cacheRefreshInterval time.Duration // 定时刷新缓存的间隔，如果为0则不启用
-	folders              []string      // 要获取的文件夹列表，如果为空则获取所有文件夹
+	cacheRefreshInterval time.Duration  // 定时刷新缓存的间隔，如果为0则不启用
+	cacheRefreshJitter   float64        // 刷新间隔上增加的随机延迟比例，避免多个副本同时刷新
+	cacheRefreshing      atomic.Bool    // 是否有缓存刷新正在进行
+	random               func() float64 // 随机数来源，测试时可替换
+	folders              []string       // 要获取的文件夹列表，如果为空则获取所有文件夹
+	excludedJobs         []string       // 要排除的顶层作业名称
+	recentBuilds         int            // 统计最近多少次构建的结果分布，0 表示不启用
+	lastSuccessCommit    bool           // 是否导出最后一次成功构建的提交信息
+	buildsRetained       bool           // 是否导出保留的构建数量
+	scmPoll              bool           // 是否导出 SCM 轮询状态
+	nondefaultParams     bool           // 是否导出最后一次构建中与默认值不同的参数数量
+	upstream             bool           // 是否导出触发最后一次构建的上游作业
+	pendingFirstBuild    bool           // 从未构建的作业是否只导出等待首次构建指标
+	nameSource           string         // job_name 标签的来源，path 或 display
+	nameRules            []NameRule     // 按顺序应用到 job_name 标签的替换规则
+	artifacts            bool           // 是否导出最后一次构建的制品数量
+	accessLimited        bool           // 构建详情无权限访问时是否导出受限标记
+	resultLabel          bool           // 是否在构建结果指标上添加 Jenkins 原始结果的 result 标签
+	priorityJobs         []string       // 优先请求详情的作业或文件夹路径前缀
+	hungMultiplier       float64        // 正在进行的构建超过估算耗时多少倍视为卡住，0 表示不导出
+	minBuild             int            // 小于该编号的构建视为尚未构建，0 表示不限制
+	descriptionLength    int            // 作业描述标签的最大长度，0 表示不导出作业信息
+	paramLabels          []string       // 作为构建结果指标额外标签导出的构建参数
+	buildNode            bool           // 是否导出最后一次构建运行的节点
+	buildingResults      []string       // 视为正在构建的构建结果字符串
+	emptyResult          string         // 已有耗时但结果为空的构建所使用的状态
 	cacheMutex           sync.RWMutex
 	lastCacheUpdate      time.Time
 	stopCacheRefresh     chan struct{} // 用于停止定时刷新任务
-
-	Disabled        *prometheus.Desc
-	Duration        *prometheus.Desc
-	StartTime       *prometheus.Desc
-	EndTime         *prometheus.Desc
-	BuildLastResult *prometheus.Desc
+	clock                jenkins.Clock // 时间来源，测试时可替换
+	panics               *prometheus.CounterVec
+	scrapes              chan struct{} // 限制同时进行的采集数量，为 nil 则不限制
+	workers              int           // 并行请求作业详情的 worker 数量
+
+	Disabled          *prometheus.Desc
+	Duration          *prometheus.Desc
+	StartTime         *prometheus.Desc
+	EndTime           *prometheus.Desc
+	QueueTime         *prometheus.Desc
+	BuildLastResult   *prometheus.Desc
+	BuildStatus       *prometheus.Desc
+	Artifacts         *prometheus.Desc
+	QuietingDown      *prometheus.Desc
+	RecentSuccess     *prometheus.Desc
+	RecentFailure     *prometheus.Desc
+	AccessLimited     *prometheus.Desc
+	LastSuccessCommit *prometheus.Desc
+	BuildsRetained    *prometheus.Desc
+	SCMPollChanges    *prometheus.Desc
+	SCMPollTimestamp  *prometheus.Desc
+	NondefaultParams  *prometheus.Desc
+	UpstreamInfo      *prometheus.Desc
+	PendingFirstBuild *prometheus.Desc
+
+	LastSuccessfulBuild   *prometheus.Desc
+	LastUnsuccessfulBuild *prometheus.Desc
+	BuildHung             *prometheus.Desc
+	BuildNodeInfo         *prometheus.Desc
+	Info                  *prometheus.Desc
+	LastScrapeError       *prometheus.Desc
 }
 
 // NewJobCollector returns a new JobCollector.
-func NewJobCollector(logger *slog.Logger, client *jenkins.Client, failures *prometheus.CounterVec, duration *prometheus.HistogramVec, cfg config.Target, fetchBuildDetails bool, cacheFile string, cacheTTL time.Duration, cacheRefreshInterval time.Duration, folders []string) *JobCollector {
+func NewJobCollector(logger *slog.Logger, client *jenkins.Client, failures *prometheus.CounterVec, duration *prometheus.HistogramVec, cfg config.Target, collector config.Collector) *JobCollector {
 	if failures != nil {
 		failures.WithLabelValues("job").Add(0)
 	}
 
+	// 规则已在启动时校验，这里不会失败
+	nameRules, _ := ParseNameRules(collector.NameRules)
+
 	labels := []string{"job_name"} // job_name 就是 job 的完整路径，不需要 name 和 class
 	return &JobCollector{
 		client:               client,
@@ -53,12 +118,47 @@ func NewJobCollector(logger *slog.Logger, client *jenkins.Client, failures *prom
 		failures:             failures,
 		duration:             duration,
 		config:               cfg,
-		fetchBuildDetails:    fetchBuildDetails,
-		cacheFile:            cacheFile,
-		cacheTTL:             cacheTTL,
-		cacheRefreshInterval: cacheRefreshInterval,
-		folders:              folders,
+		fetchBuildDetails:    collector.FetchBuildDetails,
+		buildDetailsFolders:  collector.BuildDetailsFolders,
+		cacheFile:            collector.CacheFile,
+		cacheFormat:          cacheFormat(collector.CacheFormat, collector.CacheFile),
+		cacheTTL:             collector.CacheTTL,
+		cacheRefreshInterval: collector.CacheRefreshInterval,
+		cacheRefreshJitter:   collector.CacheRefreshJitter,
+		random:               rand.Float64,
+		folders:              jenkins.GetJobNamesFromFolders(collector.FoldersStr),
+		excludedJobs:         collector.ExcludedJobs,
+		recentBuilds:         collector.RecentBuilds,
+		lastSuccessCommit:    collector.LastSuccessCommit,
+		buildsRetained:       collector.BuildsRetained,
+		scmPoll:              collector.SCMPoll,
+		nondefaultParams:     collector.NondefaultParams,
+		upstream:             collector.Upstream,
+		pendingFirstBuild:    collector.PendingFirstBuild,
+		nameSource:           collector.NameSource,
+		nameRules:            nameRules,
+		artifacts:            collector.Artifacts,
+		accessLimited:        collector.AccessLimited,
+		resultLabel:          collector.ResultLabel,
+		priorityJobs:         collector.PriorityJobs,
+		hungMultiplier:       collector.HungMultiplier,
+		minBuild:             collector.MinBuildNumber,
+		descriptionLength:    collector.DescriptionLength,
+		paramLabels:          collector.ParamLabels,
+		buildNode:            collector.BuildNode,
+		buildingResults:      collector.BuildingResults,
+		emptyResult:          collector.EmptyResultStatus,
 		stopCacheRefresh:     make(chan struct{}),
+		clock:                jenkins.RealClock{},
+		scrapes:              newScrapeLimit(collector.MaxConcurrentScrapes),
+		workers:              jobWorkers(collector.CollectorConcurrency),
+		panics: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "jenkins_collector_panics_total",
+				Help: "Number of recovered panics while processing a job",
+			},
+			[]string{"job_name"},
+		),
 
 		Disabled: prometheus.NewDesc(
 			"jenkins_job_disabled",
@@ -84,10 +184,130 @@ func NewJobCollector(logger *slog.Logger, client *jenkins.Client, failures *prom
 			labels,
 			nil,
 		),
+		QueueTime: prometheus.NewDesc(
+			"jenkins_job_last_build_queue_time_ms",
+			"Time the last build waited in the queue before it started in ms, requires the metrics plugin",
+			labels,
+			nil,
+		),
 		BuildLastResult: prometheus.NewDesc(
 			"jenkins_build_last_result",
-			"Last build result: 1 indicates current status, status label contains the actual status (success, failure, aborted, waiting, in_progress, not_built)",
-			[]string{"job_name", "check_commitID", "gitBranch", "status"}, // 只包含4个标签：job_name, check_commitID, gitBranch, status
+			jenkins.BuildLastResultHelp,
+			jenkins.BuildLastResultLabels(collector.ResultLabel, collector.ParamLabels), // job_name, check_commitID, gitBranch, status，启用时包含 result 和参数
+			nil,
+		),
+		BuildStatus: prometheus.NewDesc(
+			"jenkins_job_build_status",
+			jenkins.BuildStatusHelp,
+			labels,
+			nil,
+		),
+		Artifacts: prometheus.NewDesc(
+			"jenkins_job_last_build_artifacts",
+			"Number of artifacts archived by the last build",
+			labels,
+			nil,
+		),
+		QuietingDown: prometheus.NewDesc(
+			"jenkins_quieting_down",
+			"1 if Jenkins is quieting down in preparation for a restart, 0 otherwise",
+			nil,
+			nil,
+		),
+		RecentSuccess: prometheus.NewDesc(
+			"jenkins_job_recent_builds_success",
+			"Number of successful builds within the recent builds window",
+			labels,
+			nil,
+		),
+		RecentFailure: prometheus.NewDesc(
+			"jenkins_job_recent_builds_failure",
+			"Number of failed builds within the recent builds window",
+			labels,
+			nil,
+		),
+		AccessLimited: prometheus.NewDesc(
+			"jenkins_job_access_limited",
+			"1 if the job is listed but its build details are forbidden for the credentials",
+			labels,
+			nil,
+		),
+		LastSuccessCommit: prometheus.NewDesc(
+			"jenkins_job_last_success_commit_info",
+			"Constant 1 for jobs with a successful build, labels contain commit and branch of the last successful build",
+			[]string{"job_name", "commit", "branch"},
+			nil,
+		),
+		BuildsRetained: prometheus.NewDesc(
+			"jenkins_job_builds_retained",
+			"Number of builds retained in the build history of the job",
+			labels,
+			nil,
+		),
+		SCMPollChanges: prometheus.NewDesc(
+			"jenkins_job_scm_poll_has_changes",
+			"1 if the last SCM poll of the job found changes, 0 otherwise",
+			labels,
+			nil,
+		),
+		SCMPollTimestamp: prometheus.NewDesc(
+			"jenkins_job_last_scm_poll_timestamp",
+			"Start time of the last SCM poll of the job as unix timestamp",
+			labels,
+			nil,
+		),
+		NondefaultParams: prometheus.NewDesc(
+			"jenkins_job_last_build_nondefault_params",
+			"Number of parameters of the last build which differ from the defaults of the job",
+			labels,
+			nil,
+		),
+		UpstreamInfo: prometheus.NewDesc(
+			"jenkins_job_last_build_upstream_info",
+			"Constant 1 for jobs whose last build got triggered by an upstream job, the upstream label contains its name",
+			[]string{"job_name", "upstream"},
+			nil,
+		),
+		PendingFirstBuild: prometheus.NewDesc(
+			"jenkins_job_pending_first_build",
+			"1 if the job has never been built, only exported instead of the build status if enabled",
+			labels,
+			nil,
+		),
+		LastSuccessfulBuild: prometheus.NewDesc(
+			"jenkins_job_last_successful_build",
+			"Number of the last successful build of the job",
+			labels,
+			nil,
+		),
+		LastUnsuccessfulBuild: prometheus.NewDesc(
+			"jenkins_job_last_unsuccessful_build",
+			"Number of the last unsuccessful build of the job",
+			labels,
+			nil,
+		),
+		BuildHung: prometheus.NewDesc(
+			"jenkins_job_build_hung",
+			"1 if the last build is still running and exceeded its estimated duration by the configured multiplier",
+			labels,
+			nil,
+		),
+		LastScrapeError: prometheus.NewDesc(
+			"jenkins_last_scrape_error_info",
+			"Constant 1 if the last collection of the jobs failed, the error label contains the category of the failure",
+			[]string{"error"},
+			nil,
+		),
+		Info: prometheus.NewDesc(
+			"jenkins_job_info",
+			"Constant 1 for every job, the description label contains the sanitized and truncated description of the job",
+			[]string{"job_name", "description"},
+			nil,
+		),
+		BuildNodeInfo: prometheus.NewDesc(
+			"jenkins_job_last_build_node_info",
+			"Constant 1 for jobs whose last build reports the node it ran on, the node label contains its name",
+			[]string{"job_name", "node"},
 			nil,
 		),
 	}
@@ -127,7 +347,7 @@ func (c *JobCollector) InitializeCache(ctx context.Context) error {
 		)
 	}
 
-	jobs, err := c.client.Job.All(ctx, c.folders)
+	jobs, _, err := c.client.Job.All(ctx, c.folders)
 	if err != nil {
 		return fmt.Errorf("初始化缓存失败，无法从 Jenkins 获取作业列表: %w", err)
 	}
@@ -153,7 +373,27 @@ func (c *JobCollector) Metrics() []*prometheus.Desc {
 		c.Duration,
 		c.StartTime,
 		c.EndTime,
+		c.QueueTime,
 		c.BuildLastResult,
+		c.BuildStatus,
+		c.Artifacts,
+		c.QuietingDown,
+		c.RecentSuccess,
+		c.RecentFailure,
+		c.AccessLimited,
+		c.LastSuccessCommit,
+		c.BuildsRetained,
+		c.SCMPollChanges,
+		c.SCMPollTimestamp,
+		c.NondefaultParams,
+		c.UpstreamInfo,
+		c.PendingFirstBuild,
+		c.LastSuccessfulBuild,
+		c.LastUnsuccessfulBuild,
+		c.BuildHung,
+		c.BuildNodeInfo,
+		c.Info,
+		c.LastScrapeError,
 	}
 }
 
@@ -163,7 +403,28 @@ func (c *JobCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.Duration
 	ch <- c.StartTime
 	ch <- c.EndTime
+	ch <- c.QueueTime
 	ch <- c.BuildLastResult
+	ch <- c.BuildStatus
+	ch <- c.Artifacts
+	ch <- c.QuietingDown
+	ch <- c.RecentSuccess
+	ch <- c.RecentFailure
+	ch <- c.AccessLimited
+	ch <- c.LastSuccessCommit
+	ch <- c.BuildsRetained
+	ch <- c.SCMPollChanges
+	ch <- c.SCMPollTimestamp
+	ch <- c.NondefaultParams
+	ch <- c.UpstreamInfo
+	ch <- c.PendingFirstBuild
+	ch <- c.LastSuccessfulBuild
+	ch <- c.LastUnsuccessfulBuild
+	ch <- c.BuildHung
+	ch <- c.BuildNodeInfo
+	ch <- c.Info
+	ch <- c.LastScrapeError
+	c.panics.Describe(ch)
 }
 
 // loadJobsFromCache loads jobs from cache file if it exists.
@@ -198,8 +459,8 @@ func (c *JobCollector) loadJobsFromCache() ([]jenkins.Job, bool, bool) {
 		return nil, false, false
 	}
 
-	var jobs []jenkins.Job
-	if err := json.Unmarshal(data, &jobs); err != nil {
+	jobs, err := decodeJobs(c.cacheFormat, data)
+	if err != nil {
 		c.logger.Warn("解析缓存文件失败，将从 API 获取",
 			"缓存文件", c.cacheFile,
 			"错误", err,
@@ -208,7 +469,7 @@ func (c *JobCollector) loadJobsFromCache() ([]jenkins.Job, bool, bool) {
 	}
 
 	// 检查缓存是否过期
-	age := time.Since(info.ModTime())
+	age := c.clock.Now().Sub(info.ModTime())
 	needsUpdate := age > c.cacheTTL
 
 	if needsUpdate {
@@ -247,7 +508,7 @@ func (c *JobCollector) saveJobsToCache(jobs []jenkins.Job) error {
 		return fmt.Errorf("创建缓存目录失败: %w", err)
 	}
 
-	data, err := json.MarshalIndent(jobs, "", "  ")
+	data, err := encodeJobs(c.cacheFormat, jobs)
 	if err != nil {
 		return fmt.Errorf("序列化作业数据失败: %w", err)
 	}
@@ -271,7 +532,7 @@ func (c *JobCollector) saveJobsToCache(jobs []jenkins.Job) error {
 		return fmt.Errorf("重命名缓存文件失败: %w", err)
 	}
 
-	c.lastCacheUpdate = time.Now()
+	c.lastCacheUpdate = c.clock.Now()
 	c.logger.Info("已保存作业列表到缓存文件（原子写入）",
 		"缓存文件", c.cacheFile,
 		"作业数量", len(jobs),
@@ -282,6 +543,16 @@ func (c *JobCollector) saveJobsToCache(jobs []jenkins.Job) error {
 
 // updateCacheInBackground updates cache in background without blocking.
 func (c *JobCollector) updateCacheInBackground() {
+	// 同一时间只进行一次刷新，定时刷新和缓存过期触发的刷新可能重叠
+	if !c.cacheRefreshing.CompareAndSwap(false, true) {
+		c.logger.Debug("缓存刷新正在进行，跳过本次刷新",
+			"缓存文件", c.cacheFile,
+		)
+		return
+	}
+
+	defer c.cacheRefreshing.Store(false)
+
 	c.logger.Info("开始后台更新缓存",
 		"缓存文件", c.cacheFile,
 	)
@@ -289,7 +560,7 @@ func (c *JobCollector) updateCacheInBackground() {
 	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
 	defer cancel()
 
-	jobs, err := c.client.Job.All(ctx, c.folders)
+	jobs, _, err := c.client.Job.All(ctx, c.folders)
 	if err != nil {
 		c.logger.Warn("后台更新缓存失败",
 			"错误", err,
@@ -323,10 +594,11 @@ func (c *JobCollector) StartCacheRefresh(ctx context.Context) error {
 	c.logger.Info("启动定时缓存刷新任务",
 		"缓存文件", c.cacheFile,
 		"刷新间隔", c.cacheRefreshInterval,
+		"随机延迟比例", c.cacheRefreshJitter,
 	)
 
-	ticker := time.NewTicker(c.cacheRefreshInterval)
-	defer ticker.Stop()
+	timer := time.NewTimer(c.nextCacheRefresh())
+	defer timer.Stop()
 
 	// 立即执行一次刷新
 	c.updateCacheInBackground()
@@ -341,12 +613,23 @@ func (c *JobCollector) StartCacheRefresh(ctx context.Context) error {
 		case <-c.stopCacheRefresh:
 			c.logger.Info("定时缓存刷新任务已停止（手动停止）")
 			return nil
-		case <-ticker.C:
+		case <-timer.C:
 			c.updateCacheInBackground()
+			timer.Reset(c.nextCacheRefresh())
 		}
 	}
 }
 
+// nextCacheRefresh returns the delay until the next cache refresh, the
+// interval gets extended by a random jitter to spread refreshes of replicas.
+func (c *JobCollector) nextCacheRefresh() time.Duration {
+	if c.cacheRefreshJitter <= 0 {
+		return c.cacheRefreshInterval
+	}
+
+	return c.cacheRefreshInterval + time.Duration(float64(c.cacheRefreshInterval)*c.cacheRefreshJitter*c.random())
+}
+
 // StopCacheRefresh stops the periodic cache refresh task.
 func (c *JobCollector) StopCacheRefresh() {
 	if c.stopCacheRefresh != nil {
@@ -363,6 +646,24 @@ func (c *JobCollector) Collect(ch chan<- prometheus.Metric) {
 		"缓存TTL", c.cacheTTL,
 	)
 
+	// 无论采集是否提前结束，都导出 panic 计数
+	defer c.panics.Collect(ch)
+
+	// 限制同时进行的采集数量，避免多个 Prometheus 同时抓取时成倍增加 Jenkins 负载
+	if !c.acquireScrape() {
+		c.logger.Warn("等待其他采集完成超时，跳过本次采集",
+			"超时时间", c.config.Timeout,
+		)
+
+		c.failures.WithLabelValues("job").Inc()
+		c.collectScrapeError(ch, jenkins.ScrapeErrorTimeout)
+		return
+	}
+
+	defer c.releaseScrape()
+
+	c.collectStatus(ch)
+
 	// 先尝试从缓存加载
 	var jobs []jenkins.Job
 	var elapsed time.Duration
@@ -372,9 +673,16 @@ func (c *JobCollector) Collect(ch chan<- prometheus.Metric) {
 	if cachedJobs, fromCache, needsUpdate := c.loadJobsFromCache(); fromCache {
 		jobs = cachedJobs
 		elapsed = 0 // 从缓存加载，耗时几乎为0
+
+		// 缓存只保存作业列表，构建详情每次采集时重新获取
+		if c.fetchBuildDetails {
+			jobs = latestBuilds(jobs)
+		}
+
 		c.logger.Info("使用缓存数据",
 			"作业数量", len(jobs),
 			"需要后台更新", needsUpdate,
+			"构建详情", "缓存不包含构建详情，启用 fetch-build-details 时按作业的 lastBuild 地址重新获取",
 		)
 
 		// 如果缓存过期，后台异步更新（不阻塞当前请求）
@@ -386,7 +694,7 @@ func (c *JobCollector) Collect(ch chan<- prometheus.Metric) {
 		ctx, cancel = context.WithTimeout(context.Background(), c.config.Timeout)
 		defer cancel()
 
-		now := time.Now()
+		now := c.clock.Now()
 		c.logger.Info("正在从 Jenkins 获取作业列表")
 
 		c.logger.Info("获取作业流程说明",
@@ -401,8 +709,8 @@ func (c *JobCollector) Collect(ch chan<- prometheus.Metric) {
 		)
 
 		var err error
-		jobs, err = c.client.Job.All(ctx, c.folders)
-		elapsed = time.Since(now)
+		jobs, _, err = c.client.Job.All(ctx, c.folders)
+		elapsed = c.clock.Now().Sub(now)
 		c.duration.WithLabelValues("job").Observe(elapsed.Seconds())
 
 		if err != nil {
@@ -413,6 +721,7 @@ func (c *JobCollector) Collect(ch chan<- prometheus.Metric) {
 			)
 
 			c.failures.WithLabelValues("job").Inc()
+			c.collectScrapeError(ch, jenkins.ScrapeErrorCategory(err))
 			return
 		}
 
@@ -424,6 +733,12 @@ func (c *JobCollector) Collect(ch chan<- prometheus.Metric) {
 		}
 	}
 
+	// 过滤掉排除的顶层作业和 job_name 重复的作业，缓存中仍保留完整列表
+	jobs = c.filterJobs(jobs)
+
+	// 小于最小构建编号的构建不导出构建指标
+	jobs = c.skipOldBuilds(jobs)
+
 	// 统计各个文件夹下的作业数量（按顶层文件夹分组）
 	folderJobCount := make(map[string]int)
 	// 统计所有作业路径的前缀，用于调试
@@ -466,6 +781,9 @@ func (c *JobCollector) Collect(ch chan<- prometheus.Metric) {
 	buildDetailsFetched := 0
 	buildDetailsFailed := 0
 
+	// 同一次采集内相同构建 URL 只请求一次，最后一次构建和最后一次成功构建可能相同
+	builds := newBuildCache(2*len(jobs), c.client.Job.Build)
+
 	// 如果启用构建详情获取，使用并行处理
 	if c.fetchBuildDetails {
 		// 并行获取构建详情
@@ -478,47 +796,55 @@ func (c *JobCollector) Collect(ch chan<- prometheus.Metric) {
 			status        float64
 		}
 
-		// 创建 worker pool，最多10个并发
-		const maxWorkers = 10
+		// 创建 worker pool，并发数由配置决定
 		jobsChan := make(chan jenkins.Job, len(jobs))
 		resultsChan := make(chan buildDetailResult, len(jobs))
 
 		// 启动 workers
 		var wg sync.WaitGroup
-		for w := 0; w < maxWorkers; w++ {
+		for w := 0; w < c.workers; w++ {
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
 				for job := range jobsChan {
-					if job.LastBuild == nil {
-						resultsChan <- buildDetailResult{job: job}
-						continue
-					}
-
-					buildCtx, buildCancel := context.WithTimeout(context.Background(), 5*time.Second)
-					build, buildErr := c.client.Job.Build(buildCtx, job.LastBuild)
-					buildCancel()
-
-					result := buildDetailResult{
-						job:      job,
-						build:    build,
-						buildErr: buildErr,
-					}
-
-					if buildErr == nil {
-						result.checkCommitID = extractParameter(build, "check_commitID")
-						result.gitBranch = extractParameter(build, "gitBranch")
-						result.status = buildStatusToValue(build.Result, build.Building, build.QueueID)
-					}
-
-					resultsChan <- result
+					func() {
+						defer c.recoverJob(job)
+
+						if job.LastBuild == nil {
+							resultsChan <- buildDetailResult{job: job}
+							return
+						}
+
+						// 未配置获取构建详情的文件夹下的作业，直接使用作业颜色推断状态
+						if !c.fetchDetailsFor(job.Path) {
+							return
+						}
+
+						buildCtx, buildCancel := context.WithTimeout(context.Background(), 5*time.Second)
+						build, buildErr := builds.Get(buildCtx, job.LastBuild)
+						buildCancel()
+
+						result := buildDetailResult{
+							job:      job,
+							build:    build,
+							buildErr: buildErr,
+						}
+
+						if buildErr == nil {
+							result.checkCommitID = extractParameter(build, "check_commitID")
+							result.gitBranch = extractParameter(build, "gitBranch")
+							result.status = buildStatusToValue(build.Result, build.Building, build.QueueID, build.Duration, c.buildingResults, c.emptyResult)
+						}
+
+						resultsChan <- result
+					}()
 				}
 			}()
 		}
 
-		// 发送所有作业到 channel
+		// 发送所有作业到 channel，优先作业先发送，只影响请求顺序不影响输出顺序
 		go func() {
-			for _, job := range jobs {
+			for _, job := range c.prioritized(jobs) {
 				jobsChan <- job
 			}
 			close(jobsChan)
@@ -544,222 +870,908 @@ func (c *JobCollector) Collect(ch chan<- prometheus.Metric) {
 
 		// 处理所有作业
 		for i, job := range jobs {
-			// 每处理10个作业记录一次进度
-			if i > 0 && i%10 == 0 {
-				c.logger.Info("正在处理作业",
-					"进度", fmt.Sprintf("%d/%d", i, len(jobs)),
-					"当前作业", job.Path,
-					"已处理", processedCount,
+			func() {
+				defer c.recoverJob(job)
+
+				// 每处理10个作业记录一次进度
+				if i > 0 && i%10 == 0 {
+					c.logger.Info("正在处理作业",
+						"进度", fmt.Sprintf("%d/%d", i, len(jobs)),
+						"当前作业", job.Path,
+						"已处理", processedCount,
+					)
+				}
+				var (
+					disabled float64
 				)
-			}
-			var (
-				disabled float64
-			)
 
-			labels := []string{
-				job.Path, // path 就是 jobname，不需要 name 和 class
-			}
+				labels := []string{
+					c.jobName(job), // 根据配置使用路径或显示名称，不需要 name 和 class
+				}
 
-			if job.Disabled {
-				disabled = 1.0
-			}
+				if job.Disabled {
+					disabled = 1.0
+				}
 
-			ch <- prometheus.MustNewConstMetric(
-				c.Disabled,
-				prometheus.GaugeValue,
-				disabled,
-				labels...,
-			)
+				ch <- prometheus.MustNewConstMetric(
+					c.Disabled,
+					prometheus.GaugeValue,
+					disabled,
+					labels...,
+				)
 
-			if job.LastBuild != nil {
-				// 从并行获取的结果中获取构建详情
-				var checkCommitID, gitBranch string
-				var status float64
-				result, hasResult := buildDetailsMap[job.Path]
+				c.collectBuildNumbers(ch, job, labels)
+				c.collectInfo(ch, job)
+
+				if job.LastBuild != nil {
+					// 从并行获取的结果中获取构建详情
+					var checkCommitID, gitBranch string
+					var status float64
+					result, hasResult := buildDetailsMap[job.Path]
+
+					if hasResult && result.buildErr == nil {
+						// 成功获取构建详情
+						checkCommitID = result.checkCommitID
+						gitBranch = result.gitBranch
+						status = result.status
+
+						// 导出构建详情指标
+						ch <- prometheus.MustNewConstMetric(
+							c.Duration,
+							prometheus.GaugeValue,
+							float64(result.build.Duration),
+							labels...,
+						)
+
+						ch <- prometheus.MustNewConstMetric(
+							c.StartTime,
+							prometheus.GaugeValue,
+							float64(result.build.Timestamp),
+							labels...,
+						)
+
+						ch <- prometheus.MustNewConstMetric(
+							c.EndTime,
+							prometheus.GaugeValue,
+							float64(result.build.Timestamp+result.build.Duration),
+							labels...,
+						)
+
+						if queueTime, ok := extractQueueTime(result.build); ok {
+							ch <- prometheus.MustNewConstMetric(
+								c.QueueTime,
+								prometheus.GaugeValue,
+								float64(queueTime),
+								labels...,
+							)
+						}
+
+						if c.artifacts {
+							ch <- prometheus.MustNewConstMetric(
+								c.Artifacts,
+								prometheus.GaugeValue,
+								float64(len(result.build.Artifacts)),
+								labels...,
+							)
+						}
+
+						if c.hungMultiplier > 0 {
+							var hung float64
+							if buildHung(result.build, c.clock.Now(), c.hungMultiplier) {
+								hung = 1.0
+							}
+
+							ch <- prometheus.MustNewConstMetric(
+								c.BuildHung,
+								prometheus.GaugeValue,
+								hung,
+								labels...,
+							)
+						}
+
+						// 在内置节点上运行的构建没有节点信息
+						if c.buildNode && result.build.BuiltOn != "" {
+							ch <- prometheus.MustNewConstMetric(
+								c.BuildNodeInfo,
+								prometheus.GaugeValue,
+								1.0,
+								c.jobName(job),
+								result.build.BuiltOn,
+							)
+						}
+					} else {
+						// 构建详情无权限访问时，标记为受限，其余指标仍基于作业列表导出
+						if c.accessLimited && hasResult && errors.Is(result.buildErr, jenkins.ErrForbidden) {
+							ch <- prometheus.MustNewConstMetric(
+								c.AccessLimited,
+								prometheus.GaugeValue,
+								1.0,
+								labels...,
+							)
+						}
+
+						// 获取失败或未获取，使用作业颜色推断状态
+						switch job.Color {
+						case "blue", "blue_anime":
+							status = 0.0 // success
+						case "red", "red_anime":
+							status = 1.0 // failure
+						case "aborted", "aborted_anime":
+							status = 2.0 // aborted
+						case "yellow", "yellow_anime":
+							status = 3.0 // unstable
+						default:
+							status = 6.0 // not_built
+						}
+						checkCommitID = "" // 无法获取
+						gitBranch = ""     // 无法获取
+					}
 
-				if hasResult && result.buildErr == nil {
-					// 成功获取构建详情
-					checkCommitID = result.checkCommitID
-					gitBranch = result.gitBranch
-					status = result.status
+					// 根据状态值确定 status 标签
+					statusLabel := jenkins.BuildStatusLabel(status)
 
-					// 导出构建详情指标
-					ch <- prometheus.MustNewConstMetric(
-						c.Duration,
-						prometheus.GaugeValue,
-						float64(result.build.Duration),
-						labels...,
-					)
+					// 导出统一的构建结果指标，值为1表示当前状态，通过status标签区分
+					// 只包含4个标签：job_name, check_commitID, gitBranch, status
+					labelsBuildResult := []string{
+						c.jobName(job), // job_name
+						checkCommitID,  // check_commitID
+						gitBranch,      // gitBranch
+						statusLabel,    // status
+					}
+
+					// 获取构建详情失败时没有原始结果
+					if c.resultLabel {
+						rawResult := ""
+						if hasResult && result.buildErr == nil {
+							rawResult = result.build.Result
+						}
+
+						labelsBuildResult = append(labelsBuildResult, rawResult)
+					}
+
+					// 获取构建详情失败时参数标签为空
+					if hasResult && result.buildErr == nil {
+						labelsBuildResult = append(labelsBuildResult, c.paramLabelValues(&result.build)...)
+					} else {
+						labelsBuildResult = append(labelsBuildResult, c.paramLabelValues(nil)...)
+					}
 
 					ch <- prometheus.MustNewConstMetric(
-						c.StartTime,
+						c.BuildLastResult,
 						prometheus.GaugeValue,
-						float64(result.build.Timestamp),
-						labels...,
+						1.0, // 值为1表示这是当前状态
+						labelsBuildResult...,
 					)
 
 					ch <- prometheus.MustNewConstMetric(
-						c.EndTime,
+						c.BuildStatus,
 						prometheus.GaugeValue,
-						float64(result.build.Timestamp+result.build.Duration),
+						jenkins.BuildStatusValue(labelsBuildResult[3]),
 						labels...,
 					)
 				} else {
-					// 获取失败或未获取，使用作业颜色推断状态
-					switch job.Color {
-					case "blue", "blue_anime":
-						status = 0.0 // success
-					case "red", "red_anime":
-						status = 1.0 // failure
-					case "aborted", "aborted_anime":
-						status = 2.0 // aborted
-					case "yellow", "yellow_anime":
-						status = 3.0 // unstable
-					default:
-						status = 6.0 // not_built
-					}
-					checkCommitID = "" // 无法获取
-					gitBranch = ""     // 无法获取
+					c.collectNeverBuilt(ch, job)
 				}
 
-				// 根据状态值确定 status 标签
-				var statusLabel string
-				if status == 0.0 {
-					statusLabel = "success"
-				} else if status == 1.0 {
-					statusLabel = "failure"
-				} else if status == 2.0 {
-					statusLabel = "aborted"
-				} else if status == 4.0 {
-					statusLabel = "in_progress"
-				} else if status == 5.0 {
-					statusLabel = "waiting"
-				} else {
-					statusLabel = "not_built"
+				processedCount++
+			}()
+		}
+	} else {
+		// 未启用构建详情获取，串行处理
+		for i, job := range jobs {
+			func() {
+				defer c.recoverJob(job)
+
+				// 每处理10个作业记录一次进度
+				if i > 0 && i%10 == 0 {
+					c.logger.Info("正在处理作业",
+						"进度", fmt.Sprintf("%d/%d", i, len(jobs)),
+						"当前作业", job.Path,
+						"已处理", processedCount,
+					)
 				}
+				var (
+					disabled float64
+				)
 
-				// 导出统一的构建结果指标，值为1表示当前状态，通过status标签区分
-				// 只包含4个标签：job_name, check_commitID, gitBranch, status
-				labelsBuildResult := []string{
-					job.Path,      // job_name
-					checkCommitID, // check_commitID
-					gitBranch,     // gitBranch
-					statusLabel,   // status
+				labels := []string{
+					c.jobName(job), // 根据配置使用路径或显示名称，不需要 name 和 class
 				}
-				ch <- prometheus.MustNewConstMetric(
-					c.BuildLastResult,
-					prometheus.GaugeValue,
-					1.0, // 值为1表示这是当前状态
-					labelsBuildResult...,
-				)
-			} else {
-				// 如果没有 LastBuild，仍然导出构建结果指标（未构建状态）
-				// 只包含4个标签：job_name, check_commitID, gitBranch, status
-				labelsBuildResult := []string{
-					job.Path,    // job_name
-					"",          // check_commitID
-					"",          // gitBranch
-					"not_built", // status
+
+				if job.Disabled {
+					disabled = 1.0
 				}
+
 				ch <- prometheus.MustNewConstMetric(
-					c.BuildLastResult,
+					c.Disabled,
 					prometheus.GaugeValue,
-					1.0, // 值为1表示这是当前状态
-					labelsBuildResult...,
+					disabled,
+					labels...,
 				)
-			}
 
-			processedCount++
+				c.collectBuildNumbers(ch, job, labels)
+				c.collectInfo(ch, job)
+
+				if job.LastBuild != nil {
+					// 未启用构建详情，使用作业颜色推断状态
+					statusLabel := colorStatus(job.Color)
+
+					// 导出统一的构建结果指标
+					// 只包含4个标签：job_name, check_commitID, gitBranch, status
+					labelsBuildResult := []string{
+						c.jobName(job),
+						"", // check_commitID
+						"", // gitBranch
+						statusLabel,
+					}
+
+					// 未获取构建详情，没有原始结果和参数
+					if c.resultLabel {
+						labelsBuildResult = append(labelsBuildResult, "")
+					}
+
+					labelsBuildResult = append(labelsBuildResult, c.paramLabelValues(nil)...)
+
+					ch <- prometheus.MustNewConstMetric(
+						c.BuildLastResult,
+						prometheus.GaugeValue,
+						1.0,
+						labelsBuildResult...,
+					)
+
+					ch <- prometheus.MustNewConstMetric(
+						c.BuildStatus,
+						prometheus.GaugeValue,
+						jenkins.BuildStatusValue(labelsBuildResult[3]),
+						labels...,
+					)
+				} else {
+					c.collectNeverBuilt(ch, job)
+				}
+
+				processedCount++
+			}()
 		}
-	} else {
-		// 未启用构建详情获取，串行处理
-		for i, job := range jobs {
-			// 每处理10个作业记录一次进度
-			if i > 0 && i%10 == 0 {
-				c.logger.Info("正在处理作业",
-					"进度", fmt.Sprintf("%d/%d", i, len(jobs)),
-					"当前作业", job.Path,
-					"已处理", processedCount,
-				)
+	}
+
+	if c.recentBuilds > 0 {
+		c.collectRecentBuilds(ch, jobs)
+	}
+
+	if c.lastSuccessCommit {
+		c.collectLastSuccessCommits(ch, jobs, builds)
+	}
+
+	if c.buildsRetained {
+		c.collectBuildsRetained(ch, jobs)
+	}
+
+	if c.scmPoll {
+		c.collectSCMPolls(ch, jobs)
+	}
+
+	if c.nondefaultParams {
+		c.collectNondefaultParams(ch, jobs, builds)
+	}
+
+	if c.upstream {
+		c.collectUpstream(ch, jobs, builds)
+	}
+
+	c.logger.Info("作业指标收集完成",
+		"总作业数", len(jobs),
+		"已处理作业数", processedCount,
+		"成功获取构建详情数", buildDetailsFetched,
+		"获取构建详情失败数", buildDetailsFailed,
+		"构建详情获取已启用", c.fetchBuildDetails,
+	)
+}
+
+// eachJob runs fn for every job using a bounded worker pool, a panic only
+// skips the affected job.
+func (c *JobCollector) eachJob(jobs []jenkins.Job, fn func(job jenkins.Job)) {
+	jobsChan := make(chan jenkins.Job, len(jobs))
+	var wg sync.WaitGroup
+
+	for w := 0; w < c.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobsChan {
+				func() {
+					defer c.recoverJob(job)
+					fn(job)
+				}()
+			}
+		}()
+	}
+
+	for _, job := range c.prioritized(jobs) {
+		jobsChan <- job
+	}
+	close(jobsChan)
+
+	wg.Wait()
+}
+
+// prioritized returns the jobs ordered by the configured priority prefixes.
+func (c *JobCollector) prioritized(jobs []jenkins.Job) []jenkins.Job {
+	return jenkins.PrioritizeJobs(jobs, func(job jenkins.Job) string {
+		return job.Path
+	}, c.priorityJobs)
+}
+
+// collectBuildNumbers exports the numbers of the last successful and the last
+// unsuccessful build, they are skipped if the job has no such build.
+func (c *JobCollector) collectBuildNumbers(ch chan<- prometheus.Metric, job jenkins.Job, labels []string) {
+	if job.LastSuccessfulBuild != nil {
+		ch <- prometheus.MustNewConstMetric(
+			c.LastSuccessfulBuild,
+			prometheus.GaugeValue,
+			float64(job.LastSuccessfulBuild.Number),
+			labels...,
+		)
+	}
+
+	if job.LastUnsuccessfulBuild != nil {
+		ch <- prometheus.MustNewConstMetric(
+			c.LastUnsuccessfulBuild,
+			prometheus.GaugeValue,
+			float64(job.LastUnsuccessfulBuild.Number),
+			labels...,
+		)
+	}
+}
+
+// filterJobs drops the excluded top-level jobs and the jobs resulting in an
+// already used job_name label.
+func (c *JobCollector) filterJobs(jobs []jenkins.Job) []jenkins.Job {
+	if len(c.excludedJobs) > 0 {
+		filtered := make([]jenkins.Job, 0, len(jobs))
+		for _, job := range jobs {
+			if jenkins.IsExcludedJob(job.Path, c.excludedJobs) {
+				continue
 			}
-			var (
-				disabled float64
+			filtered = append(filtered, job)
+		}
+		jobs = filtered
+	}
+
+	// 相同的 job_name 标签会使整个抓取失败，只保留第一个作业
+	return c.uniqueJobs(jobs)
+}
+
+// jobList returns the filtered job list, which gets loaded from the cache file
+// if available and from Jenkins otherwise.
+func (c *JobCollector) jobList(ctx context.Context) ([]jenkins.Job, error) {
+	if jobs, fromCache, needsUpdate := c.loadJobsFromCache(); fromCache {
+		if needsUpdate {
+			go c.updateCacheInBackground()
+		}
+
+		return c.filterJobs(jobs), nil
+	}
+
+	jobs, _, err := c.client.Job.All(ctx, c.folders)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.saveJobsToCache(jobs); err != nil {
+		c.logger.Warn("保存缓存失败",
+			"错误", err,
+		)
+	}
+
+	return c.filterJobs(jobs), nil
+}
+
+// uniqueJobs drops jobs resulting in an already used job_name label, e.g.
+// jobs in different folders with an identical full name. The duplicates are
+// logged, as they would fail the whole scrape with inconsistent metrics.
+func (c *JobCollector) uniqueJobs(jobs []jenkins.Job) []jenkins.Job {
+	seen := make(map[string]bool, len(jobs))
+	result := make([]jenkins.Job, 0, len(jobs))
+
+	for _, job := range jobs {
+		name := c.jobName(job)
+
+		if seen[name] {
+			c.logger.Warn("作业名称重复，跳过该作业",
+				"作业", name,
+				"url", job.URL,
 			)
 
-			labels := []string{
-				job.Path, // path 就是 jobname，不需要 name 和 class
+			continue
+		}
+
+		seen[name] = true
+		result = append(result, job)
+	}
+
+	return result
+}
+
+// jobName returns the value of the job_name label, which is the full path or
+// the display name of the job depending on the configured name source.
+func (c *JobCollector) jobName(job jenkins.Job) string {
+	if c.nameSource == NameSourceDisplay && job.Name != "" {
+		return applyNameRules(job.Name, c.nameRules)
+	}
+
+	// 只改变标签，请求 API 时仍然使用原始路径
+	return applyNameRules(job.Path, c.nameRules)
+}
+
+// colorStatus infers the status of the last build from the color of the job.
+func colorStatus(color string) string {
+	switch color {
+	case "blue", "blue_anime":
+		return "success"
+	case "red", "red_anime":
+		return "failure"
+	case "aborted", "aborted_anime":
+		return "aborted"
+	case "yellow", "yellow_anime":
+		return "unstable"
+	default:
+		return "not_built"
+	}
+}
+
+// Statuses returns the status of the cached jobs inferred from their color,
+// without requesting Jenkins. It returns false if there is no cached job list.
+func (c *JobCollector) Statuses() ([]jenkins.JobStatus, bool) {
+	jobs, fromCache, _ := c.loadJobsFromCache()
+	if !fromCache {
+		return nil, false
+	}
+
+	statuses := make([]jenkins.JobStatus, 0, len(jobs))
+	for _, job := range jobs {
+		if jenkins.IsExcludedJob(job.Path, c.excludedJobs) {
+			continue
+		}
+
+		status := jenkins.JobStatus{
+			JobName: c.jobName(job),
+			Status:  "not_built",
+		}
+
+		if job.LastBuild != nil {
+			status.Status = colorStatus(job.Color)
+			status.LastBuild = int64(job.LastBuild.Number)
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, true
+}
+
+// latestBuilds points the last build of cached jobs to the lastBuild URL of
+// the job, the cached build number may be outdated already.
+func latestBuilds(jobs []jenkins.Job) []jenkins.Job {
+	for i, job := range jobs {
+		if job.LastBuild == nil || job.URL == "" {
+			continue
+		}
+
+		jobs[i].LastBuild = &jenkins.BuildNumber{
+			Number: job.LastBuild.Number,
+			URL:    strings.TrimRight(job.URL, "/") + "/lastBuild/",
+		}
+	}
+
+	return jobs
+}
+
+// collectNeverBuilt exports the metrics of a job without any build, either as
+// not_built status or as pending first build if enabled.
+func (c *JobCollector) collectNeverBuilt(ch chan<- prometheus.Metric, job jenkins.Job) {
+	// 新作业不计入任何构建状态，避免影响按状态分组的看板
+	if c.pendingFirstBuild {
+		ch <- prometheus.MustNewConstMetric(
+			c.PendingFirstBuild,
+			prometheus.GaugeValue,
+			1.0,
+			c.jobName(job),
+		)
+
+		return
+	}
+
+	// 如果没有 LastBuild，仍然导出构建结果指标（未构建状态）
+	// 只包含4个标签：job_name, check_commitID, gitBranch, status
+	labels := []string{
+		c.jobName(job),
+		"",          // check_commitID
+		"",          // gitBranch
+		"not_built", // status
+	}
+
+	// 从未构建的作业没有原始结果和参数
+	if c.resultLabel {
+		labels = append(labels, "")
+	}
+
+	labels = append(labels, c.paramLabelValues(nil)...)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.BuildLastResult,
+		prometheus.GaugeValue,
+		1.0, // 值为1表示这是当前状态
+		labels...,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.BuildStatus,
+		prometheus.GaugeValue,
+		jenkins.BuildStatusValue("not_built"),
+		c.jobName(job),
+	)
+}
+
+// collectRecentBuilds exports the result distribution of the recent builds
+// for every job, using one request per job.
+func (c *JobCollector) collectRecentBuilds(ch chan<- prometheus.Metric, jobs []jenkins.Job) {
+	c.eachJob(jobs, func(job jenkins.Job) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		results, err := c.client.Job.RecentResults(ctx, job, c.recentBuilds)
+
+		if err != nil {
+			c.logger.Log(ctx, jenkins.LevelTrace, "获取最近构建结果失败",
+				"作业", job.Path,
+				"错误", err,
+			)
+			return
+		}
+
+		var success, failure float64
+
+		for _, result := range results {
+			switch result {
+			case "SUCCESS":
+				success++
+			case "FAILURE":
+				failure++
 			}
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.RecentSuccess,
+			prometheus.GaugeValue,
+			success,
+			c.jobName(job),
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.RecentFailure,
+			prometheus.GaugeValue,
+			failure,
+			c.jobName(job),
+		)
+	})
+}
+
+// collectBuildsRetained exports the number of retained builds for every job,
+// using one request per job.
+func (c *JobCollector) collectBuildsRetained(ch chan<- prometheus.Metric, jobs []jenkins.Job) {
+	c.eachJob(jobs, func(job jenkins.Job) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		count, err := c.client.Job.RetainedBuilds(ctx, job)
+
+		if err != nil {
+			c.logger.Log(ctx, jenkins.LevelTrace, "获取保留构建数量失败",
+				"作业", job.Path,
+				"错误", err,
+			)
+			return
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.BuildsRetained,
+			prometheus.GaugeValue,
+			float64(count),
+			c.jobName(job),
+		)
+	})
+}
+
+// collectSCMPolls exports the result of the last SCM poll for every job
+// which polls its SCM, using one request per job.
+func (c *JobCollector) collectSCMPolls(ch chan<- prometheus.Metric, jobs []jenkins.Job) {
+	c.eachJob(jobs, func(job jenkins.Job) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		poll, err := c.client.Job.PollLog(ctx, job)
+
+		if err != nil {
+			c.logger.Log(ctx, jenkins.LevelTrace, "获取 SCM 轮询日志失败",
+				"作业", job.Path,
+				"错误", err,
+			)
+			return
+		}
 
-			if job.Disabled {
-				disabled = 1.0
+		// 未配置 SCM 轮询的作业不导出
+		if poll == nil {
+			return
+		}
+
+		if poll.Finished {
+			var changes float64
+
+			if poll.HasChanges {
+				changes = 1.0
 			}
 
 			ch <- prometheus.MustNewConstMetric(
-				c.Disabled,
+				c.SCMPollChanges,
 				prometheus.GaugeValue,
-				disabled,
-				labels...,
+				changes,
+				c.jobName(job),
 			)
+		}
 
-			if job.LastBuild != nil {
-				// 未启用构建详情，使用作业颜色推断状态
-				var statusLabel string
-				switch job.Color {
-				case "blue", "blue_anime":
-					statusLabel = "success"
-				case "red", "red_anime":
-					statusLabel = "failure"
-				case "aborted", "aborted_anime":
-					statusLabel = "aborted"
-				case "yellow", "yellow_anime":
-					statusLabel = "unstable"
-				default:
-					statusLabel = "not_built"
-				}
+		if !poll.StartedAt.IsZero() {
+			ch <- prometheus.MustNewConstMetric(
+				c.SCMPollTimestamp,
+				prometheus.GaugeValue,
+				float64(poll.StartedAt.Unix()),
+				c.jobName(job),
+			)
+		}
+	})
+}
 
-				// 导出统一的构建结果指标
-				// 只包含4个标签：job_name, check_commitID, gitBranch, status
-				labelsBuildResult := []string{
-					job.Path,
-					"", // check_commitID
-					"", // gitBranch
-					statusLabel,
-				}
+// jobWorkers returns the size of the worker pools, it defaults to 10 and gets
+// clamped to 100 to avoid hammering Jenkins.
+func jobWorkers(concurrency int) int {
+	if concurrency <= 0 {
+		return 10
+	}
 
-				ch <- prometheus.MustNewConstMetric(
-					c.BuildLastResult,
-					prometheus.GaugeValue,
-					1.0,
-					labelsBuildResult...,
-				)
-			} else {
-				// 如果没有 LastBuild，仍然导出构建结果指标（未构建状态）
-				// 只包含4个标签：job_name, check_commitID, gitBranch, status
-				labelsBuildResult := []string{
-					job.Path,
-					"",          // check_commitID
-					"",          // gitBranch
-					"not_built", // status
-				}
+	return min(concurrency, 100)
+}
 
-				ch <- prometheus.MustNewConstMetric(
-					c.BuildLastResult,
-					prometheus.GaugeValue,
-					1.0,
-					labelsBuildResult...,
-				)
-			}
+// newScrapeLimit creates the semaphore limiting concurrent scrapes, it's nil
+// if the limit is disabled.
+func newScrapeLimit(limit int) chan struct{} {
+	if limit <= 0 {
+		return nil
+	}
+
+	return make(chan struct{}, limit)
+}
+
+// acquireScrape waits for a free scrape slot until the request timeout.
+func (c *JobCollector) acquireScrape() bool {
+	if c.scrapes == nil {
+		return true
+	}
+
+	timer := time.NewTimer(c.config.Timeout)
+	defer timer.Stop()
 
-			processedCount++
+	select {
+	case c.scrapes <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// releaseScrape frees the scrape slot acquired by acquireScrape.
+func (c *JobCollector) releaseScrape() {
+	if c.scrapes != nil {
+		<-c.scrapes
+	}
+}
+
+// fetchDetailsFor checks if build details should be fetched for the job, which
+// is the case for all jobs if no folders are configured.
+func (c *JobCollector) fetchDetailsFor(jobPath string) bool {
+	if len(c.buildDetailsFolders) == 0 {
+		return true
+	}
+
+	for _, folder := range c.buildDetailsFolders {
+		folder = strings.Trim(folder, "/")
+
+		if folder != "" && strings.HasPrefix(jobPath, folder+"/") {
+			return true
 		}
 	}
 
-	c.logger.Info("作业指标收集完成",
-		"总作业数", len(jobs),
-		"已处理作业数", processedCount,
-		"成功获取构建详情数", buildDetailsFetched,
-		"获取构建详情失败数", buildDetailsFailed,
-		"构建详情获取已启用", c.fetchBuildDetails,
+	return false
+}
+
+// collectLastSuccessCommits exports the commit and branch of the last
+// successful build for every job which succeeded at least once.
+func (c *JobCollector) collectLastSuccessCommits(ch chan<- prometheus.Metric, jobs []jenkins.Job, builds *buildCache) {
+	c.eachJob(jobs, func(job jenkins.Job) {
+		// 从未成功构建过的作业不导出
+		if job.LastSuccessfulBuild == nil {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		build, err := builds.Get(ctx, job.LastSuccessfulBuild)
+
+		if err != nil {
+			c.logger.Log(ctx, jenkins.LevelTrace, "获取最后一次成功构建失败",
+				"作业", job.Path,
+				"错误", err,
+			)
+			return
+		}
+
+		commit := extractParameter(build, "check_commitID")
+		if commit == "" {
+			commit = extractParameter(build, "GIT_COMMIT")
+		}
+
+		branch := extractParameter(build, "gitBranch")
+		if branch == "" {
+			branch = extractParameter(build, "GIT_BRANCH")
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.LastSuccessCommit,
+			prometheus.GaugeValue,
+			1.0,
+			c.jobName(job),
+			commit,
+			branch,
+		)
+	})
+}
+
+// collectNondefaultParams exports the number of parameters of the last build
+// which differ from the defaults defined by the job.
+func (c *JobCollector) collectNondefaultParams(ch chan<- prometheus.Metric, jobs []jenkins.Job, builds *buildCache) {
+	c.eachJob(jobs, func(job jenkins.Job) {
+		// 从未构建过的作业不导出
+		if job.LastBuild == nil {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		defaults, err := c.client.Job.ParameterDefaults(ctx, job)
+
+		if err != nil {
+			c.logger.Log(ctx, jenkins.LevelTrace, "获取参数定义失败",
+				"作业", job.Path,
+				"错误", err,
+			)
+			return
+		}
+
+		// 没有参数定义的作业不导出
+		if len(defaults) == 0 {
+			return
+		}
+
+		build, err := builds.Get(ctx, job.LastBuild)
+
+		if err != nil {
+			c.logger.Log(ctx, jenkins.LevelTrace, "获取最后一次构建失败",
+				"作业", job.Path,
+				"错误", err,
+			)
+			return
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.NondefaultParams,
+			prometheus.GaugeValue,
+			float64(countNondefaultParameters(build, defaults)),
+			c.jobName(job),
+		)
+	})
+}
+
+// collectUpstream exports the upstream job which triggered the last build,
+// builds triggered otherwise are skipped.
+func (c *JobCollector) collectUpstream(ch chan<- prometheus.Metric, jobs []jenkins.Job, builds *buildCache) {
+	c.eachJob(jobs, func(job jenkins.Job) {
+		// 从未构建过的作业不导出
+		if job.LastBuild == nil {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		build, err := builds.Get(ctx, job.LastBuild)
+
+		if err != nil {
+			c.logger.Log(ctx, jenkins.LevelTrace, "获取最后一次构建失败",
+				"作业", job.Path,
+				"错误", err,
+			)
+			return
+		}
+
+		upstream, ok := extractUpstream(build)
+
+		if !ok {
+			return
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.UpstreamInfo,
+			prometheus.GaugeValue,
+			1.0,
+			c.jobName(job),
+			upstream,
+		)
+	})
+}
+
+// recoverJob recovers from a panic while processing a single job, so one bad
+// job doesn't abort the whole scrape. It has to be deferred directly.
+func (c *JobCollector) recoverJob(job jenkins.Job) {
+	if r := recover(); r != nil {
+		c.logger.Error("处理作业时发生 panic，跳过该作业",
+			"作业", job.Path,
+			"panic", r,
+			"stack", string(debug.Stack()),
+		)
+
+		// 与其他指标使用相同的 job_name 标签
+		c.panics.WithLabelValues(c.jobName(job)).Inc()
+	}
+}
+
+// collectScrapeError exports the category of the failed collection, it's not
+// exported at all if the collection succeeds.
+func (c *JobCollector) collectScrapeError(ch chan<- prometheus.Metric, category string) {
+	ch <- prometheus.MustNewConstMetric(
+		c.LastScrapeError,
+		prometheus.GaugeValue,
+		1.0,
+		category,
+	)
+}
+
+// collectStatus exports the instance wide state from the root API.
+func (c *JobCollector) collectStatus(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+
+	status, err := c.client.Job.Status(ctx)
+
+	if err != nil {
+		c.logger.Warn("获取 Jenkins 状态失败",
+			"错误", err,
+		)
+
+		c.failures.WithLabelValues("job").Inc()
+		return
+	}
+
+	var quietingDown float64
+
+	if status.QuietingDown {
+		quietingDown = 1.0
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.QuietingDown,
+		prometheus.GaugeValue,
+		quietingDown,
 	)
 }
 
@@ -780,11 +1792,64 @@ func extractParameter(build jenkins.Build, paramName string) string {
 	return "" // 未找到参数
 }
 
+// extractQueueTime extracts the time the build waited in the queue from the
+// action added by the metrics plugin.
+func extractQueueTime(build jenkins.Build) (int64, bool) {
+	for _, action := range build.Actions {
+		if action.Class == "jenkins.metrics.impl.TimeInQueueAction" {
+			return action.QueuingDurationMillis, true
+		}
+	}
+
+	return 0, false
+}
+
+// extractUpstream extracts the upstream job from the cause of a build which
+// got triggered by another job.
+func extractUpstream(build jenkins.Build) (string, bool) {
+	for _, action := range build.Actions {
+		for _, cause := range action.Causes {
+			if cause.Class == "hudson.model.Cause$UpstreamCause" && cause.UpstreamProject != "" {
+				return cause.UpstreamProject, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// countNondefaultParameters counts the build parameters which differ from the
+// defaults, parameters without a definition are ignored.
+func countNondefaultParameters(build jenkins.Build, defaults map[string]interface{}) int {
+	count := 0
+
+	for _, action := range build.Actions {
+		if action.Class != "hudson.model.ParametersAction" {
+			continue
+		}
+
+		for _, param := range action.Parameters {
+			value, ok := defaults[param.Name]
+
+			if !ok {
+				continue
+			}
+
+			if fmt.Sprintf("%v", param.Value) != fmt.Sprintf("%v", value) {
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
 // buildStatusToValue converts build status to numeric value.
-// 0=success, 1=failure, 2=aborted, 3=unstable, 4=in_progress, 5=queued, 6=not_built
-func buildStatusToValue(result string, building bool, queueID int64) float64 {
+// 0=success, 1=failure, 2=aborted, 3=unstable, 4=in_progress, 5=waiting, 6=not_built, 7=unknown
+func buildStatusToValue(result string, building bool, queueID, duration int64, buildingResults []string, emptyResult string) float64 {
 	// 如果正在构建，返回 in_progress
-	if building {
+	// 部分插件不设置 building，而是在 result 中返回 RUNNING 等中间状态
+	if building || jenkins.IsBuildingResult(result, buildingResults) {
 		return 4.0 // 正在构建
 	}
 
@@ -799,6 +1864,18 @@ func buildStatusToValue(result string, building bool, queueID int64) float64 {
 		return 2.0
 	case "UNSTABLE":
 		return 3.0
+	case "NOT_BUILT":
+		return 6.0
+	}
+
+	// 无法识别的构建结果单独标记为 unknown，避免和未构建混淆
+	if result != "" {
+		return 7.0
+	}
+
+	// 已有耗时说明构建曾经启动过，例如流水线在第一个阶段前被中止
+	if duration > 0 {
+		return jenkins.BuildStatusValue(jenkins.EmptyResultStatus(duration, emptyResult))
 	}
 
 	// 如果 result 为空且 queueID > 0，可能是等待中（但这种情况很少，因为通常没有构建记录）
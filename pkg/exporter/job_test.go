@@ -0,0 +1,870 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/promhippie/jenkins_exporter/pkg/config"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkinstest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobCollectorArtifacts(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":           `{"jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/app/"}]}`,
+		"/job/app/api/json":   `{"_class": "hudson.model.FreeStyleProject", "fullName": "app", "url": "$URL/job/app/", "lastBuild": {"number": 7, "url": "$URL/job/app/7/"}}`,
+		"/job/app/7/api/json": `{"number": 7, "result": "SUCCESS", "artifacts": [{"fileName": "a.jar"}, {"fileName": "b.jar"}, {"fileName": "c.tgz"}]}`,
+	})
+
+	c := newTestCollector(t, srv, config.Collector{FetchBuildDetails: true, Artifacts: true})
+
+	expected := `
+# HELP jenkins_job_last_build_artifacts Number of artifacts archived by the last build
+# TYPE jenkins_job_last_build_artifacts gauge
+jenkins_job_last_build_artifacts{job_name="app"} 3
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_job_last_build_artifacts"))
+}
+
+func TestJobCollectorPendingFirstBuild(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":           `{"jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/app/"}, {"_class": "hudson.model.FreeStyleProject", "name": "fresh", "url": "$URL/job/fresh/"}]}`,
+		"/job/app/api/json":   `{"_class": "hudson.model.FreeStyleProject", "fullName": "app", "url": "$URL/job/app/", "color": "blue", "lastBuild": {"number": 7, "url": "$URL/job/app/7/"}}`,
+		"/job/app/7/api/json": `{"number": 7, "result": "SUCCESS"}`,
+		"/job/fresh/api/json": `{"_class": "hudson.model.FreeStyleProject", "fullName": "fresh", "url": "$URL/job/fresh/", "color": "notbuilt"}`,
+	})
+
+	for _, details := range []bool{true, false} {
+		c := newTestCollector(t, srv, config.Collector{FetchBuildDetails: details, PendingFirstBuild: true})
+
+		// fresh 从未构建，只导出等待首次构建指标
+		expected := `
+# HELP jenkins_build_last_result Last build result: 1 indicates current status, status label contains the actual status (success, failure, aborted, unstable, in_progress, waiting, not_built, unknown)
+# TYPE jenkins_build_last_result gauge
+jenkins_build_last_result{check_commitID="",gitBranch="",job_name="app",status="success"} 1
+# HELP jenkins_job_pending_first_build 1 if the job has never been built, only exported instead of the build status if enabled
+# TYPE jenkins_job_pending_first_build gauge
+jenkins_job_pending_first_build{job_name="fresh"} 1
+`
+
+		assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_build_last_result", "jenkins_job_pending_first_build"))
+		assert.Equal(t, 1, testutil.CollectAndCount(c, "jenkins_job_build_status"))
+	}
+}
+
+func TestJobCollectorNameSource(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":                  `{"jobs": [{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "team", "url": "$URL/job/team/"}]}`,
+		"/job/team/api/json":         `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/team/job/app/"}]}`,
+		"/job/team/job/app/api/json": `{"_class": "hudson.model.FreeStyleProject", "displayName": "Payment Service", "fullName": "team/app", "url": "$URL/job/team/job/app/", "color": "blue"}`,
+	})
+
+	for source, name := range map[string]string{NameSourcePath: "team/app", NameSourceDisplay: "Payment Service"} {
+		c := newTestCollector(t, srv, config.Collector{NameSource: source})
+
+		expected := `
+# HELP jenkins_job_disabled 1 if the job is disabled, 0 otherwise
+# TYPE jenkins_job_disabled gauge
+jenkins_job_disabled{job_name="` + name + `"} 0
+`
+
+		assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_job_disabled"))
+	}
+}
+
+func TestJobCollectorNameRules(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":                 `{"jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app-pr-12", "url": "$URL/job/app-pr-12/"}]}`,
+		"/job/app-pr-12/api/json":   `{"_class": "hudson.model.FreeStyleProject", "fullName": "app-pr-12", "url": "$URL/job/app-pr-12/", "color": "blue", "lastBuild": {"number": 3, "url": "$URL/job/app-pr-12/3/"}}`,
+		"/job/app-pr-12/3/api/json": `{"number": 3, "result": "SUCCESS", "timestamp": 1700000000000, "duration": 1000}`,
+	})
+
+	c := newTestCollector(t, srv, config.Collector{
+		FetchBuildDetails: true,
+		NameRules:         []string{`-pr-\d+$=`, `^app$=service`},
+	})
+
+	// 构建详情仍然通过原始路径请求
+	expected := `
+# HELP jenkins_job_duration Duration of last build in ms
+# TYPE jenkins_job_duration gauge
+jenkins_job_duration{job_name="service"} 1000
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_job_duration"))
+
+	// panic 计数器使用相同的 job_name 标签
+	func() {
+		defer c.recoverJob(jenkins.Job{Path: "app-pr-12"})
+		panic("boom")
+	}()
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.panics.WithLabelValues("service")))
+}
+
+func TestJobCollectorQueueTime(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":           `{"jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/app/"}]}`,
+		"/job/app/api/json":   `{"_class": "hudson.model.FreeStyleProject", "fullName": "app", "url": "$URL/job/app/", "color": "blue", "lastBuild": {"number": 7, "url": "$URL/job/app/7/"}}`,
+		"/job/app/7/api/json": `{"number": 7, "result": "SUCCESS", "timestamp": 1700000000000, "duration": 90000, "actions": [{"_class": "jenkins.metrics.impl.TimeInQueueAction", "queuingDurationMillis": 45000}]}`,
+	})
+
+	c := newTestCollector(t, srv, config.Collector{FetchBuildDetails: true})
+
+	expected := `
+# HELP jenkins_job_duration Duration of last build in ms
+# TYPE jenkins_job_duration gauge
+jenkins_job_duration{job_name="app"} 90000
+# HELP jenkins_job_last_build_queue_time_ms Time the last build waited in the queue before it started in ms, requires the metrics plugin
+# TYPE jenkins_job_last_build_queue_time_ms gauge
+jenkins_job_last_build_queue_time_ms{job_name="app"} 45000
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_job_duration", "jenkins_job_last_build_queue_time_ms"))
+}
+
+func TestJobCollectorBuildHung(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json": `{"jobs": [
+			{"_class": "hudson.model.FreeStyleProject", "name": "hung", "url": "$URL/job/hung/"},
+			{"_class": "hudson.model.FreeStyleProject", "name": "running", "url": "$URL/job/running/"},
+			{"_class": "hudson.model.FreeStyleProject", "name": "done", "url": "$URL/job/done/"}
+		]}`,
+		"/job/hung/api/json":      `{"_class": "hudson.model.FreeStyleProject", "fullName": "hung", "url": "$URL/job/hung/", "lastBuild": {"number": 3, "url": "$URL/job/hung/3/"}}`,
+		"/job/hung/3/api/json":    `{"number": 3, "building": true, "timestamp": 1700000000000, "estimatedDuration": 60000}`,
+		"/job/running/api/json":   `{"_class": "hudson.model.FreeStyleProject", "fullName": "running", "url": "$URL/job/running/", "lastBuild": {"number": 4, "url": "$URL/job/running/4/"}}`,
+		"/job/running/4/api/json": `{"number": 4, "building": true, "timestamp": 1700000000000, "estimatedDuration": 600000}`,
+		"/job/done/api/json":      `{"_class": "hudson.model.FreeStyleProject", "fullName": "done", "url": "$URL/job/done/", "lastBuild": {"number": 5, "url": "$URL/job/done/5/"}}`,
+		"/job/done/5/api/json":    `{"number": 5, "result": "SUCCESS", "timestamp": 1700000000000, "duration": 900000, "estimatedDuration": 60000}`,
+	})
+
+	c := newTestCollector(t, srv, config.Collector{FetchBuildDetails: true, HungMultiplier: 2})
+
+	// 构建开始5分钟后，超过估算耗时2倍的构建视为卡住
+	c.clock = jenkinstest.NewClock(time.UnixMilli(1700000000000).Add(5 * time.Minute))
+
+	expected := `
+# HELP jenkins_job_build_hung 1 if the last build is still running and exceeded its estimated duration by the configured multiplier
+# TYPE jenkins_job_build_hung gauge
+jenkins_job_build_hung{job_name="done"} 0
+jenkins_job_build_hung{job_name="hung"} 1
+jenkins_job_build_hung{job_name="running"} 0
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_job_build_hung"))
+}
+
+func TestJobCollectorBuildNode(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json": `{"jobs": [
+			{"_class": "hudson.model.FreeStyleProject", "name": "agent", "url": "$URL/job/agent/"},
+			{"_class": "hudson.model.FreeStyleProject", "name": "builtin", "url": "$URL/job/builtin/"}
+		]}`,
+		"/job/agent/api/json":     `{"_class": "hudson.model.FreeStyleProject", "fullName": "agent", "url": "$URL/job/agent/", "lastBuild": {"number": 7, "url": "$URL/job/agent/7/"}}`,
+		"/job/agent/7/api/json":   `{"number": 7, "result": "FAILURE", "builtOn": "linux-agent-3"}`,
+		"/job/builtin/api/json":   `{"_class": "hudson.model.FreeStyleProject", "fullName": "builtin", "url": "$URL/job/builtin/", "lastBuild": {"number": 2, "url": "$URL/job/builtin/2/"}}`,
+		"/job/builtin/2/api/json": `{"number": 2, "result": "SUCCESS", "builtOn": ""}`,
+	})
+
+	c := newTestCollector(t, srv, config.Collector{FetchBuildDetails: true, BuildNode: true})
+
+	expected := `
+# HELP jenkins_job_last_build_node_info Constant 1 for jobs whose last build reports the node it ran on, the node label contains its name
+# TYPE jenkins_job_last_build_node_info gauge
+jenkins_job_last_build_node_info{job_name="agent",node="linux-agent-3"} 1
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_job_last_build_node_info"))
+}
+
+func TestJobCollectorMinBuildNumber(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json": `{"jobs": [
+			{"_class": "hudson.model.FreeStyleProject", "name": "old", "url": "$URL/job/old/"},
+			{"_class": "hudson.model.FreeStyleProject", "name": "new", "url": "$URL/job/new/"}
+		]}`,
+		"/job/old/api/json":     `{"_class": "hudson.model.FreeStyleProject", "fullName": "old", "url": "$URL/job/old/", "color": "red", "lastBuild": {"number": 3, "url": "$URL/job/old/3/"}, "lastUnsuccessfulBuild": {"number": 3, "url": "$URL/job/old/3/"}}`,
+		"/job/old/3/api/json":   `{"number": 3, "result": "FAILURE", "duration": 1000}`,
+		"/job/new/api/json":     `{"_class": "hudson.model.FreeStyleProject", "fullName": "new", "url": "$URL/job/new/", "color": "blue", "lastBuild": {"number": 120, "url": "$URL/job/new/120/"}, "lastSuccessfulBuild": {"number": 120, "url": "$URL/job/new/120/"}}`,
+		"/job/new/120/api/json": `{"number": 120, "result": "SUCCESS", "duration": 2000}`,
+	})
+
+	c := newTestCollector(t, srv, config.Collector{FetchBuildDetails: true, MinBuildNumber: 100})
+
+	expected := `
+# HELP jenkins_job_build_status ` + jenkins.BuildStatusHelp + `
+# TYPE jenkins_job_build_status gauge
+jenkins_job_build_status{job_name="new"} 0
+jenkins_job_build_status{job_name="old"} 6
+# HELP jenkins_job_duration Duration of last build in ms
+# TYPE jenkins_job_duration gauge
+jenkins_job_duration{job_name="new"} 2000
+# HELP jenkins_job_last_successful_build Number of the last successful build of the job
+# TYPE jenkins_job_last_successful_build gauge
+jenkins_job_last_successful_build{job_name="new"} 120
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_job_build_status", "jenkins_job_duration", "jenkins_job_last_successful_build", "jenkins_job_last_unsuccessful_build"))
+}
+
+func TestJobCollectorLastScrapeError(t *testing.T) {
+	var mode atomic.Value
+	mode.Store("")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		switch mode.Load() {
+		case jenkins.ScrapeErrorTimeout:
+			time.Sleep(200 * time.Millisecond)
+		case jenkins.ScrapeErrorAuth:
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		case jenkins.ScrapeErrorNonJSON:
+			_, _ = io.WriteString(w, "<html><body>Login</body></html>")
+			return
+		}
+
+		_, _ = io.WriteString(w, `{"jobs": []}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := newTestCollector(t, srv, config.Collector{})
+	c.config.Timeout = 50 * time.Millisecond
+
+	for _, category := range []string{
+		jenkins.ScrapeErrorTimeout,
+		jenkins.ScrapeErrorAuth,
+		jenkins.ScrapeErrorNonJSON,
+	} {
+		mode.Store(category)
+
+		expected := `
+# HELP jenkins_last_scrape_error_info Constant 1 if the last collection of the jobs failed, the error label contains the category of the failure
+# TYPE jenkins_last_scrape_error_info gauge
+jenkins_last_scrape_error_info{error="` + category + `"} 1
+`
+
+		assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_last_scrape_error_info"), category)
+
+		// 采集成功后不再导出错误
+		mode.Store("")
+		assert.Equal(t, 0, testutil.CollectAndCount(c, "jenkins_last_scrape_error_info"), category)
+	}
+
+	// 无法连接时归类为连接错误
+	srv.Close()
+
+	expected := `
+# HELP jenkins_last_scrape_error_info Constant 1 if the last collection of the jobs failed, the error label contains the category of the failure
+# TYPE jenkins_last_scrape_error_info gauge
+jenkins_last_scrape_error_info{error="connection"} 1
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_last_scrape_error_info"))
+}
+
+func TestJobCollectorParamLabels(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json": `{"jobs": [
+			{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/app/"},
+			{"_class": "hudson.model.FreeStyleProject", "name": "fresh", "url": "$URL/job/fresh/"}
+		]}`,
+		"/job/app/api/json":   `{"_class": "hudson.model.FreeStyleProject", "fullName": "app", "url": "$URL/job/app/", "color": "blue", "lastBuild": {"number": 2, "url": "$URL/job/app/2/"}}`,
+		"/job/app/2/api/json": `{"result": "SUCCESS", "duration": 1000, "actions": [{"_class": "hudson.model.ParametersAction", "parameters": [{"name": "DEPLOY_ENV", "value": "prod"}]}]}`,
+		"/job/fresh/api/json": `{"_class": "hudson.model.FreeStyleProject", "fullName": "fresh", "url": "$URL/job/fresh/", "color": "notbuilt"}`,
+	})
+
+	expected := `
+# HELP jenkins_build_last_result ` + jenkins.BuildLastResultHelp + `
+# TYPE jenkins_build_last_result gauge
+jenkins_build_last_result{DEPLOY_ENV="",SERVICE="",check_commitID="",gitBranch="",job_name="fresh",status="not_built"} 1
+jenkins_build_last_result{DEPLOY_ENV="prod",SERVICE="",check_commitID="",gitBranch="",job_name="app",status="success"} 1
+`
+
+	c := newTestCollector(t, srv, config.Collector{FetchBuildDetails: true, ParamLabels: []string{"DEPLOY_ENV", "SERVICE"}})
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_build_last_result"))
+}
+
+func TestJobCollectorQuietingDown(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json": `{"mode": "NORMAL", "quietingDown": true, "jobs": []}`,
+	})
+
+	c := newTestCollector(t, srv, config.Collector{})
+
+	expected := `
+# HELP jenkins_quieting_down 1 if Jenkins is quieting down in preparation for a restart, 0 otherwise
+# TYPE jenkins_quieting_down gauge
+jenkins_quieting_down 1
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_quieting_down"))
+}
+
+func TestJobCollectorBuildingResults(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":           `{"jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/app/"}]}`,
+		"/job/app/api/json":   `{"_class": "hudson.model.FreeStyleProject", "fullName": "app", "url": "$URL/job/app/", "lastBuild": {"number": 7, "url": "$URL/job/app/7/"}}`,
+		"/job/app/7/api/json": `{"number": 7, "result": "RUNNING", "building": false}`,
+	})
+
+	c := newTestCollector(t, srv, config.Collector{FetchBuildDetails: true, BuildingResults: []string{"IN_PROGRESS", "RUNNING"}})
+
+	expected := `
+# HELP jenkins_build_last_result Last build result: 1 indicates current status, status label contains the actual status (success, failure, aborted, unstable, in_progress, waiting, not_built, unknown)
+# TYPE jenkins_build_last_result gauge
+jenkins_build_last_result{check_commitID="",gitBranch="",job_name="app",status="in_progress"} 1
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_build_last_result"))
+}
+
+func TestJobCollectorCacheTTL(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{})
+	cacheFile := filepath.Join(t.TempDir(), "jobs.json")
+
+	c := newTestCollector(t, srv, config.Collector{CacheFile: cacheFile, CacheTTL: 30 * time.Minute})
+	clock := jenkinstest.NewClock(time.Now())
+	c.clock = clock
+
+	assert.NoError(t, c.saveJobsToCache([]jenkins.Job{{Name: "app", Path: "app"}}))
+
+	jobs, fromCache, needsUpdate := c.loadJobsFromCache()
+	assert.True(t, fromCache)
+	assert.False(t, needsUpdate)
+	assert.Len(t, jobs, 1)
+
+	clock.Advance(31 * time.Minute)
+
+	jobs, fromCache, needsUpdate = c.loadJobsFromCache()
+	assert.True(t, fromCache)
+	assert.True(t, needsUpdate)
+	assert.Len(t, jobs, 1)
+}
+
+func TestJobCollectorCacheHitBuildDetails(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/job/app/lastBuild/api/json": `{"number": 8, "result": "FAILURE", "timestamp": 1700000000000, "duration": 90000}`,
+	})
+
+	cacheFile := filepath.Join(t.TempDir(), "jobs.json")
+	c := newTestCollector(t, srv, config.Collector{FetchBuildDetails: true, CacheFile: cacheFile, CacheTTL: 30 * time.Minute})
+
+	// 缓存中的构建编号已经过期，构建详情按作业的 lastBuild 地址获取
+	assert.NoError(t, c.saveJobsToCache([]jenkins.Job{{
+		Name:      "app",
+		Path:      "app",
+		URL:       srv.URL + "/job/app/",
+		Color:     "blue",
+		LastBuild: &jenkins.BuildNumber{Number: 7, URL: srv.URL + "/job/app/7/"},
+	}}))
+
+	expected := `
+# HELP jenkins_job_duration Duration of last build in ms
+# TYPE jenkins_job_duration gauge
+jenkins_job_duration{job_name="app"} 90000
+# HELP jenkins_job_start_time Start time of last build as unix timestamp
+# TYPE jenkins_job_start_time gauge
+jenkins_job_start_time{job_name="app"} 1.7e+12
+# HELP jenkins_job_end_time Start time of last build as unix timestamp
+# TYPE jenkins_job_end_time gauge
+jenkins_job_end_time{job_name="app"} 1.70000009e+12
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_job_duration", "jenkins_job_start_time", "jenkins_job_end_time"))
+}
+
+func TestJobCollectorExcludedJobs(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":           `{"jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/app/"}, {"_class": "hudson.model.FreeStyleProject", "name": "noisy", "url": "$URL/job/noisy/"}]}`,
+		"/job/app/api/json":   `{"_class": "hudson.model.FreeStyleProject", "fullName": "app", "url": "$URL/job/app/"}`,
+		"/job/noisy/api/json": `{"_class": "hudson.model.FreeStyleProject", "fullName": "noisy", "url": "$URL/job/noisy/"}`,
+	})
+
+	c := newTestCollector(t, srv, config.Collector{ExcludedJobs: []string{"noisy"}})
+
+	expected := `
+# HELP jenkins_job_disabled 1 if the job is disabled, 0 otherwise
+# TYPE jenkins_job_disabled gauge
+jenkins_job_disabled{job_name="app"} 0
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_job_disabled"))
+}
+
+func TestJobCollectorRecentBuilds(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json": `{"jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/app/"}]}`,
+		"/job/app/api/json": `{"_class": "hudson.model.FreeStyleProject", "fullName": "app", "url": "$URL/job/app/", "builds": [
+			{"result": "SUCCESS"}, {"result": "FAILURE"}, {"result": "SUCCESS"}, {"result": "SUCCESS"}, {"result": "FAILURE"},
+			{"result": "SUCCESS"}, {"result": "ABORTED"}, {"result": "SUCCESS"}, {"result": "FAILURE"}, {"result": "SUCCESS"}
+		]}`,
+	})
+
+	c := newTestCollector(t, srv, config.Collector{RecentBuilds: 10})
+
+	expected := `
+# HELP jenkins_job_recent_builds_failure Number of failed builds within the recent builds window
+# TYPE jenkins_job_recent_builds_failure gauge
+jenkins_job_recent_builds_failure{job_name="app"} 3
+# HELP jenkins_job_recent_builds_success Number of successful builds within the recent builds window
+# TYPE jenkins_job_recent_builds_success gauge
+jenkins_job_recent_builds_success{job_name="app"} 6
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_job_recent_builds_success", "jenkins_job_recent_builds_failure"))
+}
+
+func TestJobCollectorAccessLimited(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":            `{"jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/app/"}, {"_class": "hudson.model.FreeStyleProject", "name": "open", "url": "$URL/job/open/"}]}`,
+		"/job/app/api/json":    `{"_class": "hudson.model.FreeStyleProject", "fullName": "app", "url": "$URL/job/app/", "disabled": true, "color": "red", "lastBuild": {"number": 7, "url": "$URL/job/app/7/"}}`,
+		"/job/app/7/api/json":  "HTTP 403",
+		"/job/open/api/json":   `{"_class": "hudson.model.FreeStyleProject", "fullName": "open", "url": "$URL/job/open/", "lastBuild": {"number": 2, "url": "$URL/job/open/2/"}}`,
+		"/job/open/2/api/json": `{"number": 2, "result": "SUCCESS"}`,
+	})
+
+	c := newTestCollector(t, srv, config.Collector{FetchBuildDetails: true, AccessLimited: true})
+
+	expected := `
+# HELP jenkins_job_access_limited 1 if the job is listed but its build details are forbidden for the credentials
+# TYPE jenkins_job_access_limited gauge
+jenkins_job_access_limited{job_name="app"} 1
+# HELP jenkins_job_disabled 1 if the job is disabled, 0 otherwise
+# TYPE jenkins_job_disabled gauge
+jenkins_job_disabled{job_name="app"} 1
+jenkins_job_disabled{job_name="open"} 0
+# HELP jenkins_build_last_result Last build result: 1 indicates current status, status label contains the actual status (success, failure, aborted, unstable, in_progress, waiting, not_built, unknown)
+# TYPE jenkins_build_last_result gauge
+jenkins_build_last_result{check_commitID="",gitBranch="",job_name="app",status="failure"} 1
+jenkins_build_last_result{check_commitID="",gitBranch="",job_name="open",status="success"} 1
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_job_access_limited", "jenkins_job_disabled", "jenkins_build_last_result"))
+}
+
+func TestJobCollectorUnknownBuildStatus(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":             `{"jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/app/"}, {"_class": "hudson.model.FreeStyleProject", "name": "fresh", "url": "$URL/job/fresh/"}, {"_class": "hudson.model.FreeStyleProject", "name": "skipped", "url": "$URL/job/skipped/"}]}`,
+		"/job/app/api/json":     `{"_class": "hudson.model.FreeStyleProject", "fullName": "app", "url": "$URL/job/app/", "lastBuild": {"number": 7, "url": "$URL/job/app/7/"}}`,
+		"/job/app/7/api/json":   `{"number": 7, "result": "CUSTOM_RESULT"}`,
+		"/job/fresh/api/json":   `{"_class": "hudson.model.FreeStyleProject", "fullName": "fresh", "url": "$URL/job/fresh/"}`,
+		"/job/skipped/api/json": `{"_class": "hudson.model.FreeStyleProject", "fullName": "skipped", "url": "$URL/job/skipped/", "lastBuild": {"number": 2, "url": "$URL/job/skipped/2/"}}`,
+		// Jenkins 的 NOT_BUILT 结果和从未构建使用相同的状态
+		"/job/skipped/2/api/json": `{"number": 2, "result": "NOT_BUILT"}`,
+	})
+
+	c := newTestCollector(t, srv, config.Collector{FetchBuildDetails: true})
+
+	expected := `
+# HELP jenkins_job_build_status Numeric status of the last build: 0 success, 1 failure, 2 aborted, 3 unstable, 4 in_progress, 5 waiting, 6 not_built, 7 unknown
+# TYPE jenkins_job_build_status gauge
+jenkins_job_build_status{job_name="app"} 7
+jenkins_job_build_status{job_name="fresh"} 6
+jenkins_job_build_status{job_name="skipped"} 6
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_job_build_status"))
+}
+
+func TestJobCollectorEmptyResultStatus(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":            `{"jobs": [{"_class": "org.jenkinsci.plugins.workflow.job.WorkflowJob", "name": "pipe", "url": "$URL/job/pipe/"}]}`,
+		"/job/pipe/api/json":   `{"_class": "org.jenkinsci.plugins.workflow.job.WorkflowJob", "fullName": "pipe", "url": "$URL/job/pipe/", "lastBuild": {"number": 4, "url": "$URL/job/pipe/4/"}}`,
+		"/job/pipe/4/api/json": `{"number": 4, "result": null, "building": false, "duration": 1200}`,
+	})
+
+	c := newTestCollector(t, srv, config.Collector{FetchBuildDetails: true, EmptyResultStatus: "aborted"})
+
+	expected := `
+# HELP jenkins_job_build_status Numeric status of the last build: 0 success, 1 failure, 2 aborted, 3 unstable, 4 in_progress, 5 waiting, 6 not_built, 7 unknown
+# TYPE jenkins_job_build_status gauge
+jenkins_job_build_status{job_name="pipe"} 2
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_job_build_status"))
+}
+
+func TestJobCollectorBuildDetailsFolders(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":                        `{"jobs": [{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "critical", "url": "$URL/job/critical/"}, {"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "other", "url": "$URL/job/other/"}]}`,
+		"/job/critical/api/json":           `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "critical", "jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/critical/job/app/"}]}`,
+		"/job/critical/job/app/api/json":   `{"_class": "hudson.model.FreeStyleProject", "fullName": "critical/app", "color": "blue", "lastBuild": {"number": 3, "url": "$URL/job/critical/job/app/3/"}}`,
+		"/job/critical/job/app/3/api/json": `{"number": 3, "result": "FAILURE", "actions": [{"_class": "hudson.model.ParametersAction", "parameters": [{"name": "gitBranch", "value": "main"}]}]}`,
+		"/job/other/api/json":              `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "other", "jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "lib", "url": "$URL/job/other/job/lib/"}]}`,
+		"/job/other/job/lib/api/json":      `{"_class": "hudson.model.FreeStyleProject", "fullName": "other/lib", "color": "blue", "lastBuild": {"number": 5, "url": "$URL/job/other/job/lib/5/"}}`,
+		"/job/other/job/lib/5/api/json":    `{"number": 5, "result": "FAILURE", "actions": [{"_class": "hudson.model.ParametersAction", "parameters": [{"name": "gitBranch", "value": "main"}]}]}`,
+	})
+
+	c := newTestCollector(t, srv, config.Collector{FetchBuildDetails: true, BuildDetailsFolders: []string{"critical"}})
+
+	// other/lib 的构建详情未被获取，状态由作业颜色推断
+	expected := `
+# HELP jenkins_build_last_result Last build result: 1 indicates current status, status label contains the actual status (success, failure, aborted, unstable, in_progress, waiting, not_built, unknown)
+# TYPE jenkins_build_last_result gauge
+jenkins_build_last_result{check_commitID="",gitBranch="main",job_name="critical/app",status="failure"} 1
+jenkins_build_last_result{check_commitID="",gitBranch="",job_name="other/lib",status="success"} 1
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_build_last_result"))
+}
+
+func TestJobCollectorMaxConcurrentScrapes(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		for {
+			observed := maxInFlight.Load()
+			if current <= observed || maxInFlight.CompareAndSwap(observed, current) {
+				break
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		_, _ = io.WriteString(w, `{"mode": "NORMAL", "jobs": []}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := newTestCollector(t, srv, config.Collector{MaxConcurrentScrapes: 1})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			testutil.CollectAndCount(c)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), maxInFlight.Load())
+}
+
+func TestJobCollectorLastSuccessCommit(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":              `{"jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/app/"}, {"_class": "hudson.model.FreeStyleProject", "name": "broken", "url": "$URL/job/broken/"}]}`,
+		"/job/app/api/json":      `{"_class": "hudson.model.FreeStyleProject", "fullName": "app", "color": "red", "lastBuild": {"number": 8, "url": "$URL/job/app/8/"}, "lastSuccessfulBuild": {"number": 7, "url": "$URL/job/app/7/"}}`,
+		"/job/app/8/api/json":    `{"number": 8, "result": "FAILURE", "actions": [{"_class": "hudson.model.ParametersAction", "parameters": [{"name": "check_commitID", "value": "bbb222"}, {"name": "gitBranch", "value": "feature"}]}]}`,
+		"/job/app/7/api/json":    `{"number": 7, "result": "SUCCESS", "actions": [{"_class": "hudson.model.ParametersAction", "parameters": [{"name": "check_commitID", "value": "aaa111"}, {"name": "gitBranch", "value": "main"}]}]}`,
+		"/job/broken/api/json":   `{"_class": "hudson.model.FreeStyleProject", "fullName": "broken", "color": "red", "lastBuild": {"number": 1, "url": "$URL/job/broken/1/"}}`,
+		"/job/broken/1/api/json": `{"number": 1, "result": "FAILURE"}`,
+	})
+
+	c := newTestCollector(t, srv, config.Collector{FetchBuildDetails: true, LastSuccessCommit: true})
+
+	// broken 从未成功构建，不导出
+	expected := `
+# HELP jenkins_job_last_success_commit_info Constant 1 for jobs with a successful build, labels contain commit and branch of the last successful build
+# TYPE jenkins_job_last_success_commit_info gauge
+jenkins_job_last_success_commit_info{branch="main",commit="aaa111",job_name="app"} 1
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_job_last_success_commit_info"))
+}
+
+func TestJobCollectorBuildsRetained(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":         `{"jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/app/"}]}`,
+		"/job/app/api/json": `{"_class": "hudson.model.FreeStyleProject", "fullName": "app", "url": "$URL/job/app/", "color": "blue", "nextBuildNumber": 40, "builds": [{"number": 39}, {"number": 38}], "allBuilds": [{"number": 39}, {"number": 38}, {"number": 12}]}`,
+	})
+
+	c := newTestCollector(t, srv, config.Collector{BuildsRetained: true})
+
+	// 构建 12 之前的记录已被轮转删除
+	expected := `
+# HELP jenkins_job_builds_retained Number of builds retained in the build history of the job
+# TYPE jenkins_job_builds_retained gauge
+jenkins_job_builds_retained{job_name="app"} 3
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_job_builds_retained"))
+}
+
+func TestJobCollectorNondefaultParams(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":           `{"jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/app/"}, {"_class": "hudson.model.FreeStyleProject", "name": "plain", "url": "$URL/job/plain/"}]}`,
+		"/job/app/api/json":   `{"_class": "hudson.model.FreeStyleProject", "fullName": "app", "url": "$URL/job/app/", "color": "blue", "lastBuild": {"number": 7, "url": "$URL/job/app/7/"}, "property": [{"_class": "hudson.model.ParametersDefinitionProperty", "parameterDefinitions": [{"name": "ENV", "defaultParameterValue": {"value": "staging"}}, {"name": "DRY_RUN", "defaultParameterValue": {"value": true}}]}]}`,
+		"/job/app/7/api/json": `{"result": "SUCCESS", "actions": [{"_class": "hudson.model.ParametersAction", "parameters": [{"name": "ENV", "value": "production"}, {"name": "DRY_RUN", "value": true}]}]}`,
+		"/job/plain/api/json": `{"_class": "hudson.model.FreeStyleProject", "fullName": "plain", "url": "$URL/job/plain/", "color": "blue", "lastBuild": {"number": 3, "url": "$URL/job/plain/3/"}}`,
+	})
+
+	c := newTestCollector(t, srv, config.Collector{NondefaultParams: true})
+
+	// plain 没有参数定义，不导出
+	expected := `
+# HELP jenkins_job_last_build_nondefault_params Number of parameters of the last build which differ from the defaults of the job
+# TYPE jenkins_job_last_build_nondefault_params gauge
+jenkins_job_last_build_nondefault_params{job_name="app"} 1
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_job_last_build_nondefault_params"))
+}
+
+func TestJobCollectorUpstream(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":              `{"jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "deploy", "url": "$URL/job/deploy/"}, {"_class": "hudson.model.FreeStyleProject", "name": "manual", "url": "$URL/job/manual/"}]}`,
+		"/job/deploy/api/json":   `{"_class": "hudson.model.FreeStyleProject", "fullName": "deploy", "url": "$URL/job/deploy/", "color": "blue", "lastBuild": {"number": 4, "url": "$URL/job/deploy/4/"}}`,
+		"/job/deploy/4/api/json": `{"result": "SUCCESS", "actions": [{"_class": "hudson.model.CauseAction", "causes": [{"_class": "hudson.model.Cause$UpstreamCause", "shortDescription": "Started by upstream project \"team/build\" build number 12", "upstreamProject": "team/build", "upstreamBuild": 12}]}]}`,
+		"/job/manual/api/json":   `{"_class": "hudson.model.FreeStyleProject", "fullName": "manual", "url": "$URL/job/manual/", "color": "blue", "lastBuild": {"number": 2, "url": "$URL/job/manual/2/"}}`,
+		"/job/manual/2/api/json": `{"result": "SUCCESS", "actions": [{"_class": "hudson.model.CauseAction", "causes": [{"_class": "hudson.model.Cause$UserIdCause", "shortDescription": "Started by user admin"}]}]}`,
+	})
+
+	c := newTestCollector(t, srv, config.Collector{Upstream: true})
+
+	// manual 由用户触发，不导出
+	expected := `
+# HELP jenkins_job_last_build_upstream_info Constant 1 for jobs whose last build got triggered by an upstream job, the upstream label contains its name
+# TYPE jenkins_job_last_build_upstream_info gauge
+jenkins_job_last_build_upstream_info{job_name="deploy",upstream="team/build"} 1
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_job_last_build_upstream_info"))
+}
+
+func TestJobCollectorSCMPoll(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":                      `{"jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/app/"}, {"_class": "hudson.model.FreeStyleProject", "name": "manual", "url": "$URL/job/manual/"}]}`,
+		"/job/app/api/json":              `{"_class": "hudson.model.FreeStyleProject", "fullName": "app", "url": "$URL/job/app/", "color": "blue"}`,
+		"/job/app/scmPollLog/pollingLog": "Started on May 1, 2024, 12:00:00 PM\nUsing strategy: Default\n[poll] Last Built Revision: Revision 4f2a1c (refs/remotes/origin/main)\nDone. Took 0.45 sec\nChanges found\n",
+		"/job/manual/api/json":           `{"_class": "hudson.model.FreeStyleProject", "fullName": "manual", "url": "$URL/job/manual/", "color": "blue"}`,
+	})
+
+	c := newTestCollector(t, srv, config.Collector{SCMPoll: true})
+	started := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local).Unix()
+
+	// manual 未配置 SCM 轮询，不导出
+	expected := `
+# HELP jenkins_job_last_scm_poll_timestamp Start time of the last SCM poll of the job as unix timestamp
+# TYPE jenkins_job_last_scm_poll_timestamp gauge
+jenkins_job_last_scm_poll_timestamp{job_name="app"} ` + strconv.FormatInt(started, 10) + `
+# HELP jenkins_job_scm_poll_has_changes 1 if the last SCM poll of the job found changes, 0 otherwise
+# TYPE jenkins_job_scm_poll_has_changes gauge
+jenkins_job_scm_poll_has_changes{job_name="app"} 1
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_job_last_scm_poll_timestamp", "jenkins_job_scm_poll_has_changes"))
+}
+
+func TestJobCollectorCacheRefreshSingleFlight(t *testing.T) {
+	var requests atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			close(started)
+		}
+
+		<-release
+		_, _ = io.WriteString(w, `{"jobs": []}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := newTestCollector(t, srv, config.Collector{CacheFile: filepath.Join(t.TempDir(), "jobs.json"), CacheRefreshInterval: time.Minute})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.updateCacheInBackground()
+	}()
+
+	<-started
+
+	// 第一次刷新仍在进行，第二次刷新直接跳过
+	c.updateCacheInBackground()
+	close(release)
+	<-done
+
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestJobCollectorCacheRefreshJitter(t *testing.T) {
+	c := newTestCollector(t, jenkinstest.NewServer(t, nil), config.Collector{CacheRefreshInterval: 10 * time.Minute, CacheRefreshJitter: 0.2})
+	c.random = func() float64 { return 0.5 }
+
+	assert.Equal(t, 11*time.Minute, c.nextCacheRefresh())
+
+	c.cacheRefreshJitter = 0
+	assert.Equal(t, 10*time.Minute, c.nextCacheRefresh())
+}
+
+func TestJobCollectorConcurrency(t *testing.T) {
+	var inflight, peak atomic.Int32
+
+	routes := map[string]string{}
+	jobs := make([]string, 0, 20)
+
+	for i := range 20 {
+		name := fmt.Sprintf("app%d", i)
+		jobs = append(jobs, fmt.Sprintf(`{"_class": "hudson.model.FreeStyleProject", "name": "%s", "url": "$URL/job/%s/"}`, name, name))
+		routes["/job/"+name+"/api/json"] = fmt.Sprintf(`{"_class": "hudson.model.FreeStyleProject", "fullName": "%s", "url": "$URL/job/%s/", "color": "blue", "lastBuild": {"number": 1, "url": "$URL/job/%s/1/"}}`, name, name, name)
+	}
+
+	routes["/api/json"] = `{"jobs": [` + strings.Join(jobs, ", ") + `]}`
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/1/api/json") {
+			current := inflight.Add(1)
+			defer inflight.Add(-1)
+
+			for {
+				previous := peak.Load()
+				if current <= previous || peak.CompareAndSwap(previous, current) {
+					break
+				}
+			}
+
+			time.Sleep(20 * time.Millisecond)
+			_, _ = io.WriteString(w, `{"result": "SUCCESS"}`)
+			return
+		}
+
+		_, _ = io.WriteString(w, strings.ReplaceAll(routes[r.URL.Path], "$URL", srv.URL))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := newTestCollector(t, srv, config.Collector{FetchBuildDetails: true, CollectorConcurrency: 3})
+
+	assert.Equal(t, 20, testutil.CollectAndCount(c, "jenkins_job_build_status"))
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+	assert.Greater(t, peak.Load(), int32(0))
+}
+
+func TestJobWorkers(t *testing.T) {
+	assert.Equal(t, 10, jobWorkers(0))
+	assert.Equal(t, 3, jobWorkers(3))
+	assert.Equal(t, 100, jobWorkers(500))
+}
+
+func TestJobCollectorLastSuccessfulBuild(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":           `{"jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/app/"}, {"_class": "hudson.model.FreeStyleProject", "name": "lib", "url": "$URL/job/lib/"}]}`,
+		"/job/app/api/json":   `{"_class": "hudson.model.FreeStyleProject", "fullName": "app", "url": "$URL/job/app/", "color": "red", "lastBuild": {"number": 9, "url": "$URL/job/app/9/"}, "lastSuccessfulBuild": {"number": 7, "url": "$URL/job/app/7/"}, "lastUnsuccessfulBuild": {"number": 9, "url": "$URL/job/app/9/"}}`,
+		"/job/app/9/api/json": `{"result": "FAILURE"}`,
+		"/job/lib/api/json":   `{"_class": "hudson.model.FreeStyleProject", "fullName": "lib", "url": "$URL/job/lib/", "color": "blue", "lastBuild": {"number": 3, "url": "$URL/job/lib/3/"}, "lastSuccessfulBuild": {"number": 3, "url": "$URL/job/lib/3/"}}`,
+		"/job/lib/3/api/json": `{"result": "SUCCESS"}`,
+	})
+
+	// lib 从未失败，不导出 last_unsuccessful_build
+	expected := `
+# HELP jenkins_job_last_successful_build Number of the last successful build of the job
+# TYPE jenkins_job_last_successful_build gauge
+jenkins_job_last_successful_build{job_name="app"} 7
+jenkins_job_last_successful_build{job_name="lib"} 3
+# HELP jenkins_job_last_unsuccessful_build Number of the last unsuccessful build of the job
+# TYPE jenkins_job_last_unsuccessful_build gauge
+jenkins_job_last_unsuccessful_build{job_name="app"} 9
+`
+
+	// 并行获取构建详情和串行处理两个分支都要导出
+	for _, fetchBuildDetails := range []bool{true, false} {
+		c := newTestCollector(t, srv, config.Collector{FetchBuildDetails: fetchBuildDetails})
+		assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_job_last_successful_build", "jenkins_job_last_unsuccessful_build"))
+	}
+}
+
+func TestJobCollectorDuplicatePath(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json": `{"jobs": [
+			{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "a", "url": "$URL/job/a/"},
+			{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "b", "url": "$URL/job/b/"}
+		]}`,
+		"/job/a/api/json":         `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/a/job/app/"}]}`,
+		"/job/b/api/json":         `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/b/job/app/"}]}`,
+		"/job/a/job/app/api/json": `{"_class": "hudson.model.FreeStyleProject", "fullName": "app", "url": "$URL/job/a/job/app/", "color": "blue", "lastSuccessfulBuild": {"number": 1, "url": "$URL/job/a/job/app/1/"}}`,
+		"/job/b/job/app/api/json": `{"_class": "hudson.model.FreeStyleProject", "fullName": "app", "url": "$URL/job/b/job/app/", "color": "red", "lastSuccessfulBuild": {"number": 2, "url": "$URL/job/b/job/app/2/"}}`,
+	})
+
+	// 两个作业的 fullName 相同，只导出一次，抓取不会失败
+	for _, fetchBuildDetails := range []bool{true, false} {
+		c := newTestCollector(t, srv, config.Collector{FetchBuildDetails: fetchBuildDetails})
+
+		reg := prometheus.NewPedanticRegistry()
+		assert.NoError(t, reg.Register(c))
+
+		count, err := testutil.GatherAndCount(reg, "jenkins_job_last_successful_build")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+	}
+}
+
+func TestJobCollectorResultLabel(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json": `{"jobs": [
+			{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/app/"},
+			{"_class": "hudson.model.FreeStyleProject", "name": "fresh", "url": "$URL/job/fresh/"}
+		]}`,
+		"/job/app/api/json":   `{"_class": "hudson.model.FreeStyleProject", "fullName": "app", "url": "$URL/job/app/", "color": "yellow", "lastBuild": {"number": 2, "url": "$URL/job/app/2/"}}`,
+		"/job/app/2/api/json": `{"result": "UNSTABLE", "duration": 1000}`,
+		"/job/fresh/api/json": `{"_class": "hudson.model.FreeStyleProject", "fullName": "fresh", "url": "$URL/job/fresh/", "color": "notbuilt"}`,
+	})
+
+	expected := `
+# HELP jenkins_build_last_result ` + jenkins.BuildLastResultHelp + `
+# TYPE jenkins_build_last_result gauge
+jenkins_build_last_result{check_commitID="",gitBranch="",job_name="app",result="UNSTABLE",status="unstable"} 1
+jenkins_build_last_result{check_commitID="",gitBranch="",job_name="fresh",result="",status="not_built"} 1
+`
+
+	c := newTestCollector(t, srv, config.Collector{FetchBuildDetails: true, ResultLabel: true})
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_build_last_result"))
+}
+
+func TestJobCollectorPriorityJobs(t *testing.T) {
+	routes := map[string]string{
+		"/api/json": `{"jobs": [
+			{"_class": "hudson.model.FreeStyleProject", "name": "a", "url": "$URL/job/a/"},
+			{"_class": "hudson.model.FreeStyleProject", "name": "b", "url": "$URL/job/b/"},
+			{"_class": "hudson.model.FreeStyleProject", "name": "prod-api", "url": "$URL/job/prod-api/"}
+		]}`,
+	}
+
+	for _, name := range []string{"a", "b", "prod-api"} {
+		routes["/job/"+name+"/api/json"] = `{"_class": "hudson.model.FreeStyleProject", "fullName": "` + name + `", "url": "$URL/job/` + name + `/", "lastBuild": {"number": 1, "url": "$URL/job/` + name + `/1/"}}`
+		routes["/job/"+name+"/1/api/json"] = `{"result": "SUCCESS"}`
+	}
+
+	var mu sync.Mutex
+	builds := make([]string, 0)
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/1/api/json") {
+			mu.Lock()
+			builds = append(builds, r.URL.Path)
+			mu.Unlock()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, strings.ReplaceAll(routes[r.URL.Path], "$URL", srv.URL))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := newTestCollector(t, srv, config.Collector{
+		FetchBuildDetails:    true,
+		CollectorConcurrency: 1,
+		PriorityJobs:         []string{"prod-"},
+	})
+	testutil.CollectAndCount(c, "jenkins_build_last_result")
+
+	// 只有一个 worker 时，优先作业的构建详情最先被请求
+	if assert.Len(t, builds, 3) {
+		assert.Equal(t, "/job/prod-api/1/api/json", builds[0])
+	}
+}
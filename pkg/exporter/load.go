@@ -0,0 +1,96 @@
+package exporter
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/promhippie/jenkins_exporter/pkg/config"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
+)
+
+// LoadCollector collects metrics about the overall load of the instance.
+type LoadCollector struct {
+	client   *jenkins.Client
+	logger   *slog.Logger
+	failures *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	config   config.Target
+
+	QueueLength   *prometheus.Desc
+	BusyExecutors *prometheus.Desc
+}
+
+// NewLoadCollector returns a new LoadCollector.
+func NewLoadCollector(logger *slog.Logger, client *jenkins.Client, failures *prometheus.CounterVec, duration *prometheus.HistogramVec, cfg config.Target) *LoadCollector {
+	if failures != nil {
+		failures.WithLabelValues("load").Add(0)
+	}
+
+	return &LoadCollector{
+		client:   client,
+		logger:   logger.With("collector", "load"),
+		failures: failures,
+		duration: duration,
+		config:   cfg,
+
+		QueueLength: prometheus.NewDesc(
+			"jenkins_overall_queue_length",
+			"Moving average of the queue length over the short time scale",
+			nil,
+			nil,
+		),
+		BusyExecutors: prometheus.NewDesc(
+			"jenkins_overall_busy_executors",
+			"Moving average of the busy executors over the short time scale",
+			nil,
+			nil,
+		),
+	}
+}
+
+// Metrics simply returns the list metric descriptors for generating a documentation.
+func (c *LoadCollector) Metrics() []*prometheus.Desc {
+	return []*prometheus.Desc{
+		c.QueueLength,
+		c.BusyExecutors,
+	}
+}
+
+// Describe sends the super-set of all possible descriptors of metrics collected by this Collector.
+func (c *LoadCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.QueueLength
+	ch <- c.BusyExecutors
+}
+
+// Collect is called by the Prometheus registry when collecting metrics.
+func (c *LoadCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+
+	now := time.Now()
+	load, err := c.client.Load.Overall(ctx)
+	c.duration.WithLabelValues("load").Observe(time.Since(now).Seconds())
+
+	if err != nil {
+		c.logger.Error("获取整体负载失败",
+			"错误", err,
+		)
+
+		c.failures.WithLabelValues("load").Inc()
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.QueueLength,
+		prometheus.GaugeValue,
+		load.QueueLength.Sec10.Latest,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.BusyExecutors,
+		prometheus.GaugeValue,
+		load.BusyExecutors.Sec10.Latest,
+	)
+}
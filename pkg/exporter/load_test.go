@@ -0,0 +1,51 @@
+package exporter
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/promhippie/jenkins_exporter/pkg/config"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkinstest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadCollector(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/overallLoad/api/json": `{
+			"_class": "hudson.model.OverallLoadStatistics",
+			"busyExecutors": {"sec10": {"latest": 3.25}},
+			"queueLength": {"sec10": {"latest": 1.5}}
+		}`,
+	})
+
+	client, err := jenkins.NewClient(
+		jenkins.WithEndpoint(srv.URL),
+		jenkins.WithTimeout(5*time.Second),
+	)
+	assert.NoError(t, err)
+
+	c := NewLoadCollector(
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		client,
+		prometheus.NewCounterVec(prometheus.CounterOpts{Name: "failures"}, []string{"collector"}),
+		prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "duration"}, []string{"collector"}),
+		config.Target{Timeout: 5 * time.Second},
+	)
+
+	expected := `
+# HELP jenkins_overall_busy_executors Moving average of the busy executors over the short time scale
+# TYPE jenkins_overall_busy_executors gauge
+jenkins_overall_busy_executors 3.25
+# HELP jenkins_overall_queue_length Moving average of the queue length over the short time scale
+# TYPE jenkins_overall_queue_length gauge
+jenkins_overall_queue_length 1.5
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected)))
+}
@@ -0,0 +1,36 @@
+package exporter
+
+import (
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
+)
+
+// skipOldBuilds removes the references to builds with a number lower than the
+// minimum build number, so these jobs are handled like they have never been
+// built. The given jobs are not modified.
+func (c *JobCollector) skipOldBuilds(jobs []jenkins.Job) []jenkins.Job {
+	if c.minBuild <= 0 {
+		return jobs
+	}
+
+	result := make([]jenkins.Job, 0, len(jobs))
+
+	for _, job := range jobs {
+		for _, build := range []**jenkins.BuildNumber{
+			&job.LastBuild,
+			&job.LastCompletedBuild,
+			&job.LastFailedBuild,
+			&job.LastStableBuild,
+			&job.LastSuccessfulBuild,
+			&job.LastUnstableBuild,
+			&job.LastUnsuccessfulBuild,
+		} {
+			if *build != nil && (*build).Number < c.minBuild {
+				*build = nil
+			}
+		}
+
+		result = append(result, job)
+	}
+
+	return result
+}
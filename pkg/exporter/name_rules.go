@@ -0,0 +1,49 @@
+package exporter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// NameRule replaces all matches of a regexp within the job_name label.
+type NameRule struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// ParseNameRules parses a list of regexp=replacement rules, they get applied
+// to the job_name label in the given order.
+func ParseNameRules(values []string) ([]NameRule, error) {
+	rules := make([]NameRule, 0, len(values))
+
+	for _, value := range values {
+		pattern, replacement, found := strings.Cut(value, "=")
+
+		if !found {
+			return nil, fmt.Errorf("invalid name rule %q, expected regexp=replacement", value)
+		}
+
+		re, err := regexp.Compile(pattern)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid name rule %q: %w", value, err)
+		}
+
+		rules = append(rules, NameRule{
+			Pattern:     re,
+			Replacement: replacement,
+		})
+	}
+
+	return rules, nil
+}
+
+// applyNameRules applies the rules in order to the given name.
+func applyNameRules(name string, rules []NameRule) string {
+	for _, rule := range rules {
+		name = rule.Pattern.ReplaceAllString(name, rule.Replacement)
+	}
+
+	return name
+}
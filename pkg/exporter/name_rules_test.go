@@ -0,0 +1,19 @@
+package exporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNameRules(t *testing.T) {
+	rules, err := ParseNameRules([]string{`-pr-\d+$=`, `^(\w+)/(\w+)$=$2.$1`})
+	assert.NoError(t, err)
+	assert.Equal(t, "app.team", applyNameRules("team/app-pr-7", rules))
+
+	_, err = ParseNameRules([]string{`-pr-(\d+=`})
+	assert.Error(t, err)
+
+	_, err = ParseNameRules([]string{`-pr-\d+`})
+	assert.Error(t, err)
+}
@@ -0,0 +1,247 @@
+package exporter
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/promhippie/jenkins_exporter/pkg/config"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
+)
+
+// NodeCollector collects metrics about the agents.
+type NodeCollector struct {
+	client   *jenkins.Client
+	logger   *slog.Logger
+	failures *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	config   config.Target
+
+	Online        *prometheus.Desc
+	Idle          *prometheus.Desc
+	ExecutorsMax  *prometheus.Desc
+	ExecutorsBusy *prometheus.Desc
+	OfflineInfo   *prometheus.Desc
+	LabelsInfo    *prometheus.Desc
+	ResponseTime  *prometheus.Desc
+	DiskSpace     *prometheus.Desc
+	TempSpace     *prometheus.Desc
+}
+
+// NewNodeCollector returns a new NodeCollector.
+func NewNodeCollector(logger *slog.Logger, client *jenkins.Client, failures *prometheus.CounterVec, duration *prometheus.HistogramVec, cfg config.Target) *NodeCollector {
+	if failures != nil {
+		failures.WithLabelValues("node").Add(0)
+	}
+
+	return &NodeCollector{
+		client:   client,
+		logger:   logger.With("collector", "node"),
+		failures: failures,
+		duration: duration,
+		config:   cfg,
+
+		Online: prometheus.NewDesc(
+			"jenkins_node_online",
+			"1 if the node is online, 0 otherwise",
+			[]string{"node_name"},
+			nil,
+		),
+		Idle: prometheus.NewDesc(
+			"jenkins_node_idle",
+			"1 if none of the executors of the node is busy, 0 otherwise",
+			[]string{"node_name"},
+			nil,
+		),
+		ExecutorsMax: prometheus.NewDesc(
+			"jenkins_node_executors_total",
+			"Number of executors configured for the node",
+			[]string{"node_name"},
+			nil,
+		),
+		ExecutorsBusy: prometheus.NewDesc(
+			"jenkins_node_executors_busy",
+			"Number of executors of the node which are running a build",
+			[]string{"node_name"},
+			nil,
+		),
+		OfflineInfo: prometheus.NewDesc(
+			"jenkins_node_offline_info",
+			"Constant 1 for offline nodes, reason label contains why the node is offline",
+			[]string{"node_name", "reason"},
+			nil,
+		),
+		LabelsInfo: prometheus.NewDesc(
+			"jenkins_node_labels_info",
+			"Constant 1 for every label assigned to a node",
+			[]string{"node_name", "label"},
+			nil,
+		),
+		ResponseTime: prometheus.NewDesc(
+			"jenkins_node_response_time_ms",
+			"Average response time of the node in milliseconds reported by the response time monitor",
+			[]string{"node_name"},
+			nil,
+		),
+		DiskSpace: prometheus.NewDesc(
+			"jenkins_node_disk_space_bytes",
+			"Free disk space of the workspace directory of the node in bytes",
+			[]string{"node_name"},
+			nil,
+		),
+		TempSpace: prometheus.NewDesc(
+			"jenkins_node_temp_space_bytes",
+			"Free disk space of the temporary directory of the node in bytes",
+			[]string{"node_name"},
+			nil,
+		),
+	}
+}
+
+// Metrics simply returns the list metric descriptors for generating a documentation.
+func (c *NodeCollector) Metrics() []*prometheus.Desc {
+	return []*prometheus.Desc{
+		c.Online,
+		c.Idle,
+		c.ExecutorsMax,
+		c.ExecutorsBusy,
+		c.OfflineInfo,
+		c.LabelsInfo,
+		c.ResponseTime,
+		c.DiskSpace,
+		c.TempSpace,
+	}
+}
+
+// Describe sends the super-set of all possible descriptors of metrics collected by this Collector.
+func (c *NodeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.Online
+	ch <- c.Idle
+	ch <- c.ExecutorsMax
+	ch <- c.ExecutorsBusy
+	ch <- c.OfflineInfo
+	ch <- c.LabelsInfo
+	ch <- c.ResponseTime
+	ch <- c.DiskSpace
+	ch <- c.TempSpace
+}
+
+// Collect is called by the Prometheus registry when collecting metrics.
+func (c *NodeCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+
+	now := time.Now()
+	computers, err := c.client.Computer.All(ctx)
+	c.duration.WithLabelValues("node").Observe(time.Since(now).Seconds())
+
+	if err != nil {
+		c.logger.Error("获取节点列表失败",
+			"错误", err,
+		)
+
+		c.failures.WithLabelValues("node").Inc()
+		return
+	}
+
+	for _, computer := range computers.Computers {
+		var online, idle, busy float64
+
+		// 离线节点同样导出，便于针对节点掉线告警
+		if !computer.Offline {
+			online = 1.0
+		}
+
+		if computer.Idle {
+			idle = 1.0
+		}
+
+		for _, executor := range computer.Executors {
+			if !executor.Idle {
+				busy++
+			}
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.Online,
+			prometheus.GaugeValue,
+			online,
+			computer.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.Idle,
+			prometheus.GaugeValue,
+			idle,
+			computer.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.ExecutorsMax,
+			prometheus.GaugeValue,
+			float64(computer.NumExecutors),
+			computer.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.ExecutorsBusy,
+			prometheus.GaugeValue,
+			busy,
+			computer.Name,
+		)
+
+		if computer.Offline {
+			ch <- prometheus.MustNewConstMetric(
+				c.OfflineInfo,
+				prometheus.GaugeValue,
+				1.0,
+				computer.Name,
+				computer.OfflineCauseReason,
+			)
+		}
+
+		// 没有监控数据的节点不导出响应时间
+		if data := computer.MonitorData.ResponseTime; data != nil {
+			ch <- prometheus.MustNewConstMetric(
+				c.ResponseTime,
+				prometheus.GaugeValue,
+				float64(data.Average),
+				computer.Name,
+			)
+		}
+
+		if data := computer.MonitorData.DiskSpace; data != nil {
+			ch <- prometheus.MustNewConstMetric(
+				c.DiskSpace,
+				prometheus.GaugeValue,
+				float64(data.Size),
+				computer.Name,
+			)
+		}
+
+		if data := computer.MonitorData.TemporarySpace; data != nil {
+			ch <- prometheus.MustNewConstMetric(
+				c.TempSpace,
+				prometheus.GaugeValue,
+				float64(data.Size),
+				computer.Name,
+			)
+		}
+
+		for _, label := range computer.AssignedLabels {
+			// 每个节点都带有与名称相同的自身标签，不导出
+			if label.Name == "" || label.Name == computer.Name {
+				continue
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				c.LabelsInfo,
+				prometheus.GaugeValue,
+				1.0,
+				computer.Name,
+				label.Name,
+			)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package exporter
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/promhippie/jenkins_exporter/pkg/config"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkinstest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestNodeCollector(t *testing.T, routes map[string]string) *NodeCollector {
+	t.Helper()
+
+	srv := jenkinstest.NewServer(t, routes)
+
+	// 监控数据的字段需要显式请求，否则 Jenkins 只返回 _class
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/computer/api/json" {
+			assert.Contains(t, r.URL.Query().Get("tree"), "monitorData[*[size,path,average]]")
+		}
+
+		srv.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(proxy.Close)
+
+	client, err := jenkins.NewClient(
+		jenkins.WithEndpoint(proxy.URL),
+		jenkins.WithTimeout(5*time.Second),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewNodeCollector(
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		client,
+		prometheus.NewCounterVec(prometheus.CounterOpts{Name: "failures"}, []string{"collector"}),
+		prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "duration"}, []string{"collector"}),
+		config.Target{Timeout: 5 * time.Second},
+	)
+}
+
+func TestNodeCollectorOfflineInfo(t *testing.T) {
+	c := newTestNodeCollector(t, map[string]string{
+		"/computer/api/json": `{"computer": [
+			{"displayName": "built-in", "offline": false},
+			{"displayName": "agent-1", "offline": true, "offlineCauseReason": "Disk full, marked offline by admin"}
+		]}`,
+	})
+
+	expected := `
+# HELP jenkins_node_offline_info Constant 1 for offline nodes, reason label contains why the node is offline
+# TYPE jenkins_node_offline_info gauge
+jenkins_node_offline_info{node_name="agent-1",reason="Disk full, marked offline by admin"} 1
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_node_offline_info"))
+}
+
+func TestNodeCollectorLabelsInfo(t *testing.T) {
+	c := newTestNodeCollector(t, map[string]string{
+		"/computer/api/json": `{"computer": [
+			{"displayName": "agent-1", "offline": false, "assignedLabels": [{"name": "docker"}, {"name": "linux"}, {"name": "agent-1"}]}
+		]}`,
+	})
+
+	expected := `
+# HELP jenkins_node_labels_info Constant 1 for every label assigned to a node
+# TYPE jenkins_node_labels_info gauge
+jenkins_node_labels_info{label="docker",node_name="agent-1"} 1
+jenkins_node_labels_info{label="linux",node_name="agent-1"} 1
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_node_labels_info"))
+}
+
+func TestNodeCollectorExecutors(t *testing.T) {
+	c := newTestNodeCollector(t, map[string]string{
+		"/computer/api/json": `{"computer": [
+			{"displayName": "built-in", "offline": false, "idle": true, "numExecutors": 2, "executors": [{"idle": true}, {"idle": true}]},
+			{"displayName": "agent-1", "offline": false, "idle": false, "numExecutors": 4, "executors": [{"idle": false}, {"idle": true}, {"idle": false}, {"idle": true}]},
+			{"displayName": "agent-2", "offline": true, "idle": true, "numExecutors": 1, "executors": []}
+		]}`,
+	})
+
+	// agent-2 离线，仍然以 online=0 导出
+	expected := `
+# HELP jenkins_node_executors_busy Number of executors of the node which are running a build
+# TYPE jenkins_node_executors_busy gauge
+jenkins_node_executors_busy{node_name="agent-1"} 2
+jenkins_node_executors_busy{node_name="agent-2"} 0
+jenkins_node_executors_busy{node_name="built-in"} 0
+# HELP jenkins_node_executors_total Number of executors configured for the node
+# TYPE jenkins_node_executors_total gauge
+jenkins_node_executors_total{node_name="agent-1"} 4
+jenkins_node_executors_total{node_name="agent-2"} 1
+jenkins_node_executors_total{node_name="built-in"} 2
+# HELP jenkins_node_idle 1 if none of the executors of the node is busy, 0 otherwise
+# TYPE jenkins_node_idle gauge
+jenkins_node_idle{node_name="agent-1"} 0
+jenkins_node_idle{node_name="agent-2"} 1
+jenkins_node_idle{node_name="built-in"} 1
+# HELP jenkins_node_online 1 if the node is online, 0 otherwise
+# TYPE jenkins_node_online gauge
+jenkins_node_online{node_name="agent-1"} 1
+jenkins_node_online{node_name="agent-2"} 0
+jenkins_node_online{node_name="built-in"} 1
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_node_online", "jenkins_node_idle", "jenkins_node_executors_total", "jenkins_node_executors_busy"))
+}
+
+func TestNodeCollectorResponseTime(t *testing.T) {
+	c := newTestNodeCollector(t, map[string]string{
+		"/computer/api/json": `{"computer": [
+			{"displayName": "built-in", "offline": false, "monitorData": {
+				"hudson.node_monitors.ResponseTimeMonitor": {"_class": "hudson.node_monitors.ResponseTimeMonitor$Data", "timestamp": 1700000000000, "average": 0}
+			}},
+			{"displayName": "agent-1", "offline": false, "monitorData": {
+				"hudson.node_monitors.ResponseTimeMonitor": {"_class": "hudson.node_monitors.ResponseTimeMonitor$Data", "timestamp": 1700000000000, "average": 187}
+			}},
+			{"displayName": "agent-2", "offline": true, "monitorData": {
+				"hudson.node_monitors.ResponseTimeMonitor": null
+			}}
+		]}`,
+	})
+
+	// agent-2 没有监控数据，不导出响应时间
+	expected := `
+# HELP jenkins_node_response_time_ms Average response time of the node in milliseconds reported by the response time monitor
+# TYPE jenkins_node_response_time_ms gauge
+jenkins_node_response_time_ms{node_name="agent-1"} 187
+jenkins_node_response_time_ms{node_name="built-in"} 0
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_node_response_time_ms"))
+}
+
+func TestNodeCollectorDiskSpace(t *testing.T) {
+	c := newTestNodeCollector(t, map[string]string{
+		"/computer/api/json": `{"computer": [
+			{"displayName": "built-in", "offline": false, "monitorData": {
+				"hudson.node_monitors.DiskSpaceMonitor": {"_class": "hudson.node_monitors.DiskSpaceMonitorDescriptor$DiskSpace", "timestamp": 1700000000000, "path": "/var/jenkins_home", "size": 53687091200},
+				"hudson.node_monitors.TemporarySpaceMonitor": {"_class": "hudson.node_monitors.DiskSpaceMonitorDescriptor$DiskSpace", "timestamp": 1700000000000, "path": "/tmp", "size": 1073741824}
+			}},
+			{"displayName": "agent-1", "offline": false, "monitorData": {
+				"hudson.node_monitors.DiskSpaceMonitor": {"_class": "hudson.node_monitors.DiskSpaceMonitorDescriptor$DiskSpace", "timestamp": 1700000000000, "path": "/home/agent", "size": 524288000}
+			}},
+			{"displayName": "agent-2", "offline": true, "monitorData": {
+				"hudson.node_monitors.DiskSpaceMonitor": null,
+				"hudson.node_monitors.TemporarySpaceMonitor": null
+			}}
+		]}`,
+	})
+
+	// 缺少的监控数据不导出
+	expected := `
+# HELP jenkins_node_disk_space_bytes Free disk space of the workspace directory of the node in bytes
+# TYPE jenkins_node_disk_space_bytes gauge
+jenkins_node_disk_space_bytes{node_name="agent-1"} 5.24288e+08
+jenkins_node_disk_space_bytes{node_name="built-in"} 5.36870912e+10
+# HELP jenkins_node_temp_space_bytes Free disk space of the temporary directory of the node in bytes
+# TYPE jenkins_node_temp_space_bytes gauge
+jenkins_node_temp_space_bytes{node_name="built-in"} 1.073741824e+09
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_node_disk_space_bytes", "jenkins_node_temp_space_bytes"))
+}
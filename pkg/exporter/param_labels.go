@@ -0,0 +1,19 @@
+package exporter
+
+import (
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
+)
+
+// paramLabelValues returns the values of the configured parameter labels for
+// the build, without build details all values are empty.
+func (c *JobCollector) paramLabelValues(build *jenkins.Build) []string {
+	params := make(map[string]string, len(c.paramLabels))
+
+	if build != nil {
+		for _, name := range c.paramLabels {
+			params[name] = extractParameter(*build, name)
+		}
+	}
+
+	return jenkins.ParamLabelValues(c.paramLabels, params)
+}
@@ -0,0 +1,207 @@
+package exporter
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/promhippie/jenkins_exporter/pkg/config"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
+)
+
+// QueueCollector collects metrics about the build queue.
+type QueueCollector struct {
+	client   *jenkins.Client
+	logger   *slog.Logger
+	failures *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	config   config.Target
+	clock    jenkins.Clock // 时间来源，测试时可替换
+
+	Size     *prometheus.Desc
+	Stuck    *prometheus.Desc
+	Blocked  *prometheus.Desc
+	ItemInfo *prometheus.Desc
+	ItemWait *prometheus.Desc
+}
+
+// NewQueueCollector returns a new QueueCollector.
+func NewQueueCollector(logger *slog.Logger, client *jenkins.Client, failures *prometheus.CounterVec, duration *prometheus.HistogramVec, cfg config.Target) *QueueCollector {
+	if failures != nil {
+		failures.WithLabelValues("queue").Add(0)
+	}
+
+	return &QueueCollector{
+		client:   client,
+		logger:   logger.With("collector", "queue"),
+		failures: failures,
+		duration: duration,
+		config:   cfg,
+		clock:    jenkins.RealClock{},
+
+		Size: prometheus.NewDesc(
+			"jenkins_queue_size",
+			"Number of items in the build queue",
+			nil,
+			nil,
+		),
+		Stuck: prometheus.NewDesc(
+			"jenkins_queue_item_stuck",
+			"Number of items in the build queue which are stuck",
+			nil,
+			nil,
+		),
+		Blocked: prometheus.NewDesc(
+			"jenkins_queue_item_blocked",
+			"Number of items in the build queue which are blocked",
+			nil,
+			nil,
+		),
+		ItemInfo: prometheus.NewDesc(
+			"jenkins_queue_item_info",
+			"Constant 1 for queued jobs, reason label contains why the item is waiting",
+			[]string{"job_name", "reason"},
+			nil,
+		),
+		ItemWait: prometheus.NewDesc(
+			"jenkins_queue_item_wait_seconds",
+			"Seconds the longest waiting queue item of the job is in the queue",
+			[]string{"job_name"},
+			nil,
+		),
+	}
+}
+
+// Metrics simply returns the list metric descriptors for generating a documentation.
+func (c *QueueCollector) Metrics() []*prometheus.Desc {
+	return []*prometheus.Desc{
+		c.Size,
+		c.Stuck,
+		c.Blocked,
+		c.ItemInfo,
+		c.ItemWait,
+	}
+}
+
+// Describe sends the super-set of all possible descriptors of metrics collected by this Collector.
+func (c *QueueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.Size
+	ch <- c.Stuck
+	ch <- c.Blocked
+	ch <- c.ItemInfo
+	ch <- c.ItemWait
+}
+
+// Collect is called by the Prometheus registry when collecting metrics.
+func (c *QueueCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+
+	now := time.Now()
+	queue, err := c.client.Queue.All(ctx)
+	c.duration.WithLabelValues("queue").Observe(time.Since(now).Seconds())
+
+	if err != nil {
+		c.logger.Error("获取构建队列失败",
+			"错误", err,
+		)
+
+		c.failures.WithLabelValues("queue").Inc()
+		return
+	}
+
+	var stuck, blocked float64
+
+	for _, item := range queue.Items {
+		if item.Stuck {
+			stuck++
+		}
+
+		if item.Blocked {
+			blocked++
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.Size,
+		prometheus.GaugeValue,
+		float64(len(queue.Items)),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.Stuck,
+		prometheus.GaugeValue,
+		stuck,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.Blocked,
+		prometheus.GaugeValue,
+		blocked,
+	)
+
+	// 同一作业可能多次排队，相同原因只输出一次
+	seen := make(map[[2]string]struct{}, len(queue.Items))
+	waits := make(map[string]float64, len(queue.Items))
+	collectedAt := c.clock.Now()
+
+	for _, item := range queue.Items {
+		key := [2]string{item.JobName(), queueReason(item)}
+
+		// 同一作业多次排队时只导出等待最久的条目
+		if item.InQueueSince > 0 {
+			wait := collectedAt.Sub(time.UnixMilli(item.InQueueSince)).Seconds()
+
+			if wait < 0 {
+				wait = 0
+			}
+
+			if current, ok := waits[key[0]]; !ok || wait > current {
+				waits[key[0]] = wait
+			}
+		}
+
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.ItemInfo,
+			prometheus.GaugeValue,
+			1.0,
+			key[0],
+			key[1],
+		)
+	}
+
+	for jobName, wait := range waits {
+		ch <- prometheus.MustNewConstMetric(
+			c.ItemWait,
+			prometheus.GaugeValue,
+			wait,
+			jobName,
+		)
+	}
+}
+
+// queueReason maps the free text why of a queue item to a bounded category.
+func queueReason(item jenkins.QueueItem) string {
+	why := strings.ToLower(item.Why)
+
+	switch {
+	case strings.Contains(why, "quiet period"):
+		return "quiet_period"
+	case item.Blocked, strings.Contains(why, "already in progress"), strings.Contains(why, "blocked"):
+		return "blocked"
+	case strings.Contains(why, "offline"), strings.Contains(why, "there are no nodes"), strings.Contains(why, "doesn’t have label"), strings.Contains(why, "doesn't have label"):
+		return "offline"
+	case strings.Contains(why, "executor"):
+		return "executor"
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,127 @@
+package exporter
+
+import (
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/promhippie/jenkins_exporter/pkg/config"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkinstest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestQueueCollector(t *testing.T, routes map[string]string) *QueueCollector {
+	t.Helper()
+
+	srv := jenkinstest.NewServer(t, routes)
+	client, err := jenkins.NewClient(
+		jenkins.WithEndpoint(srv.URL),
+		jenkins.WithTimeout(5*time.Second),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewQueueCollector(
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		client,
+		prometheus.NewCounterVec(prometheus.CounterOpts{Name: "failures"}, []string{"collector"}),
+		prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "duration"}, []string{"collector"}),
+		config.Target{Timeout: 5 * time.Second},
+	)
+}
+
+func TestQueueCollectorItemInfo(t *testing.T) {
+	c := newTestQueueCollector(t, map[string]string{
+		"/queue/api/json": `{"items": [
+			{"id": 1, "why": "Waiting for next available executor on ‘agent-1’", "task": {"name": "app", "url": "$URL/job/team/job/app/"}},
+			{"id": 2, "why": "In the quiet period. Expires in 4.9 sec", "task": {"name": "lib", "url": "$URL/job/lib/"}},
+			{"id": 3, "why": "Build #12 is already in progress (ETA: 1 min)", "blocked": true, "task": {"name": "deploy", "url": "$URL/job/deploy/"}},
+			{"id": 4, "why": "‘agent-2’ is offline", "task": {"name": "nightly", "url": "$URL/job/nightly/"}},
+			{"id": 5, "why": "Waiting for next available executor", "task": {"name": "app", "url": "$URL/job/team/job/app/"}},
+			{"id": 6, "why": "Something unexpected", "task": {"name": "misc", "url": "$URL/job/misc/"}}
+		]}`,
+	})
+
+	expected := `
+# HELP jenkins_queue_item_info Constant 1 for queued jobs, reason label contains why the item is waiting
+# TYPE jenkins_queue_item_info gauge
+jenkins_queue_item_info{job_name="deploy",reason="blocked"} 1
+jenkins_queue_item_info{job_name="lib",reason="quiet_period"} 1
+jenkins_queue_item_info{job_name="misc",reason="other"} 1
+jenkins_queue_item_info{job_name="nightly",reason="offline"} 1
+jenkins_queue_item_info{job_name="team/app",reason="executor"} 1
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_queue_item_info"))
+}
+
+func TestQueueCollectorItemWait(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	enqueued := now.Add(-90 * time.Second).UnixMilli()
+	recent := now.Add(-2 * time.Second).UnixMilli()
+
+	c := newTestQueueCollector(t, map[string]string{
+		"/queue/api/json": `{"items": [
+			{"id": 1, "why": "Waiting for next available executor", "inQueueSince": ` + strconv.FormatInt(enqueued, 10) + `, "task": {"name": "app", "url": "$URL/job/app/"}},
+			{"id": 2, "why": "Waiting for next available executor", "inQueueSince": ` + strconv.FormatInt(recent, 10) + `, "task": {"name": "app", "url": "$URL/job/app/"}},
+			{"id": 3, "why": "In the quiet period. Expires in 3 sec", "inQueueSince": ` + strconv.FormatInt(recent, 10) + `, "task": {"name": "lib", "url": "$URL/job/lib/"}}
+		]}`,
+	})
+
+	c.clock = jenkinstest.NewClock(now)
+
+	expected := `
+# HELP jenkins_queue_item_wait_seconds Seconds the longest waiting queue item of the job is in the queue
+# TYPE jenkins_queue_item_wait_seconds gauge
+jenkins_queue_item_wait_seconds{job_name="app"} 90
+jenkins_queue_item_wait_seconds{job_name="lib"} 2
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_queue_item_wait_seconds"))
+}
+
+func TestQueueCollectorSize(t *testing.T) {
+	c := newTestQueueCollector(t, map[string]string{
+		"/queue/api/json": `{"items": [
+			{"id": 1, "why": "Waiting for next available executor", "stuck": true, "task": {"name": "app", "url": "$URL/job/app/"}},
+			{"id": 2, "why": "Build #12 is already in progress (ETA: 1 min)", "blocked": true, "task": {"name": "deploy", "url": "$URL/job/deploy/"}},
+			{"id": 3, "why": "In the quiet period. Expires in 4.9 sec", "task": {"name": "lib", "url": "$URL/job/lib/"}}
+		]}`,
+	})
+
+	expected := `
+# HELP jenkins_queue_item_blocked Number of items in the build queue which are blocked
+# TYPE jenkins_queue_item_blocked gauge
+jenkins_queue_item_blocked 1
+# HELP jenkins_queue_item_stuck Number of items in the build queue which are stuck
+# TYPE jenkins_queue_item_stuck gauge
+jenkins_queue_item_stuck 1
+# HELP jenkins_queue_size Number of items in the build queue
+# TYPE jenkins_queue_size gauge
+jenkins_queue_size 3
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_queue_size", "jenkins_queue_item_stuck", "jenkins_queue_item_blocked"))
+}
+
+func TestQueueCollectorEmptyQueue(t *testing.T) {
+	c := newTestQueueCollector(t, map[string]string{
+		"/queue/api/json": `{"items": []}`,
+	})
+
+	expected := `
+# HELP jenkins_queue_size Number of items in the build queue
+# TYPE jenkins_queue_size gauge
+jenkins_queue_size 0
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "jenkins_queue_size"))
+}
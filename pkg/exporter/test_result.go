@@ -0,0 +1,176 @@
+package exporter
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/promhippie/jenkins_exporter/pkg/config"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
+)
+
+// TestResultCollector collects metrics about the test results of the last
+// completed builds.
+type TestResultCollector struct {
+	client   *jenkins.Client
+	logger   *slog.Logger
+	failures *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	config   config.Target
+	jobs     *JobCollector // 提供作业列表及 job_name 标签
+	workers  int
+
+	Total   *prometheus.Desc
+	Failed  *prometheus.Desc
+	Skipped *prometheus.Desc
+}
+
+// NewTestResultCollector returns a new TestResultCollector. The job list and
+// the job_name labels are shared with the given JobCollector, a dedicated one
+// gets created if it's nil.
+func NewTestResultCollector(logger *slog.Logger, client *jenkins.Client, failures *prometheus.CounterVec, duration *prometheus.HistogramVec, cfg config.Target, collector config.Collector, jobs *JobCollector) *TestResultCollector {
+	if failures != nil {
+		failures.WithLabelValues("tests").Add(0)
+	}
+
+	if jobs == nil {
+		jobs = NewJobCollector(logger, client, nil, duration, cfg, collector)
+	}
+
+	labels := []string{"job_name"}
+	return &TestResultCollector{
+		client:   client,
+		logger:   logger.With("collector", "tests"),
+		failures: failures,
+		duration: duration,
+		config:   cfg,
+		jobs:     jobs,
+		workers:  jobWorkers(collector.CollectorConcurrency),
+
+		Total: prometheus.NewDesc(
+			"jenkins_build_tests_total",
+			"Number of tests executed by the last completed build",
+			labels,
+			nil,
+		),
+		Failed: prometheus.NewDesc(
+			"jenkins_build_tests_failed",
+			"Number of failed tests of the last completed build",
+			labels,
+			nil,
+		),
+		Skipped: prometheus.NewDesc(
+			"jenkins_build_tests_skipped",
+			"Number of skipped tests of the last completed build",
+			labels,
+			nil,
+		),
+	}
+}
+
+// Metrics simply returns the list metric descriptors for generating a documentation.
+func (c *TestResultCollector) Metrics() []*prometheus.Desc {
+	return []*prometheus.Desc{
+		c.Total,
+		c.Failed,
+		c.Skipped,
+	}
+}
+
+// Describe sends the super-set of all possible descriptors of metrics collected by this Collector.
+func (c *TestResultCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.Total
+	ch <- c.Failed
+	ch <- c.Skipped
+}
+
+// Collect is called by the Prometheus registry when collecting metrics.
+func (c *TestResultCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+
+	now := time.Now()
+	jobs, err := c.jobs.jobList(ctx)
+
+	if err != nil {
+		c.duration.WithLabelValues("tests").Observe(time.Since(now).Seconds())
+
+		c.logger.Error("获取作业列表失败",
+			"错误", err,
+		)
+
+		c.failures.WithLabelValues("tests").Inc()
+		return
+	}
+
+	jobsChan := make(chan jenkins.Job, len(jobs))
+	var wg sync.WaitGroup
+
+	for w := 0; w < c.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobsChan {
+				c.collectJob(ctx, ch, job)
+			}
+		}()
+	}
+
+	for _, job := range jobs {
+		// 从未完成构建的作业没有测试报告
+		if job.LastCompletedBuild == nil {
+			continue
+		}
+
+		jobsChan <- job
+	}
+	close(jobsChan)
+
+	wg.Wait()
+	c.duration.WithLabelValues("tests").Observe(time.Since(now).Seconds())
+}
+
+// collectJob exports the test results of a single job, nothing gets exported
+// if the job doesn't publish test results.
+func (c *TestResultCollector) collectJob(ctx context.Context, ch chan<- prometheus.Metric, job jenkins.Job) {
+	report, err := c.client.Job.TestReport(ctx, job)
+
+	if err != nil {
+		c.logger.Log(ctx, jenkins.LevelTrace, "获取测试报告失败",
+			"job_name", job.Path,
+			"错误", err,
+		)
+
+		c.failures.WithLabelValues("tests").Inc()
+		return
+	}
+
+	if report == nil {
+		return
+	}
+
+	name := c.jobs.jobName(job)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.Total,
+		prometheus.GaugeValue,
+		float64(report.Total()),
+		name,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.Failed,
+		prometheus.GaugeValue,
+		float64(report.FailCount),
+		name,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.Skipped,
+		prometheus.GaugeValue,
+		float64(report.SkipCount),
+		name,
+	)
+}
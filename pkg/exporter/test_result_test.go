@@ -0,0 +1,130 @@
+package exporter
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/promhippie/jenkins_exporter/pkg/config"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkins"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkinstest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestResultCollector(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json": `{"jobs": [
+			{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/app/"},
+			{"_class": "hudson.model.FreeStyleProject", "name": "lib", "url": "$URL/job/lib/"},
+			{"_class": "hudson.matrix.MatrixProject", "name": "matrix", "url": "$URL/job/matrix/"},
+			{"_class": "hudson.model.FreeStyleProject", "name": "fresh", "url": "$URL/job/fresh/"}
+		]}`,
+		"/job/app/api/json": `{"_class": "hudson.model.FreeStyleProject", "fullName": "app", "url": "$URL/job/app/", "lastCompletedBuild": {"number": 3, "url": "$URL/job/app/3/"}}`,
+		"/job/app/lastCompletedBuild/testReport/api/json": `{"passCount": 40, "failCount": 2, "skipCount": 1}`,
+		"/job/lib/api/json":    `{"_class": "hudson.model.FreeStyleProject", "fullName": "lib", "url": "$URL/job/lib/", "lastCompletedBuild": {"number": 8, "url": "$URL/job/lib/8/"}}`,
+		"/job/matrix/api/json": `{"_class": "hudson.matrix.MatrixProject", "fullName": "matrix", "url": "$URL/job/matrix/", "lastCompletedBuild": {"number": 2, "url": "$URL/job/matrix/2/"}}`,
+		"/job/matrix/lastCompletedBuild/testReport/api/json": `{"totalCount": 12, "failCount": 0, "skipCount": 3}`,
+		"/job/fresh/api/json":                                `{"_class": "hudson.model.FreeStyleProject", "fullName": "fresh", "url": "$URL/job/fresh/", "lastCompletedBuild": null}`,
+		"/job/fresh/lastCompletedBuild/testReport/api/json":  `{"passCount": 1}`,
+	})
+
+	client, err := jenkins.NewClient(
+		jenkins.WithEndpoint(srv.URL),
+		jenkins.WithTimeout(5*time.Second),
+	)
+	assert.NoError(t, err)
+
+	c := NewTestResultCollector(
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		client,
+		prometheus.NewCounterVec(prometheus.CounterOpts{Name: "failures"}, []string{"collector"}),
+		prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "duration"}, []string{"collector"}),
+		config.Target{Timeout: 5 * time.Second},
+		config.Collector{},
+		nil,
+	)
+
+	// lib 没有测试报告，fresh 从未完成构建，都不导出指标
+	expected := `
+# HELP jenkins_build_tests_failed Number of failed tests of the last completed build
+# TYPE jenkins_build_tests_failed gauge
+jenkins_build_tests_failed{job_name="app"} 2
+jenkins_build_tests_failed{job_name="matrix"} 0
+# HELP jenkins_build_tests_skipped Number of skipped tests of the last completed build
+# TYPE jenkins_build_tests_skipped gauge
+jenkins_build_tests_skipped{job_name="app"} 1
+jenkins_build_tests_skipped{job_name="matrix"} 3
+# HELP jenkins_build_tests_total Number of tests executed by the last completed build
+# TYPE jenkins_build_tests_total gauge
+jenkins_build_tests_total{job_name="app"} 43
+jenkins_build_tests_total{job_name="matrix"} 12
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected)))
+}
+
+func TestTestResultCollectorDuplicatePath(t *testing.T) {
+	var listed atomic.Int32
+
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json": `{"jobs": [
+			{"_class": "hudson.model.FreeStyleProject", "name": "app-pr-1", "url": "$URL/job/app-pr-1/"},
+			{"_class": "hudson.model.FreeStyleProject", "name": "app-pr-2", "url": "$URL/job/app-pr-2/"}
+		]}`,
+		"/job/app-pr-1/api/json":                               `{"_class": "hudson.model.FreeStyleProject", "fullName": "app-pr-1", "url": "$URL/job/app-pr-1/", "lastCompletedBuild": {"number": 1, "url": "$URL/job/app-pr-1/1/"}}`,
+		"/job/app-pr-1/lastCompletedBuild/testReport/api/json": `{"passCount": 4, "failCount": 1}`,
+		"/job/app-pr-2/api/json":                               `{"_class": "hudson.model.FreeStyleProject", "fullName": "app-pr-2", "url": "$URL/job/app-pr-2/", "lastCompletedBuild": {"number": 1, "url": "$URL/job/app-pr-2/1/"}}`,
+		"/job/app-pr-2/lastCompletedBuild/testReport/api/json": `{"passCount": 2}`,
+	})
+
+	// 统计获取作业列表的次数
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/json" {
+			listed.Add(1)
+		}
+
+		srv.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(proxy.Close)
+
+	client, err := jenkins.NewClient(
+		jenkins.WithEndpoint(proxy.URL),
+		jenkins.WithTimeout(5*time.Second),
+	)
+	assert.NoError(t, err)
+
+	c := NewTestResultCollector(
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		client,
+		prometheus.NewCounterVec(prometheus.CounterOpts{Name: "failures"}, []string{"collector"}),
+		prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "duration"}, []string{"collector"}),
+		config.Target{Timeout: 5 * time.Second},
+		config.Collector{
+			NameRules: []string{`-pr-\d+$=`},
+			CacheFile: filepath.Join(t.TempDir(), "jobs.json"),
+			CacheTTL:  time.Hour,
+		},
+		nil,
+	)
+
+	reg := prometheus.NewPedanticRegistry()
+	assert.NoError(t, reg.Register(c))
+
+	// 两个作业重命名后的 job_name 相同，只导出第一个，抓取不会失败
+	for range 2 {
+		count, err := testutil.GatherAndCount(reg, "jenkins_build_tests_total")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+	}
+
+	// 第二次抓取使用缓存的作业列表
+	assert.Equal(t, int32(1), listed.Load())
+}
@@ -0,0 +1,221 @@
+package jenkins
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// defaultRetryAfter defines the cooldown if a rate limited response
+	// doesn't contain a valid Retry-After header.
+	defaultRetryAfter = time.Second
+)
+
+// WithBackpressure configures a Client to limit the requests in flight to the
+// given maximum. The limit gets halved whenever Jenkins responds with 429 and
+// grows again by one per successful response, the given counter gets
+// incremented for every rate limited response. Disabled if 0.
+func WithBackpressure(maxInFlight int, limited prometheus.Counter) ClientOption {
+	return func(client *Client) error {
+		if maxInFlight > 0 {
+			client.backpressure = newBackpressure(maxInFlight, limited)
+		}
+
+		return nil
+	}
+}
+
+// backpressure limits the requests in flight and adapts the limit to the rate
+// limiting of Jenkins, additive increase and multiplicative decrease.
+type backpressure struct {
+	mu       sync.Mutex
+	max      int
+	limit    int
+	inFlight int
+	until    time.Time     // 冷却结束前不发送新的请求
+	wake     chan struct{} // 释放请求或冷却结束时唤醒等待的请求
+	limited  prometheus.Counter
+}
+
+// newBackpressure creates a new backpressure with the given maximum limit.
+func newBackpressure(maxInFlight int, limited prometheus.Counter) *backpressure {
+	return &backpressure{
+		max:     maxInFlight,
+		limit:   maxInFlight,
+		wake:    make(chan struct{}),
+		limited: limited,
+	}
+}
+
+// acquire waits until the cooldown passed and a request is allowed.
+func (b *backpressure) acquire(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+
+		if wait := time.Until(b.until); wait > 0 {
+			b.mu.Unlock()
+
+			if err := sleepContext(ctx, wait); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if b.inFlight < b.limit {
+			b.inFlight++
+			b.mu.Unlock()
+			return nil
+		}
+
+		wake := b.wake
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wake:
+		}
+	}
+}
+
+// release frees the slot of a finished request.
+func (b *backpressure) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.inFlight--
+	b.broadcast()
+}
+
+// succeeded increases the limit by one up to the maximum.
+func (b *backpressure) succeeded() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.limit < b.max {
+		b.limit++
+		b.broadcast()
+	}
+}
+
+// rateLimited halves the limit and pauses new requests for the cooldown.
+func (b *backpressure) rateLimited(cooldown time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.limit = max(1, b.limit/2)
+
+	if until := time.Now().Add(cooldown); until.After(b.until) {
+		b.until = until
+	}
+
+	if b.limited != nil {
+		b.limited.Inc()
+	}
+}
+
+// broadcast wakes all waiting requests, the lock has to be held.
+func (b *backpressure) broadcast() {
+	close(b.wake)
+	b.wake = make(chan struct{})
+}
+
+// backpressureTransport applies the backpressure to the requests of both the
+// REST client and the SDK.
+type backpressureTransport struct {
+	base         http.RoundTripper
+	backpressure *backpressure
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *backpressureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	ctx := req.Context()
+	res, err := t.send(base, req)
+
+	if err != nil || res.StatusCode != http.StatusTooManyRequests {
+		return res, err
+	}
+
+	// 只重试一次幂等请求，等待会超过截止时间时直接返回 429
+	if req.Method != http.MethodGet || req.Body != nil {
+		return res, nil
+	}
+
+	cooldown := retryAfter(res.Header.Get("Retry-After"))
+
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(cooldown).After(deadline) {
+		return res, nil
+	}
+
+	_ = res.Body.Close()
+
+	// 等待期间不占用并发槽位，重试时重新获取
+	if err := sleepContext(ctx, cooldown); err != nil {
+		return nil, err
+	}
+
+	return t.send(base, req)
+}
+
+// send executes a single request within a slot of the backpressure and adapts
+// the limit to the response.
+func (t *backpressureTransport) send(base http.RoundTripper, req *http.Request) (*http.Response, error) {
+	if err := t.backpressure.acquire(req.Context()); err != nil {
+		return nil, err
+	}
+
+	defer t.backpressure.release()
+
+	res, err := base.RoundTrip(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case res.StatusCode == http.StatusTooManyRequests:
+		t.backpressure.rateLimited(retryAfter(res.Header.Get("Retry-After")))
+	case res.StatusCode < http.StatusBadRequest:
+		t.backpressure.succeeded()
+	}
+
+	return res, nil
+}
+
+// retryAfter parses the Retry-After header, either as seconds or as HTTP
+// date. It falls back to a default cooldown for invalid values.
+func retryAfter(value string) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(0, time.Until(date))
+	}
+
+	return defaultRetryAfter
+}
+
+// sleepContext waits for the given duration or until the context is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
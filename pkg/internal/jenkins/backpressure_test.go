@@ -0,0 +1,147 @@
+package jenkins
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientBacksOffOnRateLimit(t *testing.T) {
+	var requests atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		_, _ = io.WriteString(w, `{"quietingDown": true}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	limited := prometheus.NewCounter(prometheus.CounterOpts{Name: "jenkins_rate_limited_total"})
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+		WithBackpressure(4, limited),
+	)
+	assert.NoError(t, err)
+
+	start := time.Now()
+	status, err := client.Job.Status(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, status.QuietingDown)
+
+	// 等待 Retry-After 指定的时间后才重试
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(2), requests.Load())
+	assert.Equal(t, 1.0, testutil.ToFloat64(limited))
+
+	// 限制减半后随成功的请求逐步恢复
+	assert.Equal(t, 3, client.backpressure.limit)
+	assert.Equal(t, 0, client.backpressure.inFlight)
+}
+
+func TestClientCountsRateLimitedRetry(t *testing.T) {
+	var requests atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	t.Cleanup(srv.Close)
+
+	limited := prometheus.NewCounter(prometheus.CounterOpts{Name: "jenkins_rate_limited_total"})
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+		WithBackpressure(4, limited),
+	)
+	assert.NoError(t, err)
+
+	_, err = client.Job.Status(context.Background())
+	assert.Error(t, err)
+
+	// 重试得到的 429 同样计数并再次减半限制
+	assert.Equal(t, int32(2), requests.Load())
+	assert.Equal(t, 2.0, testutil.ToFloat64(limited))
+	assert.Equal(t, 1, client.backpressure.limit)
+	assert.Equal(t, 0, client.backpressure.inFlight)
+}
+
+func TestClientReleasesSlotDuringRetryAfter(t *testing.T) {
+	var requests atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		_, _ = io.WriteString(w, `{"quietingDown": true}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+		WithBackpressure(1, nil),
+	)
+	assert.NoError(t, err)
+
+	done := make(chan error, 1)
+
+	go func() {
+		_, err := client.Job.Status(context.Background())
+		done <- err
+	}()
+
+	// 等待 Retry-After 期间不占用槽位
+	assert.Eventually(t, func() bool {
+		client.backpressure.mu.Lock()
+		defer client.backpressure.mu.Unlock()
+
+		return requests.Load() == 1 && client.backpressure.inFlight == 0 && !client.backpressure.until.IsZero()
+	}, 900*time.Millisecond, 10*time.Millisecond)
+
+	assert.NoError(t, <-done)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestBackpressureLimitsInFlight(t *testing.T) {
+	b := newBackpressure(2, nil)
+	b.rateLimited(0)
+	assert.Equal(t, 1, b.limit)
+
+	assert.NoError(t, b.acquire(context.Background()))
+
+	// 达到限制后新的请求需要等待
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, b.acquire(ctx), context.DeadlineExceeded)
+
+	b.release()
+	assert.NoError(t, b.acquire(context.Background()))
+}
+
+func TestRetryAfter(t *testing.T) {
+	assert.Equal(t, 3*time.Second, retryAfter("3"))
+	assert.Equal(t, defaultRetryAfter, retryAfter(""))
+	assert.Equal(t, defaultRetryAfter, retryAfter("soon"))
+
+	date := time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)
+	assert.InDelta(t, 10*time.Second, retryAfter(date), float64(2*time.Second))
+}
@@ -0,0 +1,141 @@
+package jenkins
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// WithMaxSeries configures the maximum number of distinct label combinations
+// of the last build result metric within the series window, the metric
+// carries the commit, branch and parameter labels. New series beyond the limit
+// get dropped while existing series keep updating. A limit of 0 disables the
+// guard.
+func WithMaxSeries(limit int) BuildCollectorOption {
+	return func(c *BuildCollector) {
+		c.resultSeries.limit = limit
+	}
+}
+
+// newCardinalityLimited creates the counter of series dropped by the guard.
+func newCardinalityLimited() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "jenkins_cardinality_limited_total",
+			Help: "Number of new series dropped because the metric reached the maximum number of series",
+		},
+		[]string{"metric"},
+	)
+}
+
+// seriesWindow defines how long a label combination counts against the limit
+// after it has been set the last time. Prometheus keeps series in its head
+// block for about two hours, so replaced series still consume memory there.
+const seriesWindow = 2 * time.Hour
+
+// seriesGuard tracks the distinct label combinations of a metric set within
+// the series window by job name, replacing the series of a job doesn't free
+// its slot before the window passed. It's not safe for concurrent use, the
+// callers hold the metrics lock.
+type seriesGuard struct {
+	limit  int
+	series map[string]map[string]time.Time
+	count  int
+	warned bool
+}
+
+// newSeriesGuard creates a guard without limit.
+func newSeriesGuard() *seriesGuard {
+	return &seriesGuard{
+		series: make(map[string]map[string]time.Time),
+	}
+}
+
+// allow checks if the series with the given label values may be set, known
+// series are always allowed and get recorded otherwise.
+func (g *seriesGuard) allow(jobName string, values []string, now time.Time) bool {
+	if g.limit <= 0 {
+		return true
+	}
+
+	key := strings.Join(values, "\xff")
+
+	if _, ok := g.series[jobName][key]; ok {
+		g.series[jobName][key] = now
+		return true
+	}
+
+	if g.count >= g.limit {
+		g.expire(now)
+	}
+
+	if g.count >= g.limit {
+		return false
+	}
+
+	if g.series[jobName] == nil {
+		g.series[jobName] = make(map[string]time.Time)
+	}
+
+	g.series[jobName][key] = now
+	g.count++
+
+	return true
+}
+
+// expire removes the label combinations which haven't been set within the
+// series window.
+func (g *seriesGuard) expire(now time.Time) {
+	for jobName, series := range g.series {
+		for key, seen := range series {
+			if now.Sub(seen) >= seriesWindow {
+				delete(series, key)
+				g.count--
+			}
+		}
+
+		if len(series) == 0 {
+			delete(g.series, jobName)
+		}
+	}
+
+	g.reset()
+}
+
+// release removes all series of the job, e.g. after the job has been removed
+// from the enabled jobs.
+func (g *seriesGuard) release(jobName string) {
+	g.count -= len(g.series[jobName])
+	delete(g.series, jobName)
+	g.reset()
+}
+
+// retain releases the series of all jobs which are not part of the given
+// job names.
+func (g *seriesGuard) retain(jobNames map[string]bool) {
+	for jobName := range g.series {
+		if !jobNames[jobName] {
+			g.release(jobName)
+		}
+	}
+}
+
+// reset allows to warn again once the number of series dropped below the
+// limit.
+func (g *seriesGuard) reset() {
+	if g.count < g.limit {
+		g.warned = false
+	}
+}
+
+// limited reports if a warning should be logged for the dropped series, it's
+// only true once until the number of series drops below the limit again.
+func (g *seriesGuard) limited() bool {
+	if g.warned {
+		return false
+	}
+
+	g.warned = true
+	return true
+}
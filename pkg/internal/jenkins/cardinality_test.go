@@ -0,0 +1,85 @@
+package jenkins
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkinstest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetBuildResultMaxSeries(t *testing.T) {
+	c := NewBuildCollector(nil, nil, testLogger(), 1, WithMaxSeries(2))
+
+	c.setBuildResult("a", "c1", "main", "success", "", nil)
+	c.setBuildResult("b", "c1", "main", "success", "", nil)
+
+	// 达到最大序列数量后新的序列被丢弃
+	c.setBuildResult("c", "c1", "main", "success", "", nil)
+	c.setBuildResult("d", "c1", "main", "failure", "", nil)
+
+	// 已有的序列继续更新
+	c.buildResultGauge.WithLabelValues("a", "c1", "main", "success").Set(0)
+	c.setBuildResult("a", "c1", "main", "success", "", nil)
+
+	expected := `
+# HELP jenkins_build_last_result ` + BuildLastResultHelp + `
+# TYPE jenkins_build_last_result gauge
+jenkins_build_last_result{check_commitID="c1",gitBranch="main",job_name="a",status="success"} 1
+jenkins_build_last_result{check_commitID="c1",gitBranch="main",job_name="b",status="success"} 1
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c.buildResultGauge, strings.NewReader(expected)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(c.cardinality.WithLabelValues("jenkins_build_last_result")))
+
+	// 达到最大序列数量后新的提交也不能添加新的序列，保留之前的序列
+	c.setBuildResult("a", "c2", "main", "success", "", nil)
+	assert.NoError(t, testutil.CollectAndCompare(c.buildResultGauge, strings.NewReader(expected)))
+	assert.Equal(t, 3.0, testutil.ToFloat64(c.cardinality.WithLabelValues("jenkins_build_last_result")))
+}
+
+func TestSetBuildResultMaxSeriesRelease(t *testing.T) {
+	clock := jenkinstest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	c := NewBuildCollector(nil, nil, testLogger(), 1, WithMaxSeries(2))
+	c.clock = clock
+
+	c.setBuildResult("a", "c1", "main", "success", "", nil)
+	c.setBuildResult("b", "c1", "main", "success", "", nil)
+
+	// 替换的序列在时间窗口内仍然占用名额
+	c.setBuildResult("a", "c2", "main", "success", "", nil)
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.cardinality.WithLabelValues("jenkins_build_last_result")))
+
+	// 不再启用的 job 释放名额
+	c.resultSeries.retain(map[string]bool{"a": true})
+	c.setBuildResult("c", "c1", "main", "success", "", nil)
+
+	// 超过时间窗口未更新的序列释放名额
+	clock.Advance(seriesWindow)
+	c.setBuildResult("a", "c2", "main", "success", "", nil)
+
+	expected := `
+# HELP jenkins_build_last_result ` + BuildLastResultHelp + `
+# TYPE jenkins_build_last_result gauge
+jenkins_build_last_result{check_commitID="c1",gitBranch="main",job_name="b",status="success"} 1
+jenkins_build_last_result{check_commitID="c1",gitBranch="main",job_name="c",status="success"} 1
+jenkins_build_last_result{check_commitID="c2",gitBranch="main",job_name="a",status="success"} 1
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c.buildResultGauge, strings.NewReader(expected)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.cardinality.WithLabelValues("jenkins_build_last_result")))
+}
+
+func TestSetBuildResultUnlimited(t *testing.T) {
+	c := NewBuildCollector(nil, nil, testLogger(), 1)
+
+	for _, job := range []string{"a", "b", "c"} {
+		c.setBuildResult(job, "", "", "success", "", nil)
+	}
+
+	assert.Equal(t, 3, testutil.CollectAndCount(c.buildResultGauge))
+	assert.Equal(t, 0.0, testutil.ToFloat64(c.cardinality.WithLabelValues("jenkins_build_last_result")))
+}
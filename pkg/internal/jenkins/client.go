@@ -7,11 +7,17 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"os"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 const (
@@ -21,16 +27,42 @@ const (
 
 // Client is a client for the Jenkins API.
 type Client struct {
-	httpClient *http.Client
-	httpDumper Dumper
-	endpoint   string
-	username   string
-	password   string
-	timeout    time.Duration
+	httpClient    *http.Client
+	httpDumper    Dumper
+	endpoint      string
+	username      string
+	password      string
+	token         string          // API token，设置后优先于密码使用
+	authMutex     sync.RWMutex    // 保护重新读取的用户名和密码
+	reload        CredentialsFunc // 认证失败时重新读取凭据，为 nil 则不重新读取
+	authMode      string          // 凭据的传递方式，basic 或 bearer，为空时使用 basic
+	timeout       time.Duration
+	tlsServerName string
+	insecure      bool               // 是否跳过 TLS 证书校验
+	caCertFile    string             // 额外信任的 CA 证书文件，PEM 格式
+	maxResponse   int64              // 响应体的最大字节数，0 表示不限制
+	crumb         bool               // 是否为非 GET 请求获取 CSRF crumb
+	crumbs        *crumbCache        // REST 客户端和 SDK 共享的 CSRF crumb
+	retryAttempts int                // GET 请求遇到 5xx 或网络错误时的最大尝试次数，不大于 1 表示不重试
+	retryDelay    time.Duration      // 首次重试前的等待时间，之后指数增长
+	backpressure  *backpressure      // 根据 429 响应调整同时进行的请求数量，为 nil 则不限制
+	folderGlob    bool               // 是否将配置的文件夹作为 glob 模式匹配文件夹路径
+	folderDepth   int                // 最大的文件夹递归深度，0 表示不限制
+	folderTimeout time.Duration      // 递归时每个文件夹请求的超时，0 表示不限制
+	jobFilter     *JobFilter         // 按正则表达式包含或排除 job，为 nil 则不过滤
+	logger        *slog.Logger       // 记录遍历 job 时的警告，为 nil 则不记录
+	authFailures  prometheus.Counter // 响应 401 或 403 时递增，为 nil 则不统计
 
 	Job      JobClient
+	Computer ComputerClient
+	Queue    QueueClient
+	Load     LoadClient
 	SDK      *SDKClient // gojenkins SDK 客户端
 	useSDK   bool       // 是否使用 SDK 模式
+
+	sdkMutex    sync.Mutex
+	sdkFallback int // SDK 连续初始化失败多少次后回退到 REST，0 表示不回退
+	sdkFailures int // SDK 连续初始化失败次数
 }
 
 // Endpoint returns the Jenkins API endpoint.
@@ -58,8 +90,16 @@ func WithHTTPDumper(value Dumper) ClientOption {
 }
 
 // WithEndpoint configures a Client to use the specified API endpoint.
+// The endpoint must be an absolute http or https URL.
 func WithEndpoint(endpoint string) ClientOption {
 	return func(client *Client) error {
+		// 缺少协议时请求会在深处以难以理解的错误失败，启动时直接报错
+		parsed, err := url.Parse(endpoint)
+
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("invalid endpoint %q, expected an absolute URL like https://jenkins.example.com", endpoint)
+		}
+
 		client.endpoint = strings.TrimRight(endpoint, "/")
 		return nil
 	}
@@ -81,6 +121,15 @@ func WithPassword(password string) ClientOption {
 	}
 }
 
+// WithToken configures a Client to authenticate with the specified API token,
+// it takes precedence over the password.
+func WithToken(token string) ClientOption {
+	return func(client *Client) error {
+		client.token = token
+		return nil
+	}
+}
+
 // WithTimeout configures a Client to use the specified timeout for HTTP requests.
 func WithTimeout(timeout time.Duration) ClientOption {
 	return func(client *Client) error {
@@ -89,6 +138,70 @@ func WithTimeout(timeout time.Duration) ClientOption {
 	}
 }
 
+// WithTLSServerName configures a Client to verify the certificate against the
+// given server name instead of the host of the endpoint.
+func WithTLSServerName(name string) ClientOption {
+	return func(client *Client) error {
+		client.tlsServerName = name
+		return nil
+	}
+}
+
+// WithInsecureSkipVerify configures a Client to skip the verification of the
+// TLS certificate, e.g. for Jenkins with a self-signed certificate.
+func WithInsecureSkipVerify(skip bool) ClientOption {
+	return func(client *Client) error {
+		client.insecure = skip
+		return nil
+	}
+}
+
+// WithCACertFile configures a Client to trust the PEM encoded certificates of
+// the given file in addition to the system certificates, e.g. for Jenkins
+// with a certificate signed by a private CA.
+func WithCACertFile(path string) ClientOption {
+	return func(client *Client) error {
+		client.caCertFile = path
+		return nil
+	}
+}
+
+// WithMaxResponseSize configures a Client to reject responses larger than the
+// given number of bytes, disabled if 0.
+func WithMaxResponseSize(size int64) ClientOption {
+	return func(client *Client) error {
+		client.maxResponse = size
+		return nil
+	}
+}
+
+// WithCrumb configures a Client to attach a CSRF crumb to non-GET requests,
+// which is required if CSRF protection is enabled. It's disabled by default.
+func WithCrumb(enabled bool) ClientOption {
+	return func(client *Client) error {
+		client.crumb = enabled
+		return nil
+	}
+}
+
+// WithLogger configures a Client to log warnings while traversing the jobs,
+// like truncated folders or duplicated jobs.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(client *Client) error {
+		client.logger = logger
+		return nil
+	}
+}
+
+// WithSDKFallback configures a Client to fall back to the REST API after the
+// given number of consecutive SDK init failures, disabled if 0.
+func WithSDKFallback(attempts int) ClientOption {
+	return func(client *Client) error {
+		client.sdkFallback = attempts
+		return nil
+	}
+}
+
 // NewClient creates a new client.
 func NewClient(options ...ClientOption) (*Client, error) {
 	client := &Client{
@@ -101,6 +214,11 @@ func NewClient(options ...ClientOption) (*Client, error) {
 		}
 	}
 
+	// API token 和密码一样通过 Basic Auth 传递
+	if client.token != "" {
+		client.password = client.token
+	}
+
 	if client.httpClient == nil {
 		pool, err := x509.SystemCertPool()
 
@@ -108,6 +226,18 @@ func NewClient(options ...ClientOption) (*Client, error) {
 			return nil, err
 		}
 
+		if client.caCertFile != "" {
+			content, err := os.ReadFile(client.caCertFile)
+
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA certificate file: %w", err)
+			}
+
+			if !pool.AppendCertsFromPEM(content) {
+				return nil, fmt.Errorf("no certificates found in CA certificate file: %s", client.caCertFile)
+			}
+		}
+
 		timeout := client.timeout
 		if timeout == 0 {
 			timeout = 30 * time.Second // 默认30秒超时
@@ -118,13 +248,51 @@ func NewClient(options ...ClientOption) (*Client, error) {
 			Transport: &http.Transport{
 				Proxy: http.ProxyFromEnvironment,
 				TLSClientConfig: &tls.Config{
-					RootCAs: pool,
+					RootCAs:            pool,
+					ServerName:         client.tlsServerName,
+					InsecureSkipVerify: client.insecure,
 				},
 			},
 		}
 	}
 
+	// REST 客户端和 SDK 都使用这个 HTTP 客户端的 transport，统一限制请求
+	if client.backpressure != nil {
+		client.httpClient = &http.Client{
+			Timeout:       client.httpClient.Timeout,
+			CheckRedirect: client.httpClient.CheckRedirect,
+			Jar:           client.httpClient.Jar,
+			Transport: &backpressureTransport{
+				base:         client.httpClient.Transport,
+				backpressure: client.backpressure,
+			},
+		}
+	}
+
+	// 重试在限流之外，每次尝试都重新获取槽位，等待期间不占用
+	if client.retryAttempts > 1 {
+		client.httpClient = &http.Client{
+			Timeout:       client.httpClient.Timeout,
+			CheckRedirect: client.httpClient.CheckRedirect,
+			Jar:           client.httpClient.Jar,
+			Transport: &retryTransport{
+				base:     client.httpClient.Transport,
+				attempts: client.retryAttempts,
+				delay:    client.retryDelay,
+			},
+		}
+	}
+
+	client.crumbs = &crumbCache{fetch: client.fetchCrumb}
+
+	if !client.crumb {
+		client.crumbs.fetch = disabledCrumb
+	}
+
 	client.Job = JobClient{client: client}
+	client.Computer = ComputerClient{client: client}
+	client.Queue = QueueClient{client: client}
+	client.Load = LoadClient{client: client}
 
 	// 默认启用 SDK 模式
 	client.useSDK = true
@@ -142,15 +310,78 @@ func (c *Client) InitSDK(logger *slog.Logger) error {
 		return nil
 	}
 
-	sdk, err := NewSDKClient(c.endpoint, c.username, c.password, c.timeout, logger)
+	// SDK 使用相同的 HTTP 客户端，并通过共享缓存获取 crumb
+	base := c.httpClient.Transport
+
+	// 凭据可能被重新读取，SDK 请求也使用当前的凭据，SDK 本身只支持 Basic Auth
+	if c.reload != nil || c.authMode == AuthBearer {
+		base = &authTransport{
+			base:   base,
+			client: c,
+		}
+	}
+
+	httpClient := &http.Client{
+		Timeout: c.httpClient.Timeout,
+		Transport: &crumbTransport{
+			base:   base,
+			crumbs: c.crumbs,
+		},
+	}
+
+	username, password := c.credentials()
+
+	sdk, err := NewSDKClient(httpClient, c.endpoint, username, password, c.timeout, logger)
 	if err != nil {
 		return err
 	}
 
+	sdk.folderDepth = c.folderDepth
+
 	c.SDK = sdk
 	return nil
 }
 
+// InitBackend initializes the SDK if it's in use, after the configured number
+// of consecutive failures it falls back to the REST API instead.
+func (c *Client) InitBackend(logger *slog.Logger) error {
+	c.sdkMutex.Lock()
+	defer c.sdkMutex.Unlock()
+
+	if !c.useSDK {
+		return nil
+	}
+
+	err := c.InitSDK(logger)
+
+	if err == nil {
+		c.sdkFailures = 0
+		return nil
+	}
+
+	c.sdkFailures++
+
+	if c.sdkFallback > 0 && c.sdkFailures >= c.sdkFallback {
+		logger.Warn("SDK 初始化连续失败，回退到 REST 客户端",
+			"失败次数", c.sdkFailures,
+			"错误", err,
+		)
+
+		c.useSDK = false
+		return nil
+	}
+
+	return err
+}
+
+// UseSDK returns if the SDK is used, it's false after falling back to REST.
+func (c *Client) UseSDK() bool {
+	c.sdkMutex.Lock()
+	defer c.sdkMutex.Unlock()
+
+	return c.useSDK
+}
+
 // NewRequest creates an HTTP request against the Jenkins API.
 func (c *Client) NewRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
 	req, err := http.NewRequest(method, path, body)
@@ -161,20 +392,37 @@ func (c *Client) NewRequest(ctx context.Context, method, path string, body io.Re
 
 	req.Header.Set("User-Agent", UserAgent)
 
-	if c.username != "" && c.password != "" {
-		req.SetBasicAuth(
-			c.username,
-			c.password,
-		)
-	}
+	c.setAuth(req)
 
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
+	// 非 GET 请求需要携带 CSRF crumb
+	if method != http.MethodGet {
+		if err := c.setCrumb(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+
 	return req.WithContext(ctx), nil
 }
 
+// setCrumb attaches the cached CSRF crumb to the request.
+func (c *Client) setCrumb(ctx context.Context, req *http.Request) error {
+	result, err := c.crumbs.get(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if result.Field != "" {
+		req.Header.Set(result.Field, result.Value)
+	}
+
+	return nil
+}
+
 // Do performs an HTTP request against the Jenkins API.
 func (c *Client) Do(req *http.Request, v interface{}) (*Response, error) {
 	if c.httpDumper != nil {
@@ -183,6 +431,31 @@ func (c *Client) Do(req *http.Request, v interface{}) (*Response, error) {
 
 	res, err := c.httpClient.Do(req)
 
+	// crumb 可能已过期，刷新后重试一次
+	if err == nil && c.crumb && req.Method != http.MethodGet && (req.Body == nil || req.GetBody != nil) && crumbRejected(res) {
+		_ = res.Body.Close()
+		c.crumbs.invalidate()
+
+		retry := req.Clone(req.Context())
+
+		if req.GetBody != nil {
+			if retry.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := c.setCrumb(req.Context(), retry); err != nil {
+			return nil, err
+		}
+
+		res, err = c.httpClient.Do(retry)
+	}
+
+	// 挂载的凭据文件可能已轮换，重新读取后重试一次
+	if err == nil {
+		res, err = c.retryUnauthorized(req, res, c.httpClient.Do)
+	}
+
 	if res != nil {
 		defer func() { _ = res.Body.Close() }()
 	}
@@ -195,7 +468,7 @@ func (c *Client) Do(req *http.Request, v interface{}) (*Response, error) {
 		c.httpDumper.DumpResponse(res)
 	}
 
-	body, err := io.ReadAll(res.Body)
+	body, err := c.readBody(res)
 
 	if err != nil {
 		return &Response{Response: res}, err
@@ -203,6 +476,18 @@ func (c *Client) Do(req *http.Request, v interface{}) (*Response, error) {
 
 	res.Body = io.NopCloser(bytes.NewReader(body))
 
+	if (res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden) && c.authFailures != nil {
+		c.authFailures.Inc()
+	}
+
+	if res.StatusCode == http.StatusUnauthorized {
+		return &Response{Response: res}, ErrUnauthorized
+	}
+
+	if res.StatusCode == http.StatusForbidden {
+		return &Response{Response: res}, ErrForbidden
+	}
+
 	if res.StatusCode >= 400 && res.StatusCode <= 599 {
 		return &Response{Response: res}, errors.New(http.StatusText(res.StatusCode))
 	}
@@ -218,6 +503,26 @@ func (c *Client) Do(req *http.Request, v interface{}) (*Response, error) {
 	return &Response{Response: res}, err
 }
 
+// readBody reads the response body, bounded by the maximum response size so
+// huge responses of large instances don't exhaust the memory.
+func (c *Client) readBody(res *http.Response) ([]byte, error) {
+	if c.maxResponse <= 0 {
+		return io.ReadAll(res.Body)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, c.maxResponse+1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(body)) > c.maxResponse {
+		return nil, fmt.Errorf("%w: %s exceeds %d bytes", ErrResponseTooLarge, res.Request.URL.Path, c.maxResponse)
+	}
+
+	return body, nil
+}
+
 // Response simply wraps the standard response type.
 type Response struct {
 	*http.Response
@@ -0,0 +1,228 @@
+package jenkins
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkinstest"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTLSTestServer starts a TLS server with a certificate which is only valid
+// for the given DNS name, not for the address it listens on.
+func newTLSTestServer(t *testing.T, name string) (*httptest.Server, *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, `{"mode": "NORMAL", "jobs": []}`)
+	}))
+
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	}
+
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	return srv, cert
+}
+
+func TestClientTLSServerName(t *testing.T) {
+	srv, cert := newTLSTestServer(t, "jenkins.internal")
+
+	for _, tc := range []struct {
+		serverName string
+		success    bool
+	}{
+		{serverName: "", success: false},
+		{serverName: "jenkins.internal", success: true},
+	} {
+		client, err := NewClient(
+			WithEndpoint(srv.URL),
+			WithTimeout(5*time.Second),
+			WithTLSServerName(tc.serverName),
+		)
+		assert.NoError(t, err)
+
+		client.httpClient.Transport.(*http.Transport).TLSClientConfig.RootCAs.AddCert(cert)
+
+		_, err = client.Job.Root(context.Background())
+
+		if tc.success {
+			assert.NoError(t, err, tc.serverName)
+		} else {
+			assert.Error(t, err, tc.serverName)
+		}
+	}
+}
+
+func TestClientTLSVerification(t *testing.T) {
+	srv, cert := newTLSTestServer(t, "jenkins.internal")
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	assert.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0o600))
+
+	for _, tc := range []struct {
+		name    string
+		options []ClientOption
+		success bool
+	}{
+		{name: "default", success: false},
+		{name: "insecure", options: []ClientOption{WithInsecureSkipVerify(true)}, success: true},
+		{name: "ca", options: []ClientOption{WithCACertFile(caFile), WithTLSServerName("jenkins.internal")}, success: true},
+	} {
+		client, err := NewClient(append([]ClientOption{
+			WithEndpoint(srv.URL),
+			WithTimeout(5 * time.Second),
+		}, tc.options...)...)
+		assert.NoError(t, err, tc.name)
+
+		config := client.httpClient.Transport.(*http.Transport).TLSClientConfig
+		assert.Equal(t, tc.name == "insecure", config.InsecureSkipVerify, tc.name)
+
+		_, err = client.Job.Root(context.Background())
+
+		if tc.success {
+			assert.NoError(t, err, tc.name)
+		} else {
+			assert.Error(t, err, tc.name)
+		}
+	}
+
+	_, err := NewClient(WithCACertFile(filepath.Join(t.TempDir(), "missing.pem")))
+	assert.Error(t, err)
+
+	invalid := filepath.Join(t.TempDir(), "invalid.pem")
+	assert.NoError(t, os.WriteFile(invalid, []byte("no certificate"), 0o600))
+
+	_, err = NewClient(WithCACertFile(invalid))
+	assert.Error(t, err)
+}
+
+func TestClientMaxResponseSize(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/queue/api/json": `{"items": [{"id": 1, "why": "Waiting for next available executor", "task": {"name": "app"}}]}`,
+	})
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+		WithMaxResponseSize(32),
+	)
+	assert.NoError(t, err)
+
+	_, err = client.Queue.All(context.Background())
+	assert.ErrorIs(t, err, ErrResponseTooLarge)
+
+	client, err = NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+		WithMaxResponseSize(1024),
+	)
+	assert.NoError(t, err)
+
+	queue, err := client.Queue.All(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, queue.Items, 1)
+}
+
+func TestClientEndpointScheme(t *testing.T) {
+	for _, endpoint := range []string{"jenkins.example.com", "jenkins.example.com:8080", "ftp://jenkins.example.com"} {
+		_, err := NewClient(WithEndpoint(endpoint))
+		assert.ErrorContains(t, err, "expected an absolute URL", endpoint)
+	}
+
+	client, err := NewClient(WithEndpoint("https://jenkins.example.com/"))
+	assert.NoError(t, err)
+	assert.Equal(t, "https://jenkins.example.com", client.endpoint)
+}
+
+func TestClientTokenTakesPrecedence(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, _ := r.BasicAuth(); username != "admin" || password != "api-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_, _ = io.WriteString(w, `{"quietingDown": true}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithUsername("admin"),
+		WithPassword("password"),
+		WithToken("api-token"),
+		WithTimeout(5*time.Second),
+	)
+	assert.NoError(t, err)
+
+	status, err := client.Job.Status(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, status.QuietingDown)
+}
+
+func TestClientUnauthorized(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 允许匿名读取根目录，文件夹需要认证
+		if r.URL.Path == "/api/json" {
+			_, _ = io.WriteString(w, `{"jobs": [{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "team", "url": "http://`+r.Host+`/job/team/"}]}`)
+			return
+		}
+
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(srv.Close)
+
+	failures := prometheus.NewCounter(prometheus.CounterOpts{Name: "auth_failures"})
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithUsername("admin"),
+		WithPassword("wrong"),
+		WithTimeout(5*time.Second),
+		WithAuthFailures(failures),
+	)
+	assert.NoError(t, err)
+
+	_, _, err = client.Job.All(context.Background(), nil)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.Equal(t, 1.0, testutil.ToFloat64(failures))
+}
@@ -0,0 +1,19 @@
+package jenkins
+
+import (
+	"time"
+)
+
+// Clock provides the current time, it gets replaced within tests to control
+// time based behavior without sleeping.
+type Clock interface {
+	Now() time.Time
+}
+
+// RealClock is the default clock based on the system time.
+type RealClock struct{}
+
+// Now implements Clock.
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
@@ -2,9 +2,13 @@ package jenkins
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log/slog"
+	"runtime/debug"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -19,43 +23,243 @@ type BuildCollector struct {
 	repo             *storage.JobRepo
 	logger           *slog.Logger
 	buildResultGauge *prometheus.GaugeVec
+	neverBuiltGauge  *prometheus.GaugeVec
+	pendingGauge     *prometheus.GaugeVec
+	buildStatusGauge *prometheus.GaugeVec
+	durationGauge    *prometheus.GaugeVec
+	configChanged    *prometheus.CounterVec
+	panicsCounter    *prometheus.CounterVec
+	timeoutsCounter  prometheus.Counter
+	statusTally      *prometheus.GaugeVec
+	cardinality      *prometheus.CounterVec
 	mu               sync.RWMutex
 	concurrency      int // 并发数
 
 	// 按需采集相关字段
-	lastCollectTime  time.Time
-	collectMutex     sync.Mutex
-	collecting       bool          // 是否正在采集
-	collectTrigger   chan struct{} // 触发采集的通道
-	firstCollect     sync.Once     // 确保首次采集完成
-	firstCollectDone chan struct{} // 首次采集完成信号
+	lastCollectTime    time.Time
+	minCollectInterval time.Duration // 两次采集之间的最小间隔
+	collectMutex       sync.Mutex
+	collecting         bool          // 是否正在采集
+	collectTrigger     chan struct{} // 触发采集的通道
+	firstCollect       sync.Once     // 确保首次采集完成
+	firstCollectDone   chan struct{} // 首次采集完成信号
+
+	// 等待 Discovery 首次同步相关字段
+	discoveryCheckInterval time.Duration // 检查数据库的间隔
+	discoveryWaitRetries   int           // 连续数据库错误的最大次数，超过后启动失败
+
+	buildingResults []string        // 视为正在构建的构建结果字符串
+	emptyResult     string          // 已有耗时但结果为空的构建所使用的状态，为空表示 not_built
+	configChanges   bool            // 是否跟踪 job 配置变更，需要额外请求 config.xml
+	excludedJobs    []string        // 排除的顶层 job 名称
+	excludedFolders map[string]bool // 排除的顶层文件夹
+	pendingFirst    bool            // 从未构建的 job 是否只导出等待首次构建指标，不导出构建状态
+	persistCounters bool            // 是否在关闭时持久化计数器并在启动时恢复
+	statusMaxAge    time.Duration   // 按状态统计 job 数量时忽略最后构建早于该时长的 job，0 表示不限制
+	resultLabel     bool            // 是否在构建结果指标上添加 Jenkins 原始结果的 result 标签
+	priorityJobs    []string        // 优先采集的 job 或文件夹路径前缀
+	minBuild        int64           // 小于该编号的构建视为尚未构建，0 表示不限制
+	paramLabels     []string        // 作为构建结果指标额外标签导出的构建参数
+	resultSeries    *seriesGuard    // 限制构建结果指标的序列数量
+
+	// 从文件读取的排除列表，定期重新读取
+	exclusionsFile     string
+	exclusionsInterval time.Duration
+	excludeMu          sync.RWMutex
+	fileJobs           []string
+	fileFolderNames    []string
+	fileFolders        map[string]bool
+
+	maxCollectionTime time.Duration // 单次采集的最长时间，超过后取消采集，0 表示不限制
+
+	clock Clock // 时间来源，测试时可替换
+
+	// 处理单个 job 的函数，测试时可替换
+	process func(ctx context.Context, job storage.Job) (*ProcessResult, error)
+}
+
+// BuildCollectorOption is used to configure a BuildCollector.
+type BuildCollectorOption func(*BuildCollector)
+
+// WithDiscoveryWaitRetries configures how many consecutive database errors are
+// tolerated while waiting for the first Discovery sync before startup fails.
+func WithDiscoveryWaitRetries(retries int) BuildCollectorOption {
+	return func(c *BuildCollector) {
+		if retries > 0 {
+			c.discoveryWaitRetries = retries
+		}
+	}
+}
+
+// WithBuildingResults configures result strings which should be treated as
+// in progress, for plugins which don't set the building flag correctly.
+func WithBuildingResults(results []string) BuildCollectorOption {
+	return func(c *BuildCollector) {
+		c.buildingResults = results
+	}
+}
+
+// WithEmptyResultStatus configures the status used for finished builds
+// without result which have a recorded duration.
+func WithEmptyResultStatus(status string) BuildCollectorOption {
+	return func(c *BuildCollector) {
+		c.emptyResult = status
+	}
+}
+
+// WithConfigChanges enables tracking of job config changes by hashing the
+// config.xml of every job, which requires an additional request per job.
+func WithConfigChanges(enabled bool) BuildCollectorOption {
+	return func(c *BuildCollector) {
+		c.configChanges = enabled
+	}
+}
+
+// WithExcludedJobs configures top-level job names which should be skipped.
+func WithExcludedJobs(jobs []string) BuildCollectorOption {
+	return func(c *BuildCollector) {
+		c.excludedJobs = jobs
+	}
+}
+
+// WithExcludedFolders configures the top-level folders whose jobs should be
+// skipped.
+func WithExcludedFolders(folders []string) BuildCollectorOption {
+	return func(c *BuildCollector) {
+		c.excludedFolders = newExcludedFolders(folders)
+	}
+}
+
+// WithPendingFirstBuild configures never built jobs to only export the pending
+// first build gauge instead of a not_built build status.
+func WithPendingFirstBuild(enabled bool) BuildCollectorOption {
+	return func(c *BuildCollector) {
+		c.pendingFirst = enabled
+	}
+}
+
+// WithMaxCollectionTime configures the deadline of a single collection cycle,
+// the cycle gets canceled once exceeded, disabled if 0.
+func WithMaxCollectionTime(d time.Duration) BuildCollectorOption {
+	return func(c *BuildCollector) {
+		c.maxCollectionTime = d
+	}
+}
+
+// WithMinCollectInterval configures the minimum duration between two
+// collections triggered by scrapes, defaults to 5 seconds.
+func WithMinCollectInterval(d time.Duration) BuildCollectorOption {
+	return func(c *BuildCollector) {
+		if d > 0 {
+			c.minCollectInterval = d
+		}
+	}
 }
 
 // NewBuildCollector creates a new BuildCollector instance.
-func NewBuildCollector(client *Client, repo *storage.JobRepo, logger *slog.Logger, concurrency int) *BuildCollector {
+func NewBuildCollector(client *Client, repo *storage.JobRepo, logger *slog.Logger, concurrency int, options ...BuildCollectorOption) *BuildCollector {
 	if concurrency <= 0 {
 		concurrency = 10 // 默认并发数
 	}
-	return &BuildCollector{
+	c := &BuildCollector{
 		client: client,
 		repo:   repo,
 		logger: logger.With("component", "build_collector"),
-		buildResultGauge: prometheus.NewGaugeVec(
+		neverBuiltGauge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "jenkins_job_never_built",
+				Help: "1 if the job exists but has never been built",
+			},
+			[]string{"job_name"},
+		),
+		pendingGauge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "jenkins_job_pending_first_build",
+				Help: "1 if the job has never been built, only exported instead of the build status if enabled",
+			},
+			[]string{"job_name"},
+		),
+		buildStatusGauge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "jenkins_job_build_status",
+				Help: BuildStatusHelp,
+			},
+			[]string{"job_name"},
+		),
+		durationGauge: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
-				Name: "jenkins_build_last_result",
-				Help: "Last build result: 1 indicates current status, status label contains the actual status (success, failure, aborted, unstable, unknown, not_built)",
+				Name: "jenkins_build_duration_seconds",
+				Help: "Duration of the last completed build in seconds",
+			},
+			[]string{"job_name"},
+		),
+		configChanged: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "jenkins_job_config_changed_total",
+				Help: "Number of detected configuration changes of the job",
+			},
+			[]string{"job_name"},
+		),
+		panicsCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "jenkins_collector_panics_total",
+				Help: "Number of recovered panics while processing a job",
 			},
-			[]string{"job_name", "check_commitID", "gitBranch", "status"},
+			[]string{"job_name"},
 		),
+		timeoutsCounter: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "jenkins_collection_timeouts_total",
+				Help: "Number of collection cycles canceled after exceeding the maximum collection time",
+			},
+		),
+		statusTally:      newStatusTally(),
+		resultSeries:     newSeriesGuard(),
+		cardinality:      newCardinalityLimited(),
 		concurrency:      concurrency,
 		collectTrigger:   make(chan struct{}, 1), // 带缓冲的通道，避免阻塞
 		firstCollectDone: make(chan struct{}),    // 首次采集完成信号
+
+		minCollectInterval: 5 * time.Second,
+
+		discoveryCheckInterval: 5 * time.Second,
+		discoveryWaitRetries:   5,
+
+		exclusionsInterval: 30 * time.Second,
+
+		clock: RealClock{},
+	}
+
+	c.process = c.processJob
+
+	for _, option := range options {
+		option(c)
 	}
+
+	// 标签取决于配置，需要在应用配置后创建
+	c.buildResultGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "jenkins_build_last_result",
+			Help: BuildLastResultHelp,
+		},
+		BuildLastResultLabels(c.resultLabel, c.paramLabels),
+	)
+
+	return c
 }
 
 // Describe implements prometheus.Collector.
 func (c *BuildCollector) Describe(ch chan<- *prometheus.Desc) {
 	c.buildResultGauge.Describe(ch)
+	c.neverBuiltGauge.Describe(ch)
+	c.pendingGauge.Describe(ch)
+	c.buildStatusGauge.Describe(ch)
+	c.durationGauge.Describe(ch)
+	c.configChanged.Describe(ch)
+	c.panicsCounter.Describe(ch)
+	c.timeoutsCounter.Describe(ch)
+	c.statusTally.Describe(ch)
+	c.cardinality.Describe(ch)
 }
 
 // Collect implements prometheus.Collector.
@@ -86,6 +290,15 @@ func (c *BuildCollector) Collect(ch chan<- prometheus.Metric) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	c.buildResultGauge.Collect(ch)
+	c.neverBuiltGauge.Collect(ch)
+	c.pendingGauge.Collect(ch)
+	c.buildStatusGauge.Collect(ch)
+	c.durationGauge.Collect(ch)
+	c.configChanged.Collect(ch)
+	c.panicsCounter.Collect(ch)
+	c.timeoutsCounter.Collect(ch)
+	c.statusTally.Collect(ch)
+	c.cardinality.Collect(ch)
 }
 
 // triggerCollectionIfNeeded 触发按需采集（如果距离上次采集超过阈值）
@@ -99,13 +312,14 @@ func (c *BuildCollector) triggerCollectionIfNeeded() {
 		return
 	}
 
-	// 如果距离上次采集时间太短（小于 5 秒），不触发（避免频繁采集）
+	// 如果距离上次采集时间太短（小于最小采集间隔），不触发（避免频繁采集）
 	// 这样可以避免在短时间内多次请求 /metrics 时重复采集
-	timeSinceLastCollect := time.Since(c.lastCollectTime)
-	if timeSinceLastCollect < 5*time.Second {
+	timeSinceLastCollect := c.clock.Now().Sub(c.lastCollectTime)
+	if timeSinceLastCollect < c.minCollectInterval {
 		c.logger.Debug("距离上次采集时间太短，跳过本次触发（避免频繁采集）",
 			"距离上次", timeSinceLastCollect,
-			"说明", "如果 Prometheus 抓取间隔小于 5 秒，会跳过重复采集",
+			"最小间隔", c.minCollectInterval,
+			"说明", "如果 Prometheus 抓取间隔小于最小采集间隔，会跳过重复采集",
 		)
 		return
 	}
@@ -131,6 +345,35 @@ func (c *BuildCollector) Start(ctx context.Context, interval time.Duration) erro
 		"注意", "interval 参数已废弃，不再使用定时采集",
 	)
 
+	// 先读取排除列表文件，恢复的状态也不包含排除的 job
+	if c.exclusionsFile != "" {
+		if _, err := c.reloadExclusions(); err != nil {
+			c.logger.Warn("读取排除列表文件失败",
+				"文件", c.exclusionsFile,
+				"错误", err,
+			)
+		}
+
+		go c.watchExclusions(ctx)
+	}
+
+	// 先使用数据库中保存的状态，首次采集完成前 /metrics 也能返回上次的结果
+	if seeded, err := c.seedFromRepo(); err != nil {
+		c.logger.Warn("从数据库恢复构建状态失败",
+			"错误", err,
+		)
+	} else if seeded > 0 {
+		c.logger.Info("已从数据库恢复构建状态",
+			"job 数量", seeded,
+		)
+	}
+
+	if err := c.updateStatusTally(); err != nil {
+		c.logger.Warn("按状态统计 job 数量失败",
+			"错误", err,
+		)
+	}
+
 	// 等待 Discovery 完成首次同步（避免数据库为空）
 	// 最多等待 5 分钟，每 5 秒检查一次并输出进度
 	// 当有很多 job 时，Discovery 可能需要较长时间来获取和同步
@@ -138,63 +381,20 @@ func (c *BuildCollector) Start(ctx context.Context, interval time.Duration) erro
 		"说明", "Discovery 正在从 Jenkins 获取 job 列表并同步到数据库，这可能需要一些时间",
 		"最大等待时间", "5 分钟",
 	)
-	maxWaitTime := 5 * time.Minute
-	checkInterval := 5 * time.Second
-	waited := false
-	startTime := time.Now()
 
-	for i := 0; i < int(maxWaitTime/checkInterval); i++ {
-		jobs, err := c.repo.ListEnabledJobs()
-		jobCount := 0
-		if err == nil {
-			jobCount = len(jobs)
-		}
+	if err := c.waitForDiscovery(ctx, 5*time.Minute); err != nil {
+		return err
+	}
 
-		if jobCount > 0 {
-			elapsed := time.Since(startTime)
-			c.logger.Info("Discovery 已完成首次同步",
-				"job 数量", jobCount,
-				"等待时间", elapsed,
+	// 恢复上次关闭时持久化的计数器，恢复失败不影响启动
+	if c.persistCounters {
+		if err := c.restoreCounters(); err != nil {
+			c.logger.Warn("恢复计数器失败",
+				"错误", err,
 			)
-			waited = true
-			break
-		}
-
-		elapsed := time.Since(startTime)
-		// 每 30 秒输出一次等待进度，显示当前已获取的 job 数量
-		if i > 0 && i%6 == 0 {
-			if jobCount > 0 {
-				c.logger.Info("等待 Discovery 同步中...",
-					"已等待", elapsed,
-					"当前已获取 job 数量", jobCount,
-					"说明", fmt.Sprintf("Discovery 正在从 Jenkins 获取 job 列表，已获取 %d 个 job，请稍候...", jobCount),
-				)
-			} else {
-				c.logger.Info("等待 Discovery 同步中...",
-					"已等待", elapsed,
-					"当前已获取 job 数量", 0,
-					"说明", "Discovery 正在从 Jenkins 获取 job 列表，请稍候...",
-				)
-			}
-		}
-
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(checkInterval):
-			// 继续等待
 		}
 	}
 
-	if !waited {
-		elapsed := time.Since(startTime)
-		c.logger.Warn("等待 Discovery 同步超时",
-			"等待时间", elapsed,
-			"最大等待时间", maxWaitTime,
-			"提示", "如果数据库仍然为空，请检查 Discovery 日志或 Jenkins 连接。Discovery 可能需要更长时间来获取大量 job。",
-		)
-	}
-
 	// 启动后台采集协程（完全按需触发，只在请求 /metrics 时触发）
 	go func() {
 		for {
@@ -218,9 +418,88 @@ func (c *BuildCollector) Start(ctx context.Context, interval time.Duration) erro
 
 	// 主协程等待 context 取消
 	<-ctx.Done()
+
+	if c.persistCounters {
+		if err := c.saveCounters(); err != nil {
+			c.logger.Warn("持久化计数器失败",
+				"错误", err,
+			)
+		}
+	}
+
 	return ctx.Err()
 }
 
+// waitForDiscovery waits until Discovery has synced jobs into the database.
+// An empty result keeps waiting, while database errors are retried with backoff
+// and returned after too many consecutive failures instead of being masked.
+func (c *BuildCollector) waitForDiscovery(ctx context.Context, maxWaitTime time.Duration) error {
+	startTime := c.clock.Now()
+	failures := 0
+	checks := 0
+
+	for c.clock.Now().Sub(startTime) < maxWaitTime {
+		delay := c.discoveryCheckInterval
+		jobs, err := c.repo.ListEnabledJobs()
+
+		switch {
+		case err != nil:
+			failures++
+
+			if failures >= c.discoveryWaitRetries {
+				return fmt.Errorf("failed to list enabled jobs while waiting for discovery: %w", err)
+			}
+
+			// 数据库错误时指数退避，最多等待 1 分钟
+			delay = c.discoveryCheckInterval << (failures - 1)
+			if delay > time.Minute {
+				delay = time.Minute
+			}
+
+			c.logger.Warn("等待 Discovery 时读取数据库失败，稍后重试",
+				"错误", err,
+				"连续失败次数", failures,
+				"最大失败次数", c.discoveryWaitRetries,
+				"重试间隔", delay,
+			)
+		case len(jobs) > 0:
+			c.logger.Info("Discovery 已完成首次同步",
+				"job 数量", len(jobs),
+				"等待时间", c.clock.Now().Sub(startTime),
+			)
+
+			return nil
+		default:
+			failures = 0
+			checks++
+
+			// 每 30 秒输出一次等待进度
+			if checks%6 == 0 {
+				c.logger.Info("等待 Discovery 同步中...",
+					"已等待", c.clock.Now().Sub(startTime),
+					"当前已获取 job 数量", 0,
+					"说明", "Discovery 正在从 Jenkins 获取 job 列表，请稍候...",
+				)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+			// 继续等待
+		}
+	}
+
+	c.logger.Warn("等待 Discovery 同步超时",
+		"等待时间", c.clock.Now().Sub(startTime),
+		"最大等待时间", maxWaitTime,
+		"提示", "如果数据库仍然为空，请检查 Discovery 日志或 Jenkins 连接。Discovery 可能需要更长时间来获取大量 job。",
+	)
+
+	return nil
+}
+
 // collectOnceAsync performs a single collection cycle asynchronously.
 // It processes jobs in batches concurrently.
 func (c *BuildCollector) collectOnceAsync(ctx context.Context) error {
@@ -236,7 +515,7 @@ func (c *BuildCollector) collectOnceAsync(ctx context.Context) error {
 	defer func() {
 		c.collectMutex.Lock()
 		c.collecting = false
-		c.lastCollectTime = time.Now()
+		c.lastCollectTime = c.clock.Now()
 		c.collectMutex.Unlock()
 
 		// 如果是首次采集，发送完成信号
@@ -246,31 +525,74 @@ func (c *BuildCollector) collectOnceAsync(ctx context.Context) error {
 		}
 	}()
 
-	return c.collectOnce(ctx)
+	if c.maxCollectionTime <= 0 {
+		return c.collectOnce(ctx)
+	}
+
+	// 超过最长采集时间后取消采集，已经更新的指标保留
+	cycleCtx, cancel := context.WithTimeout(ctx, c.maxCollectionTime)
+	defer cancel()
+
+	err := c.collectOnce(cycleCtx)
+
+	if errors.Is(cycleCtx.Err(), context.DeadlineExceeded) {
+		c.timeoutsCounter.Inc()
+		c.logger.Warn("采集超过最长时间，已取消",
+			"最长采集时间", c.maxCollectionTime,
+		)
+	}
+
+	return err
 }
 
-// isExcludedFolder checks if a job belongs to an excluded folder.
-func isExcludedFolder(jobName string) bool {
-	excludedFolders := map[string]bool{
-		"prod-ebpay-new":  true,
-		"pre-ebpay-new":   true,
-		"prod-gray-ebpay": true,
+// deleteJobMetrics removes all metrics of the job, the caller has to hold the
+// metrics lock.
+func (c *BuildCollector) deleteJobMetrics(jobName string) {
+	c.deleteBuildResult(jobName)
+	c.resultSeries.release(jobName)
+	c.neverBuiltGauge.DeleteLabelValues(jobName)
+	c.pendingGauge.DeleteLabelValues(jobName)
+	c.buildStatusGauge.DeleteLabelValues(jobName)
+	c.durationGauge.DeleteLabelValues(jobName)
+	c.configChanged.DeleteLabelValues(jobName)
+	c.panicsCounter.DeleteLabelValues(jobName)
+}
+
+// newExcludedFolders converts the configured excluded folders into a lookup
+// set, the values get parsed the same way as the folders to collect.
+func newExcludedFolders(folders []string) map[string]bool {
+	result := make(map[string]bool, len(folders))
+
+	for _, folder := range GetJobNamesFromFolders(strings.Join(folders, ",")) {
+		result[folder] = true
 	}
 
-	// 检查 job 路径的第一部分（顶层文件夹）是否在排除列表中
-	parts := strings.Split(jobName, "/")
-	if len(parts) > 0 {
-		topLevelFolder := parts[0]
-		return excludedFolders[topLevelFolder]
+	return result
+}
+
+// isExcludedFolder checks if a job belongs to an excluded folder, only the
+// top-level folder of the path is matched.
+func isExcludedFolder(jobName string, excludedFolders map[string]bool) bool {
+	topLevelFolder, _, _ := strings.Cut(jobName, "/")
+	return excludedFolders[topLevelFolder]
+}
+
+// IsExcludedJob checks if the job is a top-level job (not within a folder)
+// whose name is part of the excluded jobs.
+func IsExcludedJob(jobName string, excludedJobs []string) bool {
+	if strings.Contains(jobName, "/") {
+		return false
 	}
-	return false
+
+	return slices.Contains(excludedJobs, jobName)
 }
 
 // collectOnce performs a single collection cycle.
 func (c *BuildCollector) collectOnce(ctx context.Context) error {
 	c.logger.Info("开始采集构建结果")
 
-	// 从 SQLite 读取 enabled=1 的 job
+	// 从 SQLite 读取 enabled=1 的 job，在事务中读取一次
+	// 本轮采集期间 Discovery 的变更不会影响正在采集的 job 列表
 	jobs, err := c.repo.ListEnabledJobs()
 	if err != nil {
 		return fmt.Errorf("failed to list enabled jobs: %w", err)
@@ -297,13 +619,13 @@ func (c *BuildCollector) collectOnce(ctx context.Context) error {
 	excludedCount := 0
 	c.mu.Lock()
 	for _, job := range jobs {
-		if isExcludedFolder(job.JobName) {
+		if c.isExcluded(job.JobName) {
 			excludedCount++
 			c.logger.Debug("跳过排除的文件夹下的 job，删除其指标",
 				"job_name", job.JobName,
 			)
 			// 删除被排除的 job 的所有指标
-			c.buildResultGauge.DeletePartialMatch(prometheus.Labels{"job_name": job.JobName})
+			c.deleteJobMetrics(job.JobName)
 			continue
 		}
 		filteredJobs = append(filteredJobs, job)
@@ -342,6 +664,11 @@ func (c *BuildCollector) collectOnce(ctx context.Context) error {
 		"说明", "job 列表已从数据库读取，现在异步批量获取构建信息",
 	)
 
+	// 优先采集的 job 先分发，采集超时时这些 job 的指标也是最新的
+	jobs = PrioritizeJobs(jobs, func(job storage.Job) string {
+		return jobPathFromSDK(job.JobName)
+	}, c.priorityJobs)
+
 	// 异步批量处理 job（使用 goroutine 池）
 	semaphore := make(chan struct{}, c.concurrency)
 	var wg sync.WaitGroup
@@ -349,12 +676,12 @@ func (c *BuildCollector) collectOnce(ctx context.Context) error {
 
 	// 启动 goroutine 处理每个 job
 	for _, job := range jobs {
+		// 获取信号量（控制并发数），在分发时获取以保证按列表顺序处理
+		semaphore <- struct{}{}
+
 		wg.Add(1)
 		go func(j storage.Job) {
 			defer wg.Done()
-
-			// 获取信号量（控制并发数）
-			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
 			// 检查 context 是否已取消
@@ -362,7 +689,7 @@ func (c *BuildCollector) collectOnce(ctx context.Context) error {
 				return
 			}
 
-			result, err := c.processJob(ctx, j)
+			result, err := c.processJobSafe(ctx, j)
 			resultChan <- &jobProcessResult{
 				job:    j,
 				result: result,
@@ -377,11 +704,14 @@ func (c *BuildCollector) collectOnce(ctx context.Context) error {
 		close(resultChan)
 	}()
 
+	// 构建编号或状态变化的 job，采集结束时在一个事务中写入 SQLite
+	updates := make(map[string]storage.BuildUpdate)
+
 	// 收集结果
 	for res := range resultChan {
 		if res.err != nil {
-			// 如果是 context canceled，不记录为错误（优雅关闭）
-			if ctx.Err() == context.Canceled {
+			// 如果 context 已取消或超时，不记录为错误（优雅关闭）
+			if ctx.Err() != nil {
 				c.logger.Debug("采集被取消，停止处理",
 					"job_name", res.job.JobName,
 				)
@@ -399,9 +729,14 @@ func (c *BuildCollector) collectOnce(ctx context.Context) error {
 
 		// 根据处理结果统计
 		if res.result != nil {
+			// 状态变化时也要保存，例如重新运行的构建或 building 状态结束
+			if update, ok := buildUpdate(res.job, res.result); ok {
+				updates[res.job.JobName] = update
+			}
+
 			if res.result.Updated {
 				updatedCount++
-				c.logger.Debug("已更新 job 构建信息",
+				c.logger.Log(ctx, LevelTrace, "已更新 job 构建信息",
 					"job_name", res.job.JobName,
 					"构建编号", res.result.BuildNumber,
 					"上次构建编号", res.job.LastSeenBuild,
@@ -411,7 +746,7 @@ func (c *BuildCollector) collectOnce(ctx context.Context) error {
 				)
 			} else {
 				skippedCount++
-				c.logger.Debug("job 构建未变化（已处理过）",
+				c.logger.Log(ctx, LevelTrace, "job 构建未变化（已处理过）",
 					"job_name", res.job.JobName,
 					"当前构建编号", res.result.BuildNumber,
 					"上次构建编号", res.job.LastSeenBuild,
@@ -426,7 +761,7 @@ func (c *BuildCollector) collectOnce(ctx context.Context) error {
 			}
 		} else {
 			noBuildCount++
-			c.logger.Debug("job 没有已完成的构建",
+			c.logger.Log(ctx, LevelTrace, "job 没有已完成的构建",
 				"job_name", res.job.JobName,
 			)
 		}
@@ -443,6 +778,14 @@ func (c *BuildCollector) collectOnce(ctx context.Context) error {
 		}
 	}
 
+	// 采集被取消时也保存已处理的 job，避免下一轮重复标记为已更新
+	if err := c.repo.UpdateLastSeenBatch(updates); err != nil {
+		c.logger.Warn("批量更新构建信息失败",
+			"job 数量", len(updates),
+			"错误", err,
+		)
+	}
+
 	// 注意：我们不在采集结束时清理指标，因为：
 	// 1. 每个 job 在处理时都会更新对应的指标（使用 DeletePartialMatch 删除旧指标）
 	// 2. 如果某个 job 不再存在，它的指标会在下次采集时自然消失（因为不会更新）
@@ -455,6 +798,11 @@ func (c *BuildCollector) collectOnce(ctx context.Context) error {
 		validJobNames[job.JobName] = true
 	}
 
+	// 不再启用的 job 不再占用构建结果指标的序列名额
+	c.updateMetrics(func() {
+		c.resultSeries.retain(validJobNames)
+	})
+
 	// 注意：Prometheus GaugeVec 没有直接的方法获取所有指标
 	// 但我们可以通过其他方式处理：在处理每个 job 时更新指标，不在列表中的自然会被覆盖或保留
 	// 实际上，由于我们在处理每个 job 时使用 DeletePartialMatch 删除旧指标，然后设置新指标
@@ -472,6 +820,13 @@ func (c *BuildCollector) collectOnce(ctx context.Context) error {
 		"说明", fmt.Sprintf("已更新=%d 表示构建编号有变化（build_number > last_seen_build），最近有构建=%d 表示有已完成构建的 job 数量，排除=%d 表示被过滤掉的 job 数量", updatedCount, recentBuildCount, excludedCount),
 	)
 
+	// 处理结果已保存到数据库，重新按状态统计 job 数量
+	if err := c.updateStatusTally(); err != nil {
+		c.logger.Warn("按状态统计 job 数量失败",
+			"错误", err,
+		)
+	}
+
 	// 如果没有任何 job 被处理，记录警告
 	if processedCount == 0 && len(filteredJobs) > 0 {
 		c.logger.Warn("没有 job 被处理，可能的原因：所有 job 都没有已完成的构建，或者采集被中断",
@@ -490,6 +845,26 @@ type ProcessResult struct {
 	Status      string
 	CommitID    string
 	Branch      string
+	Timestamp   int64 // 构建开始时间，unix 时间戳
+}
+
+// buildUpdate returns the changes to store for the processed job, nothing has
+// to be stored if neither the build number nor the status changed.
+func buildUpdate(job storage.Job, result *ProcessResult) (storage.BuildUpdate, bool) {
+	if !result.Updated && result.Status == job.LastStatus {
+		return storage.BuildUpdate{}, false
+	}
+
+	update := storage.BuildUpdate{
+		Status:    result.Status,
+		Timestamp: result.Timestamp,
+	}
+
+	if result.Updated {
+		update.LastSeenBuild = result.BuildNumber
+	}
+
+	return update, true
 }
 
 // jobProcessResult contains the result of processing a job in async mode.
@@ -502,8 +877,8 @@ type jobProcessResult struct {
 // processJob processes a single job and updates metrics if needed.
 // Returns ProcessResult if successful, nil if no build, error on failure.
 func (c *BuildCollector) processJob(ctx context.Context, job storage.Job) (*ProcessResult, error) {
-	// 初始化 SDK（如果尚未初始化）
-	if err := c.client.InitSDK(c.logger); err != nil {
+	// 初始化 SDK（如果尚未初始化），多次失败后回退到 REST 客户端
+	if err := c.client.InitBackend(c.logger); err != nil {
 		return nil, fmt.Errorf("failed to initialize SDK: %w", err)
 	}
 
@@ -512,15 +887,45 @@ func (c *BuildCollector) processJob(ctx context.Context, job storage.Job) (*Proc
 		return nil, ctx.Err()
 	}
 
+	useSDK := c.client.UseSDK()
+
+	if c.configChanges {
+		c.trackConfigChange(ctx, job.JobName, useSDK)
+	}
+
 	// 使用 SDK 获取 job 的 lastCompletedBuild
 	// job.JobName 应该是完整路径（从 SQLite 读取的，由 Discovery 阶段使用 job.GetName() 获取的完整路径）
 	// 例如："folder/job" 或 "folder/subfolder/job"，如果是顶层 job 就是 "job"
-	c.logger.Debug("使用完整路径获取构建信息",
+	c.logger.Log(ctx, LevelTrace, "使用完整路径获取构建信息",
 		"job_name", job.JobName,
 		"说明", "使用从 SQLite 读取的完整路径（由 Discovery 阶段使用 job.GetName() 获取）",
 	)
 
-	sdkBuild, buildNumber, err := c.client.SDK.GetLastCompletedBuild(ctx, job.JobName)
+	lastCompletedBuild := c.sdkLastCompletedBuild
+	if !useSDK {
+		lastCompletedBuild = c.restLastCompletedBuild
+	}
+
+	buildDetails, buildNumber, err := lastCompletedBuild(ctx, job.JobName)
+	if errors.Is(err, ErrNeverBuilt) {
+		// job 存在但从未构建过，单独标记，避免和请求错误混淆
+		c.updateMetrics(func() {
+			c.deleteBuildResult(job.JobName)
+			c.durationGauge.DeleteLabelValues(job.JobName)
+			c.neverBuiltGauge.WithLabelValues(job.JobName).Set(1.0)
+
+			// 新 job 不计入任何构建状态，避免影响按状态分组的看板
+			if c.pendingFirst {
+				c.pendingGauge.WithLabelValues(job.JobName).Set(1.0)
+				c.buildStatusGauge.DeleteLabelValues(job.JobName)
+				return
+			}
+
+			c.setBuildResult(job.JobName, "", "", "not_built", "", nil)
+			c.buildStatusGauge.WithLabelValues(job.JobName).Set(BuildStatusValue("not_built"))
+		})
+		return nil, nil
+	}
 	if err != nil {
 		// 如果是 context canceled，直接返回，不包装错误
 		if errors.Is(err, context.Canceled) || strings.Contains(err.Error(), "context canceled") {
@@ -543,24 +948,70 @@ func (c *BuildCollector) processJob(ctx context.Context, job storage.Job) (*Proc
 		return nil, fmt.Errorf("failed to get last completed build: %w", err)
 	}
 
+	// 迁移前遗留的构建编号没有意义，和没有构建一样处理
+	if buildDetails != nil && c.belowMinBuild(buildNumber) {
+		c.logger.Log(ctx, LevelTrace, "构建编号小于最小构建编号，跳过该构建",
+			"job_name", job.JobName,
+			"构建编号", buildNumber,
+			"最小构建编号", c.minBuild,
+		)
+
+		buildDetails = nil
+	}
+
 	// 如果没有 completed build，跳过
-	if sdkBuild == nil {
+	if buildDetails == nil {
 		// 即使没有构建，也要更新指标为 not_built 状态
-		c.mu.Lock()
-		c.buildResultGauge.DeletePartialMatch(prometheus.Labels{"job_name": job.JobName})
-		c.buildResultGauge.WithLabelValues(
-			job.JobName,
-			"", // check_commitID
-			"", // gitBranch
-			"not_built",
-		).Set(1.0)
-		c.mu.Unlock()
+		c.updateMetrics(func() {
+			c.setBuildResult(job.JobName, "", "", "not_built", "", nil)
+			c.neverBuiltGauge.DeleteLabelValues(job.JobName)
+			c.pendingGauge.DeleteLabelValues(job.JobName)
+			c.durationGauge.DeleteLabelValues(job.JobName)
+			c.buildStatusGauge.WithLabelValues(job.JobName).Set(BuildStatusValue("not_built"))
+		})
 		return nil, nil // 返回 nil 表示没有构建
 	}
 
+	// 解析构建结果
+	status := parseBuildStatus(buildDetails.Result, buildDetails.Building, buildDetails.Duration, c.buildingResults, c.emptyResult)
+	checkCommitID, gitBranch := commitAndBranch(buildDetails.Parameters)
+
+	// 创建结果信息
+	result := &ProcessResult{
+		BuildNumber: buildNumber,
+		Status:      status,
+		CommitID:    checkCommitID,
+		Branch:      gitBranch,
+		Timestamp:   buildDetails.Timestamp,
+		Updated:     buildNumber > job.LastSeenBuild, // 只有构建编号变化时才标记为已更新
+	}
+
+	// 更新指标（无论是否变化都要更新，以反映当前状态）
+	c.updateMetrics(func() {
+		// 替换该 job 的构建结果指标
+		c.setBuildResult(job.JobName, checkCommitID, gitBranch, status, buildDetails.Result, buildDetails.Parameters)
+		c.neverBuiltGauge.DeleteLabelValues(job.JobName)
+		c.pendingGauge.DeleteLabelValues(job.JobName)
+		c.buildStatusGauge.WithLabelValues(job.JobName).Set(BuildStatusValue(status))
+		// Jenkins 返回的耗时单位为毫秒
+		c.durationGauge.WithLabelValues(job.JobName).Set(float64(buildDetails.Duration) / 1000.0)
+	})
+
+	// 构建信息由 collectOnce 在本轮采集结束时批量写入 SQLite
+	return result, nil
+}
+
+// sdkLastCompletedBuild returns the details of the last completed build using
+// the SDK, details are nil if there is no completed build yet.
+func (c *BuildCollector) sdkLastCompletedBuild(ctx context.Context, jobName string) (*BuildDetails, int64, error) {
+	sdkBuild, buildNumber, err := c.client.SDK.GetLastCompletedBuild(ctx, jobName)
+	if err != nil || sdkBuild == nil {
+		return nil, buildNumber, err
+	}
+
 	// 检查 context 是否已取消
 	if ctx.Err() != nil {
-		return nil, ctx.Err()
+		return nil, 0, ctx.Err()
 	}
 
 	// 获取构建详情（包括参数）
@@ -568,10 +1019,10 @@ func (c *BuildCollector) processJob(ctx context.Context, job storage.Job) (*Proc
 	if err != nil {
 		// 如果是 context canceled，直接返回
 		if errors.Is(err, context.Canceled) || strings.Contains(err.Error(), "context canceled") {
-			return nil, context.Canceled
+			return nil, 0, context.Canceled
 		}
 		c.logger.Warn("获取构建详情失败，使用基本信息",
-			"job_name", job.JobName,
+			"job_name", jobName,
 			"error", err,
 		)
 		// 即使获取详情失败，也使用基本信息
@@ -583,52 +1034,155 @@ func (c *BuildCollector) processJob(ctx context.Context, job storage.Job) (*Proc
 		}
 	}
 
-	// 解析构建结果
-	status := parseBuildStatus(buildDetails.Result, buildDetails.Building)
-	checkCommitID := buildDetails.Parameters["check_commitID"]
-	if checkCommitID == "" {
-		checkCommitID = buildDetails.Parameters["GIT_COMMIT"]
+	return buildDetails, buildNumber, nil
+}
+
+// restLastCompletedBuild returns the details of the last completed build
+// using the REST client, it's used after falling back from the SDK.
+func (c *BuildCollector) restLastCompletedBuild(ctx context.Context, jobName string) (*BuildDetails, int64, error) {
+	build, buildNumber, err := c.client.Job.GetLastCompletedBuild(ctx, jobPathFromSDK(jobName))
+	if err != nil || build == nil {
+		return nil, buildNumber, err
 	}
-	gitBranch := buildDetails.Parameters["gitBranch"]
-	if gitBranch == "" {
-		gitBranch = buildDetails.Parameters["GIT_BRANCH"]
+
+	details := &BuildDetails{
+		Number:     buildNumber,
+		Result:     build.Result,
+		Building:   build.Building,
+		Timestamp:  build.Timestamp / 1000,
+		Duration:   build.Duration,
+		Parameters: make(map[string]string),
 	}
 
-	// 创建结果信息
-	result := &ProcessResult{
-		BuildNumber: buildNumber,
-		Status:      status,
-		CommitID:    checkCommitID,
-		Branch:      gitBranch,
-		Updated:     buildNumber > job.LastSeenBuild, // 只有构建编号变化时才标记为已更新
+	for _, action := range build.Actions {
+		for _, param := range action.Parameters {
+			if param.Name == "" {
+				continue
+			}
+
+			if param.Value == nil {
+				details.Parameters[param.Name] = ""
+				continue
+			}
+
+			details.Parameters[param.Name] = fmt.Sprintf("%v", param.Value)
+		}
+	}
+
+	return details, buildNumber, nil
+}
+
+// trackConfigChange hashes the config.xml of the job and increments the change
+// counter if the hash differs from the stored one. After falling back to REST
+// the config.xml gets fetched by the REST client.
+func (c *BuildCollector) trackConfigChange(ctx context.Context, jobName string, useSDK bool) {
+	var getConfig func(ctx context.Context, jobName string) (string, error)
+
+	if useSDK {
+		getConfig = c.client.SDK.GetJobConfig
+	} else {
+		getConfig = c.restJobConfig
+	}
+
+	config, err := getConfig(ctx, jobName)
+	if err != nil {
+		c.logger.Debug("获取 job 配置失败，跳过配置变更检测",
+			"job_name", jobName,
+			"错误", err,
+		)
+		return
+	}
+
+	sum := sha256.Sum256([]byte(config))
+	changed, err := c.repo.UpdateConfigHash(jobName, hex.EncodeToString(sum[:]))
+	if err != nil {
+		c.logger.Warn("更新 job 配置哈希失败",
+			"job_name", jobName,
+			"错误", err,
+		)
+		return
 	}
 
-	// 更新指标（无论是否变化都要更新，以反映当前状态）
 	c.mu.Lock()
-	// 先删除该 job 的所有旧指标
-	c.buildResultGauge.DeletePartialMatch(prometheus.Labels{"job_name": job.JobName})
-	// 设置新指标
-	c.buildResultGauge.WithLabelValues(
-		job.JobName,
-		checkCommitID,
-		gitBranch,
-		status,
-	).Set(1.0)
-	c.mu.Unlock()
+	defer c.mu.Unlock()
 
-	// 只有构建编号变化时才更新 SQLite
-	if result.Updated {
-		if err := c.repo.UpdateLastSeen(job.JobName, buildNumber); err != nil {
-			return nil, fmt.Errorf("failed to update last_seen_build: %w", err)
+	if changed {
+		c.configChanged.WithLabelValues(jobName).Inc()
+		return
+	}
+
+	// 确保计数器从 0 开始导出，便于 increase() 计算
+	c.configChanged.WithLabelValues(jobName).Add(0)
+}
+
+// restJobConfig fetches the config.xml of the job through the REST client.
+func (c *BuildCollector) restJobConfig(ctx context.Context, jobName string) (string, error) {
+	return c.client.Job.Config(ctx, jobPathFromSDK(jobName))
+}
+
+// IsBuildingResult checks if the result matches one of the configured results
+// that should be treated as in progress.
+func IsBuildingResult(result string, buildingResults []string) bool {
+	if result == "" {
+		return false
+	}
+
+	for _, r := range buildingResults {
+		if strings.EqualFold(result, r) {
+			return true
 		}
 	}
 
-	return result, nil
+	return false
+}
+
+// updateMetrics runs fn while holding the metrics lock, the lock gets released
+// even if fn panics.
+func (c *BuildCollector) updateMetrics(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fn()
+}
+
+// processJobSafe processes a single job and recovers from panics, so one bad
+// job doesn't abort the whole collection.
+func (c *BuildCollector) processJobSafe(ctx context.Context, job storage.Job) (result *ProcessResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.logger.Error("处理 job 时发生 panic，跳过该 job",
+				"job_name", job.JobName,
+				"panic", r,
+				"stack", string(debug.Stack()),
+			)
+
+			c.panicsCounter.WithLabelValues(job.JobName).Inc()
+			result, err = nil, fmt.Errorf("panic while processing job %s: %v", job.JobName, r)
+		}
+	}()
+
+	return c.process(ctx, job)
+}
+
+// commitAndBranch extracts the commit and branch from the build parameters,
+// falling back to the variables set by the git plugin.
+func commitAndBranch(params map[string]string) (string, string) {
+	commit := params["check_commitID"]
+	if commit == "" {
+		commit = params["GIT_COMMIT"]
+	}
+
+	branch := params["gitBranch"]
+	if branch == "" {
+		branch = params["GIT_BRANCH"]
+	}
+
+	return commit, branch
 }
 
 // parseBuildStatus converts build result to status string.
-func parseBuildStatus(result string, building bool) string {
-	if building {
+func parseBuildStatus(result string, building bool, duration int64, buildingResults []string, emptyResult string) string {
+	if building || IsBuildingResult(result, buildingResults) {
 		return "in_progress"
 	}
 
@@ -641,9 +1195,11 @@ func parseBuildStatus(result string, building bool) string {
 		return "aborted"
 	case "UNSTABLE":
 		return "unstable"
+	case "NOT_BUILT":
+		return "not_built"
 	default:
 		if result == "" {
-			return "not_built"
+			return EmptyResultStatus(duration, emptyResult)
 		}
 		return "unknown"
 	}
@@ -0,0 +1,560 @@
+package jenkins
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkinstest"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessJobNeverBuiltAndError(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":           `{"jobs": []}`,
+		"/job/fresh/api/json": `{"_class": "hudson.model.FreeStyleProject", "name": "fresh", "lastBuild": null, "lastCompletedBuild": null}`,
+		"/job/gone/api/json":  `{"_class": "hudson.model.FreeStyleProject", "name": "gone", "lastBuild": {"number": 3}, "lastCompletedBuild": {"number": 3}}`,
+	})
+
+	c := NewBuildCollector(newTestClient(t, srv), newTestRepo(t), testLogger(), 1)
+
+	result, err := c.processJob(context.Background(), storage.Job{JobName: "fresh"})
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.neverBuiltGauge.WithLabelValues("fresh")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.buildResultGauge.WithLabelValues("fresh", "", "", "not_built")))
+
+	// 构建详情请求失败（404）不能被当作从未构建
+	_, _ = c.processJob(context.Background(), storage.Job{JobName: "gone"})
+	assert.Equal(t, 1, testutil.CollectAndCount(c.neverBuiltGauge))
+	assert.Equal(t, 1, testutil.CollectAndCount(c.buildResultGauge))
+}
+
+func TestProcessJobPendingFirstBuild(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":           `{"jobs": []}`,
+		"/job/fresh/api/json": `{"_class": "hudson.model.FreeStyleProject", "name": "fresh", "lastBuild": null, "lastCompletedBuild": null}`,
+	})
+
+	c := NewBuildCollector(newTestClient(t, srv), newTestRepo(t), testLogger(), 1, WithPendingFirstBuild(true))
+
+	result, err := c.processJob(context.Background(), storage.Job{JobName: "fresh"})
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.pendingGauge.WithLabelValues("fresh")))
+	assert.Equal(t, 0, testutil.CollectAndCount(c.buildResultGauge))
+	assert.Equal(t, 0, testutil.CollectAndCount(c.buildStatusGauge))
+}
+
+func TestWaitForDiscoverySurfacesDatabaseErrors(t *testing.T) {
+	db, err := storage.NewSQLite(filepath.Join(t.TempDir(), "jobs.db"), testLogger())
+	assert.NoError(t, err)
+	assert.NoError(t, db.Close())
+
+	c := NewBuildCollector(nil, storage.NewJobRepo(db, testLogger()), testLogger(), 1, WithDiscoveryWaitRetries(2))
+	c.discoveryCheckInterval = time.Millisecond
+
+	err = c.waitForDiscovery(context.Background(), time.Minute)
+	assert.ErrorContains(t, err, "database is closed")
+}
+
+func TestWaitForDiscoveryKeepsWaitingOnEmptyDatabase(t *testing.T) {
+	c := NewBuildCollector(nil, newTestRepo(t), testLogger(), 1, WithDiscoveryWaitRetries(2))
+	c.discoveryCheckInterval = time.Millisecond
+
+	assert.NoError(t, c.waitForDiscovery(context.Background(), 20*time.Millisecond))
+}
+
+func TestProcessJobConfigChanges(t *testing.T) {
+	routes := map[string]string{
+		"/api/json":              `{"jobs": []}`,
+		"/job/app/api/json":      `{"_class": "hudson.model.FreeStyleProject", "name": "app", "lastBuild": null, "lastCompletedBuild": null}`,
+		"/job/app/config.xml/":   `<project><description>v1</description></project>`,
+		"/job/other/api/json":    `{"_class": "hudson.model.FreeStyleProject", "name": "other", "lastBuild": null, "lastCompletedBuild": null}`,
+		"/job/other/config.xml/": `<project><description>v1</description></project>`,
+	}
+	srv := jenkinstest.NewServer(t, routes)
+
+	repo := newTestRepo(t)
+	assert.NoError(t, repo.SyncJobs([]string{"app", "other"}))
+
+	c := NewBuildCollector(newTestClient(t, srv), repo, testLogger(), 1, WithConfigChanges(true))
+
+	collect := func() {
+		for _, name := range []string{"app", "other"} {
+			_, err := c.processJob(context.Background(), storage.Job{JobName: name})
+			assert.NoError(t, err)
+		}
+	}
+
+	collect()
+	collect()
+	assert.Equal(t, 0.0, testutil.ToFloat64(c.configChanged.WithLabelValues("app")))
+
+	routes["/job/app/config.xml/"] = `<project><description>v2</description></project>`
+	collect()
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.configChanged.WithLabelValues("app")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(c.configChanged.WithLabelValues("other")))
+
+	// 回退到 REST 客户端后继续通过 REST 获取 config.xml
+	c.client.useSDK = false
+	routes["/job/app/config.xml"] = `<project><description>v3</description></project>`
+	routes["/job/other/config.xml"] = `<project><description>v1</description></project>`
+	collect()
+	assert.Equal(t, 2.0, testutil.ToFloat64(c.configChanged.WithLabelValues("app")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(c.configChanged.WithLabelValues("other")))
+}
+
+func TestSinceCollectorOnlyChangedJobs(t *testing.T) {
+	repo := newTestRepo(t)
+	assert.NoError(t, repo.SyncJobs([]string{"stale", "recent"}))
+	assert.NoError(t, repo.UpdateLastSeenBatch(map[string]storage.BuildUpdate{"recent": {LastSeenBuild: 12}}))
+
+	c := NewBuildCollector(nil, repo, testLogger(), 1)
+	c.buildResultGauge.WithLabelValues("stale", "", "", "success").Set(1.0)
+	c.buildResultGauge.WithLabelValues("recent", "", "", "failure").Set(1.0)
+
+	expected := `
+# HELP jenkins_build_last_result Last build result: 1 indicates current status, status label contains the actual status (success, failure, aborted, unstable, in_progress, waiting, not_built, unknown)
+# TYPE jenkins_build_last_result gauge
+jenkins_build_last_result{check_commitID="",gitBranch="",job_name="recent",status="failure"} 1
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c.Since(time.Now().Add(-time.Minute)), strings.NewReader(expected)))
+	assert.Equal(t, 0, testutil.CollectAndCount(c.Since(time.Now().Add(time.Minute))))
+}
+
+func TestCollectOnceRecoversPanickingJob(t *testing.T) {
+	repo := newTestRepo(t)
+	assert.NoError(t, repo.SyncJobs([]string{"bad", "good", "other"}))
+
+	c := NewBuildCollector(nil, repo, testLogger(), 2)
+	c.process = func(_ context.Context, job storage.Job) (*ProcessResult, error) {
+		if job.JobName == "bad" {
+			panic("boom")
+		}
+
+		c.updateMetrics(func() {
+			c.buildResultGauge.WithLabelValues(job.JobName, "", "", "success").Set(1.0)
+		})
+
+		return &ProcessResult{BuildNumber: 1, Status: "success"}, nil
+	}
+
+	assert.NoError(t, c.collectOnce(context.Background()))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.panicsCounter.WithLabelValues("bad")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.buildResultGauge.WithLabelValues("good", "", "", "success")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.buildResultGauge.WithLabelValues("other", "", "", "success")))
+}
+
+func TestCollectOnceStableJobSet(t *testing.T) {
+	repo := newTestRepo(t)
+	assert.NoError(t, repo.SyncJobs([]string{"a", "b", "c"}))
+
+	var mu sync.Mutex
+	processed := make([]string, 0)
+
+	c := NewBuildCollector(nil, repo, testLogger(), 1)
+	c.process = func(_ context.Context, job storage.Job) (*ProcessResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// 采集过程中 Discovery 禁用了 c 并新增了 d
+		if len(processed) == 0 {
+			assert.NoError(t, repo.SyncJobs([]string{"a", "b", "d"}))
+		}
+
+		processed = append(processed, job.JobName)
+		return &ProcessResult{BuildNumber: 1, Status: "success"}, nil
+	}
+
+	assert.NoError(t, c.collectOnce(context.Background()))
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, processed)
+
+	// 下一轮采集使用同步后的 job 列表
+	processed = processed[:0]
+	assert.NoError(t, c.collectOnce(context.Background()))
+	assert.ElementsMatch(t, []string{"a", "b", "d"}, processed)
+}
+
+func TestCollectOnceAsyncMaxCollectionTime(t *testing.T) {
+	repo := newTestRepo(t)
+	assert.NoError(t, repo.SyncJobs([]string{"fast", "slow"}))
+
+	c := NewBuildCollector(nil, repo, testLogger(), 2, WithMaxCollectionTime(50*time.Millisecond))
+	c.process = func(ctx context.Context, job storage.Job) (*ProcessResult, error) {
+		if job.JobName == "slow" {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+
+		c.updateMetrics(func() {
+			c.buildResultGauge.WithLabelValues(job.JobName, "", "", "success").Set(1.0)
+		})
+
+		return &ProcessResult{BuildNumber: 1, Status: "success"}, nil
+	}
+
+	start := time.Now()
+	assert.NoError(t, c.collectOnceAsync(context.Background()))
+
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.timeoutsCounter))
+
+	// 超时前已更新的指标保留
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.buildResultGauge.WithLabelValues("fast", "", "", "success")))
+}
+
+func TestTriggerCollectionMinCollectInterval(t *testing.T) {
+	clock := jenkinstest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	c := NewBuildCollector(nil, nil, testLogger(), 1, WithMinCollectInterval(time.Minute))
+	c.clock = clock
+	c.lastCollectTime = clock.Now()
+
+	// 最小间隔内的触发被跳过
+	clock.Advance(30 * time.Second)
+	c.triggerCollectionIfNeeded()
+	assert.Len(t, c.collectTrigger, 0)
+
+	// 超过最小间隔后触发采集
+	clock.Advance(31 * time.Second)
+	c.triggerCollectionIfNeeded()
+	assert.Len(t, c.collectTrigger, 1)
+}
+
+func TestMinCollectIntervalDefault(t *testing.T) {
+	c := NewBuildCollector(nil, nil, testLogger(), 1, WithMinCollectInterval(0))
+	assert.Equal(t, 5*time.Second, c.minCollectInterval)
+}
+
+func TestProcessJobUnknownBuildStatus(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":               `{"jobs": []}`,
+		"/job/app/api/json":       `{"_class": "hudson.model.FreeStyleProject", "name": "app", "lastBuild": {"number": 3, "url": "$URL/job/app/3/"}, "lastCompletedBuild": {"number": 3, "url": "$URL/job/app/3/"}}`,
+		"/job/app/3/api/json":     `{"number": 3, "result": "CUSTOM_RESULT", "building": false}`,
+		"/job/fresh/api/json":     `{"_class": "hudson.model.FreeStyleProject", "name": "fresh", "lastBuild": null, "lastCompletedBuild": null}`,
+		"/job/skipped/api/json":   `{"_class": "hudson.model.FreeStyleProject", "name": "skipped", "lastBuild": {"number": 2, "url": "$URL/job/skipped/2/"}, "lastCompletedBuild": {"number": 2, "url": "$URL/job/skipped/2/"}}`,
+		"/job/skipped/2/api/json": `{"number": 2, "result": "NOT_BUILT", "building": false}`,
+	})
+
+	c := NewBuildCollector(newTestClient(t, srv), newTestRepo(t), testLogger(), 1)
+
+	for _, name := range []string{"app", "fresh", "skipped"} {
+		_, err := c.processJob(context.Background(), storage.Job{JobName: name})
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 7.0, testutil.ToFloat64(c.buildStatusGauge.WithLabelValues("app")))
+	assert.Equal(t, 6.0, testutil.ToFloat64(c.buildStatusGauge.WithLabelValues("fresh")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.buildResultGauge.WithLabelValues("app", "", "", "unknown")))
+
+	// Jenkins 的 NOT_BUILT 结果和从未构建使用相同的状态
+	assert.Equal(t, 6.0, testutil.ToFloat64(c.buildStatusGauge.WithLabelValues("skipped")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.buildResultGauge.WithLabelValues("skipped", "", "", "not_built")))
+}
+
+func TestProcessJobBuildDuration(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":           `{"jobs": []}`,
+		"/job/app/api/json":   `{"_class": "hudson.model.FreeStyleProject", "name": "app", "lastBuild": {"number": 3, "url": "$URL/job/app/3/"}, "lastCompletedBuild": {"number": 3, "url": "$URL/job/app/3/"}}`,
+		"/job/app/3/api/json": `{"number": 3, "result": "SUCCESS", "building": false, "timestamp": 1700000000000, "duration": 90500}`,
+		"/job/fresh/api/json": `{"_class": "hudson.model.FreeStyleProject", "name": "fresh", "lastBuild": null, "lastCompletedBuild": null}`,
+	})
+
+	repo := newTestRepo(t)
+	assert.NoError(t, repo.SyncJobs([]string{"app", "fresh"}))
+
+	c := NewBuildCollector(newTestClient(t, srv), repo, testLogger(), 1)
+
+	for _, name := range []string{"app", "fresh"} {
+		_, err := c.processJob(context.Background(), storage.Job{JobName: name})
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 90.5, testutil.ToFloat64(c.durationGauge.WithLabelValues("app")))
+	assert.Equal(t, 1, testutil.CollectAndCount(c.durationGauge))
+
+	// 被排除的 job 的耗时指标同样被删除
+	WithExcludedJobs([]string{"app"})(c)
+	assert.NoError(t, c.collectOnceAsync(context.Background()))
+	assert.Equal(t, 0, testutil.CollectAndCount(c.durationGauge))
+}
+
+func TestProcessJobEmptyResultStatus(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":             `{"jobs": []}`,
+		"/job/pipe/api/json":    `{"_class": "org.jenkinsci.plugins.workflow.job.WorkflowJob", "name": "pipe", "lastBuild": {"number": 4, "url": "$URL/job/pipe/4/"}, "lastCompletedBuild": {"number": 4, "url": "$URL/job/pipe/4/"}}`,
+		"/job/pipe/4/api/json":  `{"number": 4, "result": null, "building": false, "timestamp": 1700000000000, "duration": 1200}`,
+		"/job/never/api/json":   `{"_class": "org.jenkinsci.plugins.workflow.job.WorkflowJob", "name": "never", "lastBuild": {"number": 1, "url": "$URL/job/never/1/"}, "lastCompletedBuild": {"number": 1, "url": "$URL/job/never/1/"}}`,
+		"/job/never/1/api/json": `{"number": 1, "result": null, "building": false, "duration": 0}`,
+	})
+
+	c := NewBuildCollector(newTestClient(t, srv), newTestRepo(t), testLogger(), 1, WithEmptyResultStatus("aborted"))
+
+	for _, name := range []string{"pipe", "never"} {
+		_, err := c.processJob(context.Background(), storage.Job{JobName: name})
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(c.buildStatusGauge.WithLabelValues("pipe")))
+	assert.Equal(t, 6.0, testutil.ToFloat64(c.buildStatusGauge.WithLabelValues("never")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.buildResultGauge.WithLabelValues("pipe", "", "", "aborted")))
+}
+
+func TestProcessJobPersistsBuildInfo(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":           `{"jobs": []}`,
+		"/job/app/api/json":   `{"_class": "hudson.model.FreeStyleProject", "name": "app", "lastBuild": {"number": 3, "url": "$URL/job/app/3/"}, "lastCompletedBuild": {"number": 3, "url": "$URL/job/app/3/"}}`,
+		"/job/app/3/api/json": `{"number": 3, "result": "FAILURE", "building": false, "timestamp": 1700000000000, "duration": 1000}`,
+	})
+
+	repo := newTestRepo(t)
+	assert.NoError(t, repo.SyncJobs([]string{"app"}))
+
+	c := NewBuildCollector(newTestClient(t, srv), repo, testLogger(), 1)
+
+	assert.NoError(t, c.collectOnce(context.Background()))
+
+	jobs, err := repo.ListEnabledJobs()
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, int64(3), jobs[0].LastSeenBuild)
+	assert.Equal(t, "failure", jobs[0].LastStatus)
+
+	if assert.NotNil(t, jobs[0].LastBuildStartedAt) {
+		assert.Equal(t, int64(1700000000), jobs[0].LastBuildStartedAt.Unix())
+	}
+}
+
+func TestSeedFromRepo(t *testing.T) {
+	repo := newTestRepo(t)
+	assert.NoError(t, repo.SyncJobs([]string{"app", "gone", "fresh"}))
+	assert.NoError(t, repo.UpdateLastSeenBatch(map[string]storage.BuildUpdate{
+		"app":  {Status: "failure", Timestamp: 1700000000},
+		"gone": {Status: "success", Timestamp: 1700000000},
+	}))
+
+	// gone 已被 Discovery 禁用，不应该恢复它的状态
+	assert.NoError(t, repo.SyncJobs([]string{"app", "fresh"}))
+
+	c := NewBuildCollector(nil, repo, testLogger(), 1)
+
+	seeded, err := c.seedFromRepo()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, seeded)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.buildResultGauge.WithLabelValues("app", "", "", "failure")))
+	assert.Equal(t, BuildStatusValue("failure"), testutil.ToFloat64(c.buildStatusGauge.WithLabelValues("app")))
+	assert.Equal(t, 1, testutil.CollectAndCount(c.buildStatusGauge))
+}
+
+func TestUpdateStatusTallyMaxAge(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	repo := newTestRepo(t)
+	assert.NoError(t, repo.SyncJobs([]string{"recent", "old", "fresh"}))
+	assert.NoError(t, repo.UpdateLastSeenBatch(map[string]storage.BuildUpdate{
+		"recent": {Status: "failure", Timestamp: now.Add(-time.Hour).Unix()},
+		"old":    {Status: "failure", Timestamp: now.Add(-90 * 24 * time.Hour).Unix()},
+	}))
+
+	c := NewBuildCollector(nil, repo, testLogger(), 1, WithStatusMaxAge(30*24*time.Hour))
+	c.clock = jenkinstest.NewClock(now)
+
+	assert.NoError(t, c.updateStatusTally())
+
+	// old 的最后构建超过最大时长，不计入统计
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.statusTally.WithLabelValues("failure")))
+	assert.Equal(t, 1, testutil.CollectAndCount(c.statusTally))
+
+	// 不限制时长时所有有状态的 job 都计入统计
+	c.statusMaxAge = 0
+	assert.NoError(t, c.updateStatusTally())
+	assert.Equal(t, 2.0, testutil.ToFloat64(c.statusTally.WithLabelValues("failure")))
+}
+
+func TestProcessJobResultLabel(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":           `{"jobs": []}`,
+		"/job/app/api/json":   `{"_class": "hudson.model.FreeStyleProject", "name": "app", "lastBuild": {"number": 3, "url": "$URL/job/app/3/"}, "lastCompletedBuild": {"number": 3, "url": "$URL/job/app/3/"}}`,
+		"/job/app/3/api/json": `{"number": 3, "result": "UNSTABLE", "building": false, "timestamp": 1700000000000, "duration": 1000}`,
+	})
+
+	c := NewBuildCollector(newTestClient(t, srv), newTestRepo(t), testLogger(), 1, WithResultLabel(true))
+
+	_, err := c.processJob(context.Background(), storage.Job{JobName: "app"})
+	assert.NoError(t, err)
+
+	expected := `
+# HELP jenkins_build_last_result ` + BuildLastResultHelp + `
+# TYPE jenkins_build_last_result gauge
+jenkins_build_last_result{check_commitID="",gitBranch="",job_name="app",result="UNSTABLE",status="unstable"} 1
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c.buildResultGauge, strings.NewReader(expected)))
+}
+
+func TestProcessJobMinBuildNumber(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":             `{"jobs": []}`,
+		"/job/old/api/json":     `{"_class": "hudson.model.FreeStyleProject", "name": "old", "lastBuild": {"number": 3, "url": "$URL/job/old/3/"}, "lastCompletedBuild": {"number": 3, "url": "$URL/job/old/3/"}}`,
+		"/job/old/3/api/json":   `{"number": 3, "result": "FAILURE", "building": false, "timestamp": 1700000000000, "duration": 1000}`,
+		"/job/new/api/json":     `{"_class": "hudson.model.FreeStyleProject", "name": "new", "lastBuild": {"number": 120, "url": "$URL/job/new/120/"}, "lastCompletedBuild": {"number": 120, "url": "$URL/job/new/120/"}}`,
+		"/job/new/120/api/json": `{"number": 120, "result": "SUCCESS", "building": false, "timestamp": 1700000000000, "duration": 1000}`,
+	})
+
+	repo := newTestRepo(t)
+	assert.NoError(t, repo.SyncJobs([]string{"new", "old"}))
+
+	c := NewBuildCollector(newTestClient(t, srv), repo, testLogger(), 1, WithMinBuildNumber(100))
+
+	result, err := c.processJob(context.Background(), storage.Job{JobName: "old"})
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+
+	result, err = c.processJob(context.Background(), storage.Job{JobName: "new"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(120), result.BuildNumber)
+
+	expected := `
+# HELP jenkins_build_last_result ` + BuildLastResultHelp + `
+# TYPE jenkins_build_last_result gauge
+jenkins_build_last_result{check_commitID="",gitBranch="",job_name="new",status="success"} 1
+jenkins_build_last_result{check_commitID="",gitBranch="",job_name="old",status="not_built"} 1
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c.buildResultGauge, strings.NewReader(expected)))
+}
+
+func TestCollectOncePriorityJobs(t *testing.T) {
+	repo := newTestRepo(t)
+	assert.NoError(t, repo.SyncJobs([]string{"a", "b", "team/job/app", "z"}))
+
+	var mu sync.Mutex
+	processed := make([]string, 0)
+
+	c := NewBuildCollector(nil, repo, testLogger(), 1, WithPriorityJobs([]string{"z", "team/"}))
+	c.process = func(_ context.Context, job storage.Job) (*ProcessResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		processed = append(processed, job.JobName)
+		return &ProcessResult{BuildNumber: 1, Status: "success"}, nil
+	}
+
+	assert.NoError(t, c.collectOnce(context.Background()))
+	assert.Equal(t, []string{"z", "team/job/app", "a", "b"}, processed)
+}
+
+// walFrames returns the number of frames within the write-ahead log, every
+// committed transaction appends at least one frame.
+func walFrames(b *testing.B, db *sql.DB) int {
+	b.Helper()
+
+	var busy, frames, checkpointed int
+
+	if err := db.QueryRow("PRAGMA wal_checkpoint(PASSIVE)").Scan(&busy, &frames, &checkpointed); err != nil {
+		b.Fatal(err)
+	}
+
+	return frames
+}
+
+func BenchmarkCollectOnce(b *testing.B) {
+	// 每个 job 在单独的事务中写入，和批量写入之前的行为一致
+	perJob := func(repo *storage.JobRepo, job storage.Job, result *ProcessResult) error {
+		update, _ := buildUpdate(job, result)
+		return repo.UpdateLastSeenBatch(map[string]storage.BuildUpdate{job.JobName: update})
+	}
+
+	for _, tc := range []struct {
+		name  string
+		write func(repo *storage.JobRepo, job storage.Job, result *ProcessResult) error
+	}{
+		{name: "batch"},
+		{name: "per-job", write: perJob},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			db, err := storage.NewSQLite(filepath.Join(b.TempDir(), "jobs.db"), testLogger())
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.Cleanup(func() { _ = db.Close() })
+
+			repo := storage.NewJobRepo(db, testLogger())
+
+			names := make([]string, 0, 1000)
+			for i := range 1000 {
+				names = append(names, fmt.Sprintf("team/job/app-%04d", i))
+			}
+
+			if err := repo.SyncJobs(names); err != nil {
+				b.Fatal(err)
+			}
+
+			// 禁用自动 checkpoint，每轮开始前清空 WAL，以便统计写入的帧数
+			if _, err := db.Exec("PRAGMA wal_autocheckpoint = 0"); err != nil {
+				b.Fatal(err)
+			}
+
+			c := NewBuildCollector(nil, repo, testLogger(), 10)
+
+			// 每一轮所有 job 的构建编号和状态都发生变化
+			c.process = func(_ context.Context, job storage.Job) (*ProcessResult, error) {
+				result := &ProcessResult{
+					BuildNumber: job.LastSeenBuild + 1,
+					Status:      "success",
+					Timestamp:   1700000000 + job.LastSeenBuild,
+					Updated:     true,
+				}
+
+				if job.LastStatus == "success" {
+					result.Status = "failure"
+				}
+
+				if tc.write == nil {
+					return result, nil
+				}
+
+				if err := tc.write(repo, job, result); err != nil {
+					return nil, err
+				}
+
+				// 已经写入，collectOnce 不再批量写入
+				result.Updated = false
+				result.Status = job.LastStatus
+
+				return result, nil
+			}
+
+			frames := 0
+			b.ResetTimer()
+
+			for range b.N {
+				b.StopTimer()
+				if _, err := db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+					b.Fatal(err)
+				}
+				b.StartTimer()
+
+				if err := c.collectOnce(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+
+				b.StopTimer()
+				frames += walFrames(b, db)
+				b.StartTimer()
+			}
+
+			b.ReportMetric(float64(frames)/float64(b.N), "wal-frames/op")
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package jenkins
+
+import (
+	"context"
+	"fmt"
+)
+
+// computerTree limits the computer response to the fields of Computer, the
+// fields of the monitor results have to be requested explicitly, otherwise
+// Jenkins only returns their _class.
+const computerTree = "computer[_class,displayName,offline,offlineCauseReason,idle,numExecutors,executors[idle],assignedLabels[name],monitorData[*[size,path,average]]]"
+
+// ComputerClient is a client for the computer API.
+type ComputerClient struct {
+	client *Client
+}
+
+// All returns all agents known to Jenkins, only the required fields get
+// requested to keep the response small on large instances.
+func (c *ComputerClient) All(ctx context.Context) (Computers, error) {
+	result := Computers{}
+	req, err := c.client.NewRequest(ctx, "GET", fmt.Sprintf("%s/computer/api/json?tree=%s", c.client.endpoint, computerTree), nil)
+
+	if err != nil {
+		return result, err
+	}
+
+	if _, err := c.client.Do(req, &result); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
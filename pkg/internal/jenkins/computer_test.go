@@ -0,0 +1,19 @@
+package jenkins
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputerAllRequestsTree(t *testing.T) {
+	var tree string
+	client := newTreeTestServer(t, `{"computer": [{"_class": "hudson.slaves.SlaveComputer", "displayName": "agent-1", "offline": true, "offlineCauseReason": "disconnected"}]}`, &tree)
+
+	computers, err := client.Computer.All(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, "computer[_class,displayName,offline,offlineCauseReason,idle,numExecutors,executors[idle],assignedLabels[name],monitorData[*[size,path,average]]]", tree)
+	assert.Equal(t, []Computer{{Class: "hudson.slaves.SlaveComputer", Name: "agent-1", Offline: true, OfflineCauseReason: "disconnected"}}, computers.Computers)
+}
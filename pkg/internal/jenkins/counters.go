@@ -0,0 +1,90 @@
+package jenkins
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/storage"
+)
+
+// WithPersistCounters configures the counters to be persisted within the
+// database on shutdown and restored on startup, so they stay monotonic across
+// restarts.
+func WithPersistCounters(enabled bool) BuildCollectorOption {
+	return func(c *BuildCollector) {
+		c.persistCounters = enabled
+	}
+}
+
+// counterVecs returns the persisted counters labeled by job_name.
+func (c *BuildCollector) counterVecs() map[string]*prometheus.CounterVec {
+	return map[string]*prometheus.CounterVec{
+		"jenkins_job_config_changed_total": c.configChanged,
+		"jenkins_collector_panics_total":   c.panicsCounter,
+	}
+}
+
+// saveCounters persists the current counter values within the database.
+func (c *BuildCollector) saveCounters() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var counters []storage.Counter
+
+	for name, vec := range c.counterVecs() {
+		counters = append(counters, counterValues(name, vec)...)
+	}
+
+	counters = append(counters, counterValues("jenkins_collection_timeouts_total", c.timeoutsCounter)...)
+
+	return c.repo.SaveCounters(counters)
+}
+
+// restoreCounters adds the persisted counter values to the counters.
+func (c *BuildCollector) restoreCounters() error {
+	counters, err := c.repo.ListCounters()
+	if err != nil {
+		return err
+	}
+
+	vecs := c.counterVecs()
+
+	c.updateMetrics(func() {
+		for _, counter := range counters {
+			if vec, ok := vecs[counter.Name]; ok {
+				vec.WithLabelValues(counter.Label).Add(counter.Value)
+				continue
+			}
+
+			if counter.Name == "jenkins_collection_timeouts_total" {
+				c.timeoutsCounter.Add(counter.Value)
+			}
+		}
+	})
+
+	return nil
+}
+
+// counterValues reads the values of all counters exported by the collector.
+func counterValues(name string, collector prometheus.Collector) []storage.Counter {
+	metrics := make(chan prometheus.Metric)
+	go func() {
+		collector.Collect(metrics)
+		close(metrics)
+	}()
+
+	var counters []storage.Counter
+	for metric := range metrics {
+		m := &dto.Metric{}
+		if err := metric.Write(m); err != nil || m.GetCounter() == nil {
+			continue
+		}
+
+		counters = append(counters, storage.Counter{
+			Name:  name,
+			Label: jobNameOf(metric),
+			Value: m.GetCounter().GetValue(),
+		})
+	}
+
+	return counters
+}
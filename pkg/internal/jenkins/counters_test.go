@@ -0,0 +1,34 @@
+package jenkins
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountersRestoredAfterRestart(t *testing.T) {
+	repo := newTestRepo(t)
+
+	before := NewBuildCollector(nil, repo, testLogger(), 1, WithPersistCounters(true))
+	before.panicsCounter.WithLabelValues("bad").Add(3)
+	before.timeoutsCounter.Add(2)
+	assert.NoError(t, before.saveCounters())
+
+	// 模拟重启：新的 collector 从数据库恢复计数器
+	after := NewBuildCollector(nil, repo, testLogger(), 1, WithPersistCounters(true))
+	assert.NoError(t, after.restoreCounters())
+
+	assert.Equal(t, 3.0, testutil.ToFloat64(after.panicsCounter.WithLabelValues("bad")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(after.timeoutsCounter))
+	assert.Equal(t, 0, testutil.CollectAndCount(after.configChanged))
+
+	// 恢复后继续单调递增
+	after.panicsCounter.WithLabelValues("bad").Inc()
+	assert.NoError(t, after.saveCounters())
+
+	counters, err := repo.ListCounters()
+	assert.NoError(t, err)
+	assert.Len(t, counters, 2)
+	assert.Equal(t, 4.0, counters[1].Value)
+}
@@ -0,0 +1,158 @@
+package jenkins
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// AuthBasic sends the username and the password or token via basic auth.
+	AuthBasic = "basic"
+
+	// AuthBearer sends the token or password as bearer token, e.g. for
+	// Jenkins behind an OIDC proxy. The username is not used.
+	AuthBearer = "bearer"
+)
+
+// WithAuthMode configures a Client to send the credentials via basic auth or
+// as bearer token, it defaults to basic auth.
+func WithAuthMode(mode string) ClientOption {
+	return func(client *Client) error {
+		switch mode {
+		case "", AuthBasic, AuthBearer:
+			client.authMode = mode
+			return nil
+		}
+
+		return fmt.Errorf("invalid auth mode: %s", mode)
+	}
+}
+
+// CredentialsFunc resolves the current username and password, e.g. from a
+// mounted secret which gets rotated while the exporter is running.
+type CredentialsFunc func() (username, password string, err error)
+
+// WithCredentialsReload configures a Client to resolve the credentials again
+// after an unauthorized response, the request gets repeated once if they
+// changed.
+func WithCredentialsReload(reload CredentialsFunc) ClientOption {
+	return func(client *Client) error {
+		client.reload = reload
+		return nil
+	}
+}
+
+// WithAuthFailures configures a Client to increment the given counter for
+// every request rejected with 401 or 403.
+func WithAuthFailures(failures prometheus.Counter) ClientOption {
+	return func(client *Client) error {
+		client.authFailures = failures
+		return nil
+	}
+}
+
+// credentials returns the current username and password.
+func (c *Client) credentials() (string, string) {
+	c.authMutex.RLock()
+	defer c.authMutex.RUnlock()
+
+	return c.username, c.password
+}
+
+// setAuth attaches the current credentials to the request.
+func (c *Client) setAuth(req *http.Request) {
+	username, password := c.credentials()
+
+	if c.authMode == AuthBearer {
+		if password != "" {
+			req.Header.Set("Authorization", "Bearer "+password)
+		}
+
+		return
+	}
+
+	if username != "" && password != "" {
+		req.SetBasicAuth(
+			username,
+			password,
+		)
+	}
+}
+
+// reloadCredentials resolves the credentials again, it returns true if they
+// differ from the current ones.
+func (c *Client) reloadCredentials() (bool, error) {
+	username, password, err := c.reload()
+
+	if err != nil {
+		return false, err
+	}
+
+	c.authMutex.Lock()
+	defer c.authMutex.Unlock()
+
+	if username == c.username && password == c.password {
+		return false, nil
+	}
+
+	c.username = username
+	c.password = password
+
+	return true, nil
+}
+
+// retryUnauthorized repeats an unauthorized request once with reloaded
+// credentials. The original response gets returned if the credentials can't
+// be reloaded or didn't change.
+func (c *Client) retryUnauthorized(req *http.Request, res *http.Response, do func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if res.StatusCode != http.StatusUnauthorized || c.reload == nil || (req.Body != nil && req.GetBody == nil) {
+		return res, nil
+	}
+
+	// 重新读取失败时保留原始响应，由调用方按 401 处理
+	changed, err := c.reloadCredentials()
+
+	if err != nil || !changed {
+		return res, nil
+	}
+
+	_ = res.Body.Close()
+	retry := req.Clone(req.Context())
+
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+
+	c.setAuth(retry)
+	return do(retry)
+}
+
+// authTransport attaches the current credentials to the requests of the SDK,
+// so reloaded credentials are used by both clients.
+type authTransport struct {
+	base   http.RoundTripper
+	client *Client
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	req = req.Clone(req.Context())
+	t.client.setAuth(req)
+
+	res, err := base.RoundTrip(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return t.client.retryUnauthorized(req, res, base.RoundTrip)
+}
@@ -0,0 +1,118 @@
+package jenkins
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientReloadsRotatedCredentials(t *testing.T) {
+	var unauthorized, reloads atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, password, _ := r.BasicAuth(); password != "rotated" {
+			unauthorized.Add(1)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_, _ = io.WriteString(w, `{"quietingDown": true}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	secret := filepath.Join(t.TempDir(), "password")
+	assert.NoError(t, os.WriteFile(secret, []byte("initial"), 0o600))
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithUsername("admin"),
+		WithPassword("initial"),
+		WithTimeout(5*time.Second),
+		WithCredentialsReload(func() (string, string, error) {
+			reloads.Add(1)
+			content, err := os.ReadFile(secret)
+			return "admin", string(content), err
+		}),
+	)
+	assert.NoError(t, err)
+
+	// secret 在运行期间被轮换
+	assert.NoError(t, os.WriteFile(secret, []byte("rotated"), 0o600))
+
+	status, err := client.Job.Status(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, status.QuietingDown)
+	assert.Equal(t, int32(1), unauthorized.Load())
+	assert.Equal(t, int32(1), reloads.Load())
+
+	// 后续请求直接使用新的凭据
+	_, err = client.Job.Status(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), unauthorized.Load())
+}
+
+func TestClientUnchangedCredentialsNotRetried(t *testing.T) {
+	var requests atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithUsername("admin"),
+		WithPassword("initial"),
+		WithTimeout(5*time.Second),
+		WithCredentialsReload(func() (string, string, error) {
+			return "admin", "initial", nil
+		}),
+	)
+	assert.NoError(t, err)
+
+	_, err = client.Job.Status(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestClientAuthMode(t *testing.T) {
+	for mode, expected := range map[string]string{
+		"":         "Basic YWRtaW46c2VjcmV0",
+		AuthBasic:  "Basic YWRtaW46c2VjcmV0",
+		AuthBearer: "Bearer secret",
+	} {
+		var header atomic.Value
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header.Store(r.Header.Get("Authorization"))
+			_, _ = io.WriteString(w, `{}`)
+		}))
+
+		client, err := NewClient(
+			WithEndpoint(srv.URL),
+			WithUsername("admin"),
+			WithToken("secret"),
+			WithAuthMode(mode),
+			WithTimeout(5*time.Second),
+		)
+		assert.NoError(t, err)
+
+		_, err = client.Job.Status(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, expected, header.Load(), mode)
+
+		srv.Close()
+	}
+
+	_, err := NewClient(WithAuthMode("digest"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,180 @@
+package jenkins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	// crumbPath defines the path of the CSRF crumb issuer.
+	crumbPath = "/crumbIssuer/api/json"
+
+	// crumbRejection defines the message of forbidden responses caused by a
+	// missing or expired crumb.
+	crumbRejection = "No valid crumb"
+)
+
+// crumb defines the response of the crumb issuer.
+type crumb struct {
+	Field string `json:"crumbRequestField"`
+	Value string `json:"crumb"`
+}
+
+// crumbCache caches the CSRF crumb, it gets shared between the REST client
+// and the SDK to avoid separate requests against the crumb issuer.
+type crumbCache struct {
+	mu      sync.Mutex
+	fetch   func(ctx context.Context) (crumb, error)
+	current *crumb
+}
+
+// get returns the cached crumb, it gets fetched if it's not cached yet. An
+// empty crumb gets returned and cached if the issuer is not available.
+func (c *crumbCache) get(ctx context.Context) (crumb, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.current != nil {
+		return *c.current, nil
+	}
+
+	result, err := c.fetch(ctx)
+
+	if err != nil {
+		return crumb{}, err
+	}
+
+	c.current = &result
+	return result, nil
+}
+
+// invalidate drops the cached crumb, so it gets refetched on the next use.
+func (c *crumbCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.current = nil
+}
+
+// fetchCrumb requests a new crumb from the crumb issuer.
+func (c *Client) fetchCrumb(ctx context.Context) (crumb, error) {
+	result := crumb{}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+crumbPath, nil)
+
+	if err != nil {
+		return result, err
+	}
+
+	req.Header.Set("User-Agent", UserAgent)
+
+	c.setAuth(req)
+
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return result, fmt.Errorf("failed to request crumb: %w", err)
+	}
+
+	defer func() { _ = res.Body.Close() }()
+
+	// 未启用 CSRF 保护时不存在 crumb issuer，使用空 crumb
+	if res.StatusCode == http.StatusNotFound {
+		return result, nil
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return result, fmt.Errorf("failed to request crumb: %s", http.StatusText(res.StatusCode))
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return result, fmt.Errorf("failed to parse crumb: %w", err)
+	}
+
+	return result, nil
+}
+
+// crumbRejected checks if a forbidden response got caused by an invalid crumb.
+// The body gets buffered, so it can still be read afterwards.
+func crumbRejected(res *http.Response) bool {
+	if res.StatusCode != http.StatusForbidden {
+		return false
+	}
+
+	body, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err != nil {
+		return false
+	}
+
+	return bytes.Contains(body, []byte(crumbRejection))
+}
+
+// disabledCrumb is used as fetch function if crumbs are disabled, the empty
+// crumb is handled like a Jenkins without CSRF protection.
+func disabledCrumb(_ context.Context) (crumb, error) {
+	return crumb{}, nil
+}
+
+// crumbTransport serves the crumb issuer requests of the SDK from the shared
+// crumb cache and invalidates the cache on rejected crumbs.
+type crumbTransport struct {
+	base   http.RoundTripper
+	crumbs *crumbCache
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *crumbTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// gojenkins 会在路径后追加 api/json，因此只匹配前缀部分
+	if req.Method == http.MethodGet && strings.Contains(req.URL.Path, crumbPath) {
+		result, err := t.crumbs.get(req.Context())
+
+		if err != nil {
+			return nil, err
+		}
+
+		status := http.StatusOK
+		if result.Field == "" {
+			status = http.StatusNotFound
+		}
+
+		body, err := json.Marshal(result)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &http.Response{
+			Status:        http.StatusText(status),
+			StatusCode:    status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": []string{"application/json"}},
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	res, err := base.RoundTrip(req)
+
+	if err == nil && req.Method != http.MethodGet && crumbRejected(res) {
+		t.crumbs.invalidate()
+	}
+
+	return res, err
+}
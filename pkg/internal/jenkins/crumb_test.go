@@ -0,0 +1,153 @@
+package jenkins
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrumbSharedBetweenClients(t *testing.T) {
+	var fetches, rejected atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.TrimRight(r.URL.Path, "/") {
+		case "/api/json":
+			_, _ = io.WriteString(w, `{"jobs": []}`)
+		case crumbPath:
+			fetches.Add(1)
+			_, _ = io.WriteString(w, `{"crumbRequestField": "Jenkins-Crumb", "crumb": "abc"}`)
+		default:
+			if r.Method == http.MethodPost && r.Header.Get("Jenkins-Crumb") != "abc" {
+				rejected.Add(1)
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			_, _ = io.WriteString(w, `{}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+		WithCrumb(true),
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, client.InitSDK(testLogger()))
+
+	req, err := client.NewRequest(context.Background(), http.MethodPost, srv.URL+"/job/app/build", nil)
+	assert.NoError(t, err)
+
+	_, err = client.Do(req, nil)
+	assert.NoError(t, err)
+
+	res, err := client.SDK.jenkins.Requester.Post(context.Background(), "/job/app/enable", nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	assert.Equal(t, int32(1), fetches.Load())
+	assert.Equal(t, int32(0), rejected.Load())
+}
+
+func TestCrumbRefreshedOnForbidden(t *testing.T) {
+	var fetches atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.TrimRight(r.URL.Path, "/") == crumbPath {
+			fetches.Add(1)
+			_, _ = io.WriteString(w, `{"crumbRequestField": "Jenkins-Crumb", "crumb": "v`+string(rune('0'+fetches.Load()))+`"}`)
+			return
+		}
+
+		// 只接受第二次签发的 crumb
+		if r.Header.Get("Jenkins-Crumb") != "v2" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = io.WriteString(w, "No valid crumb was included in the request")
+			return
+		}
+
+		_, _ = io.WriteString(w, `{}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+		WithCrumb(true),
+	)
+	assert.NoError(t, err)
+
+	req, err := client.NewRequest(context.Background(), http.MethodPost, srv.URL+"/job/app/build", nil)
+	assert.NoError(t, err)
+
+	_, err = client.Do(req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), fetches.Load())
+}
+
+func TestCrumbNotRefreshedOnPermissionDenied(t *testing.T) {
+	var fetches, requests atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.TrimRight(r.URL.Path, "/") == crumbPath {
+			fetches.Add(1)
+			_, _ = io.WriteString(w, `{"crumbRequestField": "Jenkins-Crumb", "crumb": "abc"}`)
+			return
+		}
+
+		requests.Add(1)
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, "anonymous is missing the Job/Build permission")
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+		WithCrumb(true),
+	)
+	assert.NoError(t, err)
+
+	req, err := client.NewRequest(context.Background(), http.MethodPost, srv.URL+"/job/app/build", nil)
+	assert.NoError(t, err)
+
+	_, err = client.Do(req, nil)
+	assert.ErrorIs(t, err, ErrForbidden)
+	assert.Equal(t, int32(1), fetches.Load())
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestCrumbDisabledByDefault(t *testing.T) {
+	var fetches atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.TrimRight(r.URL.Path, "/") == crumbPath {
+			fetches.Add(1)
+		}
+
+		assert.Empty(t, r.Header.Get("Jenkins-Crumb"))
+		_, _ = io.WriteString(w, `{}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+	)
+	assert.NoError(t, err)
+
+	req, err := client.NewRequest(context.Background(), http.MethodPost, srv.URL+"/job/app/build", nil)
+	assert.NoError(t, err)
+
+	_, err = client.Do(req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), fetches.Load())
+}
@@ -0,0 +1,49 @@
+package jenkins
+
+import (
+	"strings"
+)
+
+// dedupeJobs removes jobs with the same full path, keeping the first one. It
+// returns the remaining jobs and the number of removed duplicates. The path
+// is taken from the URL, as the full name of different jobs may be identical.
+func dedupeJobs(jobs []Job) ([]Job, int) {
+	seen := make(map[string]bool, len(jobs))
+	result := make([]Job, 0, len(jobs))
+
+	for _, job := range jobs {
+		key := job.Path
+
+		if names := jobNamesFromURL(job.URL); len(names) > 0 {
+			key = strings.Join(names, "/")
+		}
+
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		result = append(result, job)
+	}
+
+	return result, len(jobs) - len(result)
+}
+
+// dedupeJobNames removes duplicated job names, keeping the order of the first
+// occurrence. It returns the remaining names and the number of removed
+// duplicates.
+func dedupeJobNames(names []string) ([]string, int) {
+	seen := make(map[string]bool, len(names))
+	result := make([]string, 0, len(names))
+
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+
+		seen[name] = true
+		result = append(result, name)
+	}
+
+	return result, len(names) - len(result)
+}
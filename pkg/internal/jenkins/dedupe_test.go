@@ -0,0 +1,46 @@
+package jenkins
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkinstest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobClientAllOverlappingFolders(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json": `{"jobs": [
+			{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "team", "url": "$URL/job/team/"}
+		]}`,
+		"/job/team/api/json": `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "team", "url": "$URL/job/team/", "jobs": [
+			{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "sub", "url": "$URL/job/team/job/sub/"}
+		]}`,
+		"/job/team/job/sub/api/json": `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "sub", "url": "$URL/job/team/job/sub/", "jobs": [
+			{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/team/job/sub/job/app/"}
+		]}`,
+		"/job/team/job/sub/job/app/api/json": `{"_class": "hudson.model.FreeStyleProject", "name": "app", "fullName": "team/sub/app", "url": "$URL/job/team/job/sub/job/app/"}`,
+	})
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+		WithFolderGlob(true),
+	)
+	assert.NoError(t, err)
+
+	jobs, _, err := client.Job.All(context.Background(), []string{"team", "team/sub"})
+	assert.NoError(t, err)
+
+	if assert.Len(t, jobs, 1) {
+		assert.Equal(t, "team/sub/app", jobs[0].Path)
+	}
+}
+
+func TestDedupeJobNames(t *testing.T) {
+	names, duplicates := dedupeJobNames([]string{"team/job/app", "other", "team/job/app"})
+
+	assert.Equal(t, []string{"team/job/app", "other"}, names)
+	assert.Equal(t, 1, duplicates)
+}
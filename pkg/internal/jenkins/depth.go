@@ -0,0 +1,70 @@
+package jenkins
+
+import (
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// WithFolderDepth configures a Client to stop recursing into folders nested
+// deeper than depth levels below the configured folders, the truncated
+// folders get logged with the logger of the Client. A depth of 0 means
+// unlimited.
+func WithFolderDepth(depth int) ClientOption {
+	return func(client *Client) error {
+		client.folderDepth = depth
+		return nil
+	}
+}
+
+// FolderDepth returns the maximum folder depth, 0 means unlimited.
+func (c *Client) FolderDepth() int {
+	return c.folderDepth
+}
+
+// exceedsDepth checks if a folder on the given level is nested too deep, the
+// configured folders or the top-level folders are on level 1.
+func exceedsDepth(level, limit int) bool {
+	return limit > 0 && level > limit
+}
+
+// folderPath returns the full path of the folder, the name only contains the
+// last segment for nested folders.
+func folderPath(folder Folder) string {
+	if names := jobNamesFromURL(folder.URL); len(names) > 0 {
+		return strings.Join(names, "/")
+	}
+
+	return folder.Name
+}
+
+// truncatedFolders collects the folders skipped by the depth limit.
+type truncatedFolders struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (t *truncatedFolders) add(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.names = append(t.names, name)
+}
+
+// warn logs the collected folders, if any folder has been truncated.
+func (t *truncatedFolders) warn(logger *slog.Logger, limit int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if logger == nil || len(t.names) == 0 {
+		return
+	}
+
+	sort.Strings(t.names)
+
+	logger.Warn("文件夹超过最大深度，未继续递归",
+		"最大深度", limit,
+		"文件夹", t.names,
+	)
+}
@@ -0,0 +1,89 @@
+package jenkins
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobClientAllFolderDepth(t *testing.T) {
+	routes := map[string]string{
+		"/api/json": `{"jobs": [
+			{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "a", "url": "$URL/job/a/"}
+		]}`,
+		"/job/a/api/json": `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "a", "url": "$URL/job/a/", "jobs": [
+			{"_class": "hudson.model.FreeStyleProject", "name": "one", "url": "$URL/job/a/job/one/"},
+			{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "b", "url": "$URL/job/a/job/b/"}
+		]}`,
+		"/job/a/job/one/api/json": `{"_class": "hudson.model.FreeStyleProject", "name": "one", "fullName": "a/one", "url": "$URL/job/a/job/one/"}`,
+		"/job/a/job/b/api/json": `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "b", "url": "$URL/job/a/job/b/", "jobs": [
+			{"_class": "hudson.model.FreeStyleProject", "name": "two", "url": "$URL/job/a/job/b/job/two/"},
+			{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "c", "url": "$URL/job/a/job/b/job/c/"}
+		]}`,
+		"/job/a/job/b/job/two/api/json": `{"_class": "hudson.model.FreeStyleProject", "name": "two", "fullName": "a/b/two", "url": "$URL/job/a/job/b/job/two/"}`,
+		"/job/a/job/b/job/c/api/json": `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "c", "url": "$URL/job/a/job/b/job/c/", "jobs": [
+			{"_class": "hudson.model.FreeStyleProject", "name": "three", "url": "$URL/job/a/job/b/job/c/job/three/"},
+			{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "d", "url": "$URL/job/a/job/b/job/c/job/d/"}
+		]}`,
+		"/job/a/job/b/job/c/job/three/api/json": `{"_class": "hudson.model.FreeStyleProject", "name": "three", "fullName": "a/b/c/three", "url": "$URL/job/a/job/b/job/c/job/three/"}`,
+		"/job/a/job/b/job/c/job/d/api/json": `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "d", "url": "$URL/job/a/job/b/job/c/job/d/", "jobs": [
+			{"_class": "hudson.model.FreeStyleProject", "name": "four", "url": "$URL/job/a/job/b/job/c/job/d/job/four/"}
+		]}`,
+		"/job/a/job/b/job/c/job/d/job/four/api/json": `{"_class": "hudson.model.FreeStyleProject", "name": "four", "fullName": "a/b/c/d/four", "url": "$URL/job/a/job/b/job/c/job/d/job/four/"}`,
+	}
+
+	var mu sync.Mutex
+	requested := make(map[string]bool)
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested[r.URL.Path] = true
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, strings.ReplaceAll(routes[r.URL.Path], "$URL", srv.URL))
+	}))
+	t.Cleanup(srv.Close)
+
+	buf := &bytes.Buffer{}
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+		WithFolderDepth(2),
+		WithLogger(slog.New(slog.NewTextHandler(buf, nil))),
+	)
+	assert.NoError(t, err)
+
+	jobs, _, err := client.Job.All(context.Background(), nil)
+	assert.NoError(t, err)
+
+	names := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		names = append(names, job.Path)
+	}
+
+	assert.ElementsMatch(t, []string{"a/one", "a/b/two"}, names)
+
+	// 超过最大深度的文件夹不会被请求
+	assert.False(t, requested["/job/a/job/b/job/c/api/json"])
+	assert.False(t, requested["/job/a/job/b/job/c/job/d/api/json"])
+
+	assert.Contains(t, buf.String(), "a/b/c")
+}
+
+func TestExceedsDepth(t *testing.T) {
+	assert.False(t, exceedsDepth(10, 0))
+	assert.False(t, exceedsDepth(2, 2))
+	assert.True(t, exceedsDepth(3, 2))
+}
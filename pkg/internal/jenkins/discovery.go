@@ -2,8 +2,10 @@ package jenkins
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 	"time"
 
@@ -14,6 +16,8 @@ import (
 // that gojenkins SDK expects.
 // Example: "uat/pre-wallet-server" -> "uat/job/pre-wallet-server"
 // Example: "folder/subfolder/job" -> "folder/job/subfolder/job/job"
+// Every "/" is treated as separator, discovery skips jobs whose names contain
+// a slash themselves.
 func convertJobPathForSDK(fullName string) string {
 	// 如果路径包含 "/"，说明是文件夹下的 job
 	// gojenkins SDK 需要 "folder/job/job" 格式，而不是 "folder/job"
@@ -32,26 +36,64 @@ func convertJobPathForSDK(fullName string) string {
 	return fullName
 }
 
+// jobPathFromSDK converts a job path from the SDK format "folder/job/job"
+// back to the full name "folder/job", it's the reverse of convertJobPathForSDK.
+func jobPathFromSDK(sdkPath string) string {
+	parts := strings.Split(sdkPath, "/")
+	names := make([]string, 0, len(parts)/2+1)
+
+	// SDK 格式中名称和 "job" 交替出现，只保留偶数位置的名称
+	for i := 0; i < len(parts); i += 2 {
+		names = append(names, parts[i])
+	}
+
+	return strings.Join(names, "/")
+}
+
 // StartDiscovery starts the job discovery process that periodically syncs job list from Jenkins to SQLite.
 // It runs at the specified interval (recommended: 5-10 minutes).
-func StartDiscovery(ctx context.Context, client *Client, repo *storage.JobRepo, interval time.Duration, folders []string, logger *slog.Logger) error {
+// With strictFolders it fails if a configured folder doesn't exist.
+func StartDiscovery(ctx context.Context, client *Client, repo *storage.JobRepo, interval time.Duration, folders, excludedJobs, excludedFolders []string, strictFolders bool, metrics *DiscoveryMetrics, logger *slog.Logger) error {
 	logger = logger.With("component", "discovery")
+	excluded := newExcludedFolders(excludedFolders)
 
 	logger.Info("启动 Job Discovery",
 		"同步间隔", interval,
 		"指定文件夹", folders,
 	)
 
-	// 立即执行一次同步
-	if err := syncJobsOnce(ctx, client, repo, folders, logger); err != nil {
-		logger.Warn("首次同步失败，将在下一个周期重试",
+	// 启动前检查配置的文件夹是否存在，拼写错误会导致永远同步不到任何 job
+	if err := CheckFolders(ctx, client, folders); errors.Is(err, ErrFolderNotFound) {
+		logger.Error("配置的文件夹不存在，请检查 collector.jobs.folders 配置",
+			"错误", err,
+		)
+
+		if strictFolders {
+			return err
+		}
+	} else if err != nil {
+		logger.Warn("检查配置的文件夹失败，跳过检查",
 			"错误", err,
 		)
 	}
 
+	// 先创建 ticker，下次同步的时间从本次同步开始时计算
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
+	// 立即执行一次同步
+	start := time.Now()
+	metrics.observeSchedule(start, interval)
+
+	if err := syncJobsOnce(ctx, client, repo, folders, excludedJobs, excluded, metrics, logger); err != nil {
+		metrics.observeSyncError()
+		logger.Warn("首次同步失败，将在下一个周期重试",
+			"错误", err,
+		)
+	} else {
+		metrics.observeSync(start)
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -59,19 +101,81 @@ func StartDiscovery(ctx context.Context, client *Client, repo *storage.JobRepo,
 				"原因", ctx.Err(),
 			)
 			return ctx.Err()
-		case <-ticker.C:
-			if err := syncJobsOnce(ctx, client, repo, folders, logger); err != nil {
+		case tick := <-ticker.C:
+			metrics.observeSchedule(tick, interval)
+
+			if err := syncJobsOnce(ctx, client, repo, folders, excludedJobs, excluded, metrics, logger); err != nil {
+				metrics.observeSyncError()
 				logger.Warn("Job 列表同步失败，将在下一个周期重试",
 					"错误", err,
 				)
 				// 继续运行，不中断服务
+				continue
 			}
+
+			metrics.observeSync(tick)
 		}
 	}
 }
 
+// CheckFolders verifies that all configured folders exist as top-level jobs.
+// The error lists the missing and the available top-level folders.
+func CheckFolders(ctx context.Context, client *Client, folders []string) error {
+	if len(folders) == 0 {
+		return nil
+	}
+
+	if client.FolderGlob() {
+		return checkFolderPatterns(ctx, client, folders)
+	}
+
+	available, err := client.Job.TopLevelNames(ctx)
+
+	if err != nil {
+		return fmt.Errorf("failed to list top-level folders: %w", err)
+	}
+
+	missing := make([]string, 0)
+
+	for _, folder := range folders {
+		if !slices.Contains(available, folder) {
+			missing = append(missing, folder)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s, available top-level folders: %s", ErrFolderNotFound, strings.Join(missing, ", "), strings.Join(available, ", "))
+}
+
+// checkFolderPatterns verifies that every configured glob pattern matches at
+// least one folder.
+func checkFolderPatterns(ctx context.Context, client *Client, patterns []string) error {
+	missing := make([]string, 0)
+
+	for _, pattern := range patterns {
+		matched, err := client.Job.MatchFolders(ctx, []string{pattern})
+
+		if err != nil {
+			return fmt.Errorf("failed to match folders: %w", err)
+		}
+
+		if len(matched) == 0 {
+			missing = append(missing, pattern)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: no folders match %s", ErrFolderNotFound, strings.Join(missing, ", "))
+}
+
 // syncJobsOnce performs a single synchronization of jobs from Jenkins to SQLite.
-func syncJobsOnce(ctx context.Context, client *Client, repo *storage.JobRepo, folders []string, logger *slog.Logger) error {
+func syncJobsOnce(ctx context.Context, client *Client, repo *storage.JobRepo, folders, excludedJobs []string, excludedFolders map[string]bool, metrics *DiscoveryMetrics, logger *slog.Logger) error {
 	logger.Info("开始同步 Job 列表",
 		"指定文件夹", folders,
 		"说明", "正在从 Jenkins 获取 job 列表并同步到 SQLite 数据库",
@@ -79,18 +183,55 @@ func syncJobsOnce(ctx context.Context, client *Client, repo *storage.JobRepo, fo
 
 	// 初始化 SDK（如果尚未初始化）
 	logger.Info("正在初始化 Jenkins SDK...")
-	if err := client.InitSDK(logger); err != nil {
+	if err := client.InitBackend(logger); err != nil {
 		return fmt.Errorf("failed to initialize SDK: %w", err)
 	}
+
+	// SDK 初始化多次失败后回退到 REST 客户端
+	if !client.UseSDK() {
+		return syncJobsREST(ctx, client, repo, folders, excludedJobs, excludedFolders, metrics, logger)
+	}
 	logger.Info("Jenkins SDK 初始化成功")
 
 	// 使用 SDK 递归获取所有 job（包括文件夹下的所有 job）
 	// 返回 job 列表和路径映射（因为 gojenkins.Job.GetName() 可能只返回相对名称）
 	logger.Info("正在从 Jenkins 获取 job 列表（递归获取所有文件夹下的 job）...")
-	sdkJobs, jobPathMap, err := client.SDK.GetAllJobsRecursive(ctx, folders, logger)
+	fetchStart := time.Now()
+	// glob 模式需要先解析为具体的文件夹路径
+	if len(folders) > 0 && client.FolderGlob() {
+		matched, err := client.Job.MatchFolders(ctx, folders)
+		if err != nil {
+			return fmt.Errorf("failed to match folders: %w", err)
+		}
+
+		logger.Info("匹配到的文件夹",
+			"模式", folders,
+			"文件夹", folderNames(matched),
+		)
+
+		if len(matched) == 0 {
+			return fmt.Errorf("%w: no folders match %s", ErrFolderNotFound, strings.Join(folders, ", "))
+		}
+
+		folders = folderNames(matched)
+	}
+
+	sdkJobs, jobPathMap, stats, err := client.SDK.GetAllJobsRecursive(ctx, folders, excludedFolders, logger)
 	if err != nil {
 		return fmt.Errorf("failed to get jobs from Jenkins SDK: %w", err)
 	}
+	metrics.observePhase("fetch", fetchStart)
+
+	failedFolders := stats.FailedFolders
+	metrics.observeFailedFolders(failedFolders)
+	metrics.observeDisabledFolders(stats.DisabledFolders)
+	partial := failedFolders > 0
+
+	if partial {
+		logger.Warn("部分文件夹获取失败，本次同步结果不完整，将不会软删除任何 job",
+			"失败的文件夹数量", failedFolders,
+		)
+	}
 	
 	logger.Info("从 Jenkins 获取到 job 列表",
 		"原始 job 数量", len(sdkJobs),
@@ -98,12 +239,6 @@ func syncJobsOnce(ctx context.Context, client *Client, repo *storage.JobRepo, fo
 	)
 
 	// 提取 job 名称（使用路径映射获取完整路径），并过滤掉排除的文件夹
-	excludedFolders := map[string]bool{
-		"prod-ebpay-new":  true,
-		"pre-ebpay-new":   true,
-		"prod-gray-ebpay":  true,
-	}
-	
 	jobNames := make([]string, 0, len(sdkJobs))
 	excludedCount := 0
 	folderCount := 0
@@ -117,6 +252,7 @@ func syncJobsOnce(ctx context.Context, client *Client, repo *storage.JobRepo, fo
 	processedCount := 0
 	validCount := 0
 	progressInterval := 50 // 每处理 50 个 job 输出一次进度
+	filterStart := time.Now()
 	
 	for i, job := range sdkJobs {
 		processedCount = i + 1
@@ -128,7 +264,7 @@ func syncJobsOnce(ctx context.Context, client *Client, repo *storage.JobRepo, fo
 		}
 		
 		if fullName == "" {
-			logger.Debug("跳过空名称的 job",
+			logger.Log(ctx, LevelTrace, "跳过空名称的 job",
 				"job_info", fmt.Sprintf("%+v", job),
 			)
 			continue
@@ -159,7 +295,7 @@ func syncJobsOnce(ctx context.Context, client *Client, repo *storage.JobRepo, fo
 			if err == nil {
 				// 能成功调用 GetInnerJobs，说明是文件夹
 				isFolder = true
-				logger.Debug("在 Discovery 阶段检测到文件夹类型，跳过",
+				logger.Log(ctx, LevelTrace, "在 Discovery 阶段检测到文件夹类型，跳过",
 					"job_name", fullName,
 					"子项数量", len(subJobs),
 				)
@@ -168,7 +304,7 @@ func syncJobsOnce(ctx context.Context, client *Client, repo *storage.JobRepo, fo
 		
 		if isFolder {
 			folderCount++
-			logger.Debug("跳过文件夹类型的 job（在 Discovery 阶段）",
+			logger.Log(ctx, LevelTrace, "跳过文件夹类型的 job（在 Discovery 阶段）",
 				"job_name", fullName,
 			)
 			continue
@@ -179,30 +315,43 @@ func syncJobsOnce(ctx context.Context, client *Client, repo *storage.JobRepo, fo
 		if jobPathMap[job] != "" {
 			source = "路径映射"
 		}
-		logger.Debug("获取到构建 job 完整路径",
+		logger.Log(ctx, LevelTrace, "获取到构建 job 完整路径",
 			"full_name", fullName,
 			"来源", source,
 			"说明", "将存储到 SQLite。如果是文件夹下的 job，应该是完整路径 folder/job",
 		)
 		
 		// 检查是否是排除的文件夹下的 job
-		parts := strings.Split(fullName, "/")
-		if len(parts) > 0 {
-			topLevelFolder := parts[0]
-			if excludedFolders[topLevelFolder] {
-				excludedCount++
-				logger.Debug("过滤掉排除的文件夹下的 job",
-					"job_name", fullName,
-					"顶层文件夹", topLevelFolder,
-				)
-				continue
-			}
+		if isExcludedFolder(fullName, excludedFolders) {
+			excludedCount++
+			logger.Log(ctx, LevelTrace, "过滤掉排除的文件夹下的 job",
+				"job_name", fullName,
+			)
+			continue
+		}
+
+		// 检查是否是排除的顶层 job
+		if IsExcludedJob(fullName, excludedJobs) {
+			excludedCount++
+			logger.Log(ctx, LevelTrace, "过滤掉排除的顶层 job",
+				"job_name", fullName,
+			)
+			continue
+		}
+
+		// 检查是否匹配包含和排除的正则表达式
+		if !client.JobFilter().Match(fullName) {
+			excludedCount++
+			logger.Log(ctx, LevelTrace, "过滤掉不匹配正则表达式的 job",
+				"job_name", fullName,
+			)
+			continue
 		}
 		
 		// 将路径转换为 SDK 格式（folder/job -> folder/job/job）
 		// 这样存储到数据库后，采集时可以直接使用，不需要再次转换
 		sdkPath := convertJobPathForSDK(fullName)
-		logger.Debug("转换 job 路径为 SDK 格式",
+		logger.Log(ctx, LevelTrace, "转换 job 路径为 SDK 格式",
 			"原始路径", fullName,
 			"SDK 路径", sdkPath,
 			"说明", "存储到数据库的路径已经是 SDK 格式，采集时可直接使用",
@@ -223,6 +372,16 @@ func syncJobsOnce(ctx context.Context, client *Client, repo *storage.JobRepo, fo
 			)
 		}
 	}
+	metrics.observePhase("filter", filterStart)
+
+	// 重叠的文件夹（例如 team 和 team/sub）会返回重复的 job
+	jobNames, duplicates := dedupeJobNames(jobNames)
+	if duplicates > 0 {
+		logger.Warn("移除了通过多个文件夹获取到的重复 job",
+			"重复数量", duplicates,
+			"指定文件夹", folders,
+		)
+	}
 	
 	if folderCount > 0 {
 		logger.Info("过滤掉文件夹类型的 job",
@@ -237,6 +396,8 @@ func syncJobsOnce(ctx context.Context, client *Client, repo *storage.JobRepo, fo
 		)
 	}
 
+	metrics.observeJobs(len(jobNames), excludedCount+stats.ExcludedFolders)
+
 	if len(jobNames) == 0 {
 		logger.Warn("从 Jenkins 获取到的 job 列表为空",
 			"指定文件夹", folders,
@@ -257,10 +418,17 @@ func syncJobsOnce(ctx context.Context, client *Client, repo *storage.JobRepo, fo
 		"说明", "正在将 job 列表同步到数据库（新增、更新或软删除 job 记录）...",
 	)
 
-	// 同步到 SQLite
-	if err := repo.SyncJobs(jobNames); err != nil {
+	// 同步到 SQLite，结果不完整时不软删除缺失的 job
+	syncJobs := repo.SyncJobs
+	if partial {
+		syncJobs = repo.SyncJobsPartial
+	}
+
+	syncStart := time.Now()
+	if err := syncJobs(jobNames); err != nil {
 		return fmt.Errorf("failed to sync jobs to SQLite: %w", err)
 	}
+	metrics.observePhase("sync", syncStart)
 
 	// 获取同步后的统计信息（从数据库读取实际数量）
 	enabledJobs, err := repo.ListEnabledJobs()
@@ -285,6 +453,88 @@ func syncJobsOnce(ctx context.Context, client *Client, repo *storage.JobRepo, fo
 	return nil
 }
 
+// syncJobsREST performs a single synchronization of jobs using the REST
+// client, it's used after falling back from the SDK.
+func syncJobsREST(ctx context.Context, client *Client, repo *storage.JobRepo, folders, excludedJobs []string, excludedFolders map[string]bool, metrics *DiscoveryMetrics, logger *slog.Logger) error {
+	logger.Info("正在通过 REST 客户端获取 job 列表")
+
+	fetchStart := time.Now()
+	jobs, stats, err := client.Job.All(ctx, folders)
+	if err != nil {
+		return fmt.Errorf("failed to get jobs from Jenkins API: %w", err)
+	}
+	metrics.observePhase("fetch", fetchStart)
+
+	// REST 客户端会跳过获取失败的文件夹，此时结果不完整
+	metrics.observeFailedFolders(stats.FailedFolders)
+	partial := stats.FailedFolders > 0
+
+	if partial {
+		logger.Warn("部分文件夹获取失败，本次同步结果不完整，将不会软删除任何 job",
+			"失败的文件夹数量", stats.FailedFolders,
+		)
+	}
+
+	filterStart := time.Now()
+	excludedCount := 0
+	jobNames := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		// 优先使用 URL 中的名称，fullName 中的 "/" 无法区分路径分隔符和名称本身
+		if names := jobNamesFromURL(job.URL); len(names) > 0 {
+			if slices.ContainsFunc(names, isAmbiguousName) {
+				logger.Warn("job 名称包含路径分隔符，无法构建 API 路径，跳过",
+					"job_name", job.Path,
+					"url", job.URL,
+				)
+				continue
+			}
+
+			job.Path = strings.Join(names, "/")
+		}
+
+		if job.Path == "" {
+			continue
+		}
+
+		if isExcludedFolder(job.Path, excludedFolders) || IsExcludedJob(job.Path, excludedJobs) {
+			excludedCount++
+			continue
+		}
+
+		// 与 SDK 模式保持一致，存储 SDK 格式的路径
+		jobNames = append(jobNames, convertJobPathForSDK(job.Path))
+	}
+	metrics.observePhase("filter", filterStart)
+	metrics.observeJobs(len(jobNames), excludedCount)
+
+	if len(jobNames) == 0 {
+		logger.Warn("从 Jenkins 获取到的 job 列表为空",
+			"指定文件夹", folders,
+			"原始 job 数量", len(jobs),
+		)
+		return nil
+	}
+
+	// 同步到 SQLite，结果不完整时不软删除缺失的 job
+	syncJobs := repo.SyncJobs
+	if partial {
+		syncJobs = repo.SyncJobsPartial
+	}
+
+	syncStart := time.Now()
+	if err := syncJobs(jobNames); err != nil {
+		return fmt.Errorf("failed to sync jobs to SQLite: %w", err)
+	}
+	metrics.observePhase("sync", syncStart)
+
+	logger.Info("✅ Job 列表同步完成（REST 模式）",
+		"有效 job 数量", len(jobNames),
+		"指定文件夹", folders,
+	)
+
+	return nil
+}
+
 // GetJobNamesFromFolders extracts job names from a folder string (comma-separated).
 func GetJobNamesFromFolders(foldersStr string) []string {
 	if foldersStr == "" {
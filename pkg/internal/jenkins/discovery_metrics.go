@@ -0,0 +1,154 @@
+package jenkins
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DiscoveryMetrics exports the state of the job discovery.
+type DiscoveryMetrics struct {
+	partial         prometheus.Gauge
+	foldersFailed   prometheus.Counter
+	foldersDisabled prometheus.Gauge
+	phaseDuration   *prometheus.GaugeVec
+	lastSync        prometheus.Gauge
+	nextSync        prometheus.Gauge
+	jobs            prometheus.Gauge
+	excluded        prometheus.Gauge
+	syncErrors      prometheus.Counter
+}
+
+// NewDiscoveryMetrics creates a new DiscoveryMetrics instance.
+func NewDiscoveryMetrics() *DiscoveryMetrics {
+	return &DiscoveryMetrics{
+		partial: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "jenkins_discovery_partial",
+				Help: "1 if the last discovery skipped failed folders, 0 otherwise",
+			},
+		),
+		foldersFailed: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "jenkins_discovery_folders_failed_total",
+				Help: "Number of folders which failed and got skipped during discovery",
+			},
+		),
+		foldersDisabled: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "jenkins_discovery_folders_disabled",
+				Help: "Number of disabled folders skipped by the last discovery",
+			},
+		),
+		phaseDuration: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "jenkins_discovery_phase_duration_seconds",
+				Help: "Duration of the phases of the last discovery in seconds, fetch from Jenkins, filter and sync to the database",
+			},
+			[]string{"phase"},
+		),
+		lastSync: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "jenkins_discovery_last_sync_timestamp_seconds",
+				Help: "Unix timestamp when the last successful discovery sync started",
+			},
+		),
+		nextSync: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "jenkins_discovery_next_sync_timestamp_seconds",
+				Help: "Unix timestamp when the next discovery sync is scheduled",
+			},
+		),
+		jobs: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "jenkins_discovery_jobs_total",
+				Help: "Number of jobs found by the last discovery after applying the exclusions",
+			},
+		),
+		excluded: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "jenkins_discovery_excluded_total",
+				Help: "Number of excluded jobs and folders skipped by the last discovery",
+			},
+		),
+		syncErrors: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "jenkins_discovery_sync_errors_total",
+				Help: "Number of failed discovery syncs",
+			},
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (m *DiscoveryMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.partial.Describe(ch)
+	m.foldersFailed.Describe(ch)
+	m.foldersDisabled.Describe(ch)
+	m.phaseDuration.Describe(ch)
+	m.lastSync.Describe(ch)
+	m.nextSync.Describe(ch)
+	m.jobs.Describe(ch)
+	m.excluded.Describe(ch)
+	m.syncErrors.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (m *DiscoveryMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.partial.Collect(ch)
+	m.foldersFailed.Collect(ch)
+	m.foldersDisabled.Collect(ch)
+	m.phaseDuration.Collect(ch)
+	m.lastSync.Collect(ch)
+	m.nextSync.Collect(ch)
+	m.jobs.Collect(ch)
+	m.excluded.Collect(ch)
+	m.syncErrors.Collect(ch)
+}
+
+// observeFailedFolders records the number of failed folders of a discovery run.
+func (m *DiscoveryMetrics) observeFailedFolders(failed int) {
+	if failed > 0 {
+		m.partial.Set(1)
+		m.foldersFailed.Add(float64(failed))
+		return
+	}
+
+	m.partial.Set(0)
+}
+
+// observeDisabledFolders records the number of disabled folders skipped by a
+// discovery run.
+func (m *DiscoveryMetrics) observeDisabledFolders(disabled int) {
+	m.foldersDisabled.Set(float64(disabled))
+}
+
+// observePhase records the duration of a discovery phase started at the given
+// time.
+func (m *DiscoveryMetrics) observePhase(phase string, start time.Time) {
+	m.phaseDuration.WithLabelValues(phase).Set(time.Since(start).Seconds())
+}
+
+// observeSchedule records when the next discovery sync is scheduled, the
+// next sync starts the given interval after the start of the current one.
+func (m *DiscoveryMetrics) observeSchedule(start time.Time, interval time.Duration) {
+	m.nextSync.Set(float64(start.Add(interval).Unix()))
+}
+
+// observeSync records the start of the last successful discovery sync, failed
+// syncs don't update it so the lag keeps growing.
+func (m *DiscoveryMetrics) observeSync(start time.Time) {
+	m.lastSync.Set(float64(start.Unix()))
+}
+
+// observeJobs records the number of synced and excluded jobs of a discovery
+// run.
+func (m *DiscoveryMetrics) observeJobs(synced, excluded int) {
+	m.jobs.Set(float64(synced))
+	m.excluded.Set(float64(excluded))
+}
+
+// observeSyncError records a failed discovery sync.
+func (m *DiscoveryMetrics) observeSyncError() {
+	m.syncErrors.Inc()
+}
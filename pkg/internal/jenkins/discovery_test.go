@@ -0,0 +1,331 @@
+package jenkins
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkinstest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncJobsOncePartialDiscovery(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":                `{"jobs": []}`,
+		"/job/ok/api/json":         `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "ok", "jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app"}]}`,
+		"/job/ok/job/app/api/json": `{"_class": "hudson.model.FreeStyleProject", "name": "app"}`,
+	})
+
+	repo := newTestRepo(t)
+	assert.NoError(t, repo.SyncJobs([]string{"broken/job/old"}))
+
+	metrics := NewDiscoveryMetrics()
+	err := syncJobsOnce(context.Background(), newTestClient(t, srv), repo, []string{"ok", "broken"}, nil, nil, metrics, testLogger())
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.partial))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.foldersFailed))
+
+	// 获取、过滤和同步三个阶段的耗时都被记录
+	assert.Equal(t, 3, testutil.CollectAndCount(metrics.phaseDuration))
+
+	// 结果不完整时不能软删除失败文件夹下的 job
+	jobs, err := repo.ListEnabledJobs()
+	assert.NoError(t, err)
+
+	names := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		names = append(names, job.JobName)
+	}
+
+	assert.ElementsMatch(t, []string{"broken/job/old", "ok/job/app"}, names)
+}
+
+func TestSyncJobsRESTPartialDiscovery(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json": `{"jobs": [
+			{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "ok", "url": "$URL/job/ok/"},
+			{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "broken", "url": "$URL/job/broken/"}
+		]}`,
+		"/job/ok/api/json":         `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "ok", "jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/ok/job/app/"}]}`,
+		"/job/ok/job/app/api/json": `{"_class": "hudson.model.FreeStyleProject", "fullName": "ok/app", "url": "$URL/job/ok/job/app/"}`,
+	})
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+	)
+	assert.NoError(t, err)
+
+	repo := newTestRepo(t)
+	assert.NoError(t, repo.SyncJobs([]string{"broken/job/old"}))
+
+	metrics := NewDiscoveryMetrics()
+	assert.NoError(t, syncJobsREST(context.Background(), client, repo, nil, nil, nil, metrics, testLogger()))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.partial))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.foldersFailed))
+
+	// 结果不完整时不能软删除失败文件夹下的 job
+	jobs, err := repo.ListEnabledJobs()
+	assert.NoError(t, err)
+
+	names := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		names = append(names, job.JobName)
+	}
+
+	assert.ElementsMatch(t, []string{"broken/job/old", "ok/job/app"}, names)
+}
+
+func TestSyncJobsOnceFallsBackToREST(t *testing.T) {
+	routes := map[string]string{
+		"/api/json":                    `{"jobs": [{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "team", "url": "$URL/job/team/"}]}`,
+		"/job/team/api/json":           `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "team", "jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/team/job/app/"}]}`,
+		"/job/team/job/app/api/json":   `{"_class": "hudson.model.FreeStyleProject", "fullName": "team/app", "lastBuild": {"number": 4, "url": "$URL/job/team/job/app/4/"}, "lastCompletedBuild": {"number": 4, "url": "$URL/job/team/job/app/4/"}}`,
+		"/job/team/job/app/4/api/json": `{"number": 4, "result": "FAILURE", "actions": [{"parameters": [{"name": "gitBranch", "value": "main"}]}]}`,
+	}
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := routes[r.URL.Path]
+
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		// gojenkins 遇到 X-Error 时初始化失败，REST 客户端不受影响
+		if r.URL.Path == "/api/json" {
+			w.Header().Set("X-Error", "unsupported")
+		}
+
+		_, _ = io.WriteString(w, strings.ReplaceAll(body, "$URL", srv.URL))
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+		WithSDKFallback(2),
+	)
+	assert.NoError(t, err)
+
+	repo := newTestRepo(t)
+	metrics := NewDiscoveryMetrics()
+
+	// 第一次失败仍然返回错误，达到阈值后回退到 REST
+	assert.Error(t, syncJobsOnce(context.Background(), client, repo, nil, nil, nil, metrics, testLogger()))
+	assert.True(t, client.UseSDK())
+	assert.NoError(t, syncJobsOnce(context.Background(), client, repo, nil, nil, nil, metrics, testLogger()))
+	assert.False(t, client.UseSDK())
+
+	jobs, err := repo.ListEnabledJobs()
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, "team/job/app", jobs[0].JobName)
+
+	c := NewBuildCollector(client, repo, testLogger(), 1)
+	result, err := c.processJob(context.Background(), jobs[0])
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), result.BuildNumber)
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.buildResultGauge.WithLabelValues("team/job/app", "", "main", "failure")))
+}
+
+func TestSyncJobsOnceSkipsDisabledFolders(t *testing.T) {
+	routes := map[string]string{
+		"/api/json":                     `{"jobs": [{"name": "active"}, {"name": "retired"}]}`,
+		"/job/active/api/json":          `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "active", "jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app"}]}`,
+		"/job/active/job/app/api/json":  `{"_class": "hudson.model.FreeStyleProject", "name": "app"}`,
+		"/job/retired/api/json":         `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "retired", "color": "disabled", "jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "old"}]}`,
+		"/job/retired/job/old/api/json": `{"_class": "hudson.model.FreeStyleProject", "name": "old"}`,
+	}
+
+	var mu sync.Mutex
+	requested := map[string]bool{}
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested[strings.TrimRight(r.URL.Path, "/")] = true
+		mu.Unlock()
+
+		body, ok := routes[strings.TrimRight(r.URL.Path, "/")]
+
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = io.WriteString(w, strings.ReplaceAll(body, "$URL", srv.URL))
+	}))
+	t.Cleanup(srv.Close)
+
+	repo := newTestRepo(t)
+	metrics := NewDiscoveryMetrics()
+
+	assert.NoError(t, syncJobsOnce(context.Background(), newTestClient(t, srv), repo, nil, nil, nil, metrics, testLogger()))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.foldersDisabled))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.partial))
+
+	mu.Lock()
+	assert.False(t, requested["/job/retired/job/old/api/json"])
+	mu.Unlock()
+
+	jobs, err := repo.ListEnabledJobs()
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, "active/job/app", jobs[0].JobName)
+}
+
+func TestSyncJobsOnceSkipsAmbiguousNames(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":                  `{"jobs": [{"name": "team"}]}`,
+		"/job/team/api/json":         `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "team", "jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/team/job/app/"}, {"_class": "hudson.model.FreeStyleProject", "name": "release/1.0", "url": "$URL/job/team/job/release%2F1.0/"}]}`,
+		"/job/team/job/app/api/json": `{"_class": "hudson.model.FreeStyleProject", "name": "app", "displayName": "app/main"}`,
+	})
+
+	repo := newTestRepo(t)
+	metrics := NewDiscoveryMetrics()
+
+	// 名称包含 "/" 的 job 被跳过，不会导致整个文件夹失败
+	assert.NoError(t, syncJobsOnce(context.Background(), newTestClient(t, srv), repo, nil, nil, nil, metrics, testLogger()))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.partial))
+
+	jobs, err := repo.ListEnabledJobs()
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, "team/job/app", jobs[0].JobName)
+}
+
+func TestSyncJobsOnceSkipsExcludedFolders(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":                    `{"jobs": [{"name": "team"}, {"name": "legacy"}]}`,
+		"/job/team/api/json":           `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "team", "jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app"}]}`,
+		"/job/team/job/app/api/json":   `{"_class": "hudson.model.FreeStyleProject", "name": "app"}`,
+		"/job/legacy/api/json":         `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "legacy", "jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "old"}]}`,
+		"/job/legacy/job/old/api/json": `{"_class": "hudson.model.FreeStyleProject", "name": "old"}`,
+	})
+
+	repo := newTestRepo(t)
+	metrics := NewDiscoveryMetrics()
+	excluded := newExcludedFolders([]string{" legacy , "})
+
+	assert.NoError(t, syncJobsOnce(context.Background(), newTestClient(t, srv), repo, nil, nil, excluded, metrics, testLogger()))
+
+	jobs, err := repo.ListEnabledJobs()
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, "team/job/app", jobs[0].JobName)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.jobs))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.excluded))
+}
+
+func TestIsExcludedFolder(t *testing.T) {
+	excluded := newExcludedFolders([]string{"legacy"})
+
+	assert.True(t, isExcludedFolder("legacy", excluded))
+	assert.True(t, isExcludedFolder("legacy/job/old", excluded))
+	assert.False(t, isExcludedFolder("team/legacy", excluded))
+	assert.False(t, isExcludedFolder("legacy/job/old", nil))
+}
+
+func TestJobNamesFromURL(t *testing.T) {
+	assert.Equal(t, []string{"team", "app"}, jobNamesFromURL("https://ci.example.com/jenkins/job/team/job/app/"))
+	assert.Equal(t, []string{"team", "release/1.0"}, jobNamesFromURL("https://ci.example.com/job/team/job/release%2F1.0/"))
+	assert.Empty(t, jobNamesFromURL("https://ci.example.com/"))
+}
+
+func TestCheckFolders(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json": `{"jobs": [{"name": "team"}, {"name": "infra"}]}`,
+	})
+
+	client := newTestClient(t, srv)
+
+	assert.NoError(t, CheckFolders(context.Background(), client, nil))
+	assert.NoError(t, CheckFolders(context.Background(), client, []string{"team"}))
+
+	err := CheckFolders(context.Background(), client, []string{"team", "teem"})
+	assert.ErrorIs(t, err, ErrFolderNotFound)
+	assert.ErrorContains(t, err, "teem")
+	assert.ErrorContains(t, err, "available top-level folders: team, infra")
+}
+
+func TestStartDiscoveryStrictFolders(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json": `{"jobs": [{"name": "team"}]}`,
+	})
+
+	err := StartDiscovery(context.Background(), newTestClient(t, srv), newTestRepo(t), time.Minute, []string{"teem"}, nil, nil, true, NewDiscoveryMetrics(), testLogger())
+	assert.ErrorIs(t, err, ErrFolderNotFound)
+}
+
+func TestStartDiscoveryNextSync(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json": `{"jobs": []}`,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, repo, metrics := newTestClient(t, srv), newTestRepo(t), NewDiscoveryMetrics()
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		_ = StartDiscovery(ctx, client, repo, 10*time.Minute, nil, nil, nil, false, metrics, testLogger())
+	}()
+
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.lastSync) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+
+	last := testutil.ToFloat64(metrics.lastSync)
+	assert.Equal(t, last+600, testutil.ToFloat64(metrics.nextSync))
+}
+
+func TestStartDiscoverySyncErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+	)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	metrics := NewDiscoveryMetrics()
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		_ = StartDiscovery(ctx, client, newTestRepo(t), 10*time.Minute, nil, nil, nil, false, metrics, testLogger())
+	}()
+
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.syncErrors) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+
+	// 失败的同步只安排下一次同步，不更新最后一次同步的时间
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.lastSync))
+	assert.Greater(t, testutil.ToFloat64(metrics.nextSync), 0.0)
+}
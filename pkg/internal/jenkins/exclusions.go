@@ -0,0 +1,138 @@
+package jenkins
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// WithExclusionsFile configures a file with additional exclusions, which gets
+// read again within the given interval, so the exclusions can be changed
+// without a restart. Every line contains a top-level job name, folders end
+// with a slash and empty lines or lines starting with # are ignored.
+func WithExclusionsFile(file string, interval time.Duration) BuildCollectorOption {
+	return func(c *BuildCollector) {
+		c.exclusionsFile = file
+
+		if interval > 0 {
+			c.exclusionsInterval = interval
+		}
+	}
+}
+
+// ReadExclusions parses the excluded top-level jobs and folders from a file.
+func ReadExclusions(file string) ([]string, []string, error) {
+	handle, err := os.Open(file)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer handle.Close()
+
+	jobs := make([]string, 0)
+	folders := make([]string, 0)
+	scanner := bufio.NewScanner(handle)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if folder, ok := strings.CutSuffix(line, "/"); ok {
+			folders = append(folders, folder)
+			continue
+		}
+
+		jobs = append(jobs, line)
+	}
+
+	return jobs, folders, scanner.Err()
+}
+
+// isExcluded checks if the job is excluded by the configured exclusions or by
+// the exclusions file.
+func (c *BuildCollector) isExcluded(jobName string) bool {
+	c.excludeMu.RLock()
+	defer c.excludeMu.RUnlock()
+
+	return isExcludedFolder(jobName, c.excludedFolders) || IsExcludedJob(jobName, c.excludedJobs) ||
+		isExcludedFolder(jobName, c.fileFolders) || IsExcludedJob(jobName, c.fileJobs)
+}
+
+// reloadExclusions reads the exclusions file again, if the exclusions changed
+// the metrics of the jobs excluded now get removed. It returns true if the
+// exclusions changed.
+func (c *BuildCollector) reloadExclusions() (bool, error) {
+	jobs, folders, err := ReadExclusions(c.exclusionsFile)
+
+	if err != nil {
+		return false, err
+	}
+
+	c.excludeMu.Lock()
+	changed := !slices.Equal(jobs, c.fileJobs) || !slices.Equal(folders, c.fileFolderNames)
+
+	if changed {
+		c.fileJobs = jobs
+		c.fileFolderNames = folders
+		c.fileFolders = newExcludedFolders(folders)
+	}
+	c.excludeMu.Unlock()
+
+	if !changed {
+		return false, nil
+	}
+
+	enabled, err := c.repo.ListEnabledJobs()
+
+	if err != nil {
+		return true, err
+	}
+
+	c.updateMetrics(func() {
+		for _, job := range enabled {
+			if c.isExcluded(job.JobName) {
+				c.deleteJobMetrics(job.JobName)
+			}
+		}
+	})
+
+	return true, c.updateStatusTally()
+}
+
+// watchExclusions reads the exclusions file within the configured interval
+// until the context gets canceled.
+func (c *BuildCollector) watchExclusions(ctx context.Context) {
+	ticker := time.NewTicker(c.exclusionsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := c.reloadExclusions()
+
+			if err != nil {
+				c.logger.Warn("重新读取排除列表文件失败",
+					"文件", c.exclusionsFile,
+					"错误", err,
+				)
+
+				continue
+			}
+
+			if changed {
+				c.logger.Info("排除列表已更新",
+					"文件", c.exclusionsFile,
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package jenkins
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadExclusions(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "exclude")
+	assert.NoError(t, os.WriteFile(file, []byte("# comment\nseed\n\nlegacy/\n"), 0o600))
+
+	jobs, folders, err := ReadExclusions(file)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"seed"}, jobs)
+	assert.Equal(t, []string{"legacy"}, folders)
+}
+
+func TestReloadExclusions(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "exclude")
+	assert.NoError(t, os.WriteFile(file, []byte("seed\n"), 0o600))
+
+	repo := newTestRepo(t)
+	assert.NoError(t, repo.SyncJobs([]string{"app", "seed", "legacy/old"}))
+	assert.NoError(t, repo.UpdateLastSeenBatch(map[string]storage.BuildUpdate{
+		"app":        {Status: "success", Timestamp: 1700000000},
+		"seed":       {Status: "success", Timestamp: 1700000000},
+		"legacy/old": {Status: "failure", Timestamp: 1700000000},
+	}))
+
+	c := NewBuildCollector(nil, repo, testLogger(), 1, WithExclusionsFile(file, 0))
+
+	changed, err := c.reloadExclusions()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	_, err = c.seedFromRepo()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, testutil.CollectAndCount(c.buildStatusGauge))
+
+	// 未修改的文件不会重新清理指标
+	changed, err = c.reloadExclusions()
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	assert.NoError(t, os.WriteFile(file, []byte("legacy/\n"), 0o600))
+
+	changed, err = c.reloadExclusions()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	assert.True(t, c.isExcluded("legacy/old"))
+	assert.False(t, c.isExcluded("seed"))
+
+	// 新排除的 job 的指标被删除
+	assert.Equal(t, 1, testutil.CollectAndCount(c.buildStatusGauge))
+	assert.Equal(t, BuildStatusValue("success"), testutil.ToFloat64(c.buildStatusGauge.WithLabelValues("app")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(c.statusTally.WithLabelValues("success")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(c.statusTally.WithLabelValues("failure")))
+}
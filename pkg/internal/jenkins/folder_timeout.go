@@ -0,0 +1,66 @@
+package jenkins
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// WithFolderTimeout configures a Client to give every folder request while
+// recursing through the folders its own timeout, so a single slow folder
+// can't consume the whole deadline of the scrape. The deadline of the parent
+// context is still respected. A timeout of 0 disables the limit.
+func WithFolderTimeout(timeout time.Duration) ClientOption {
+	return func(client *Client) error {
+		client.folderTimeout = timeout
+		return nil
+	}
+}
+
+// folderContext derives the context for the requests of a single folder.
+func (c *Client) folderContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.folderTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, c.folderTimeout)
+}
+
+// folderTimedOut checks if the folder context expired on its own, a canceled
+// or expired parent context is not caused by the folder.
+func folderTimedOut(parent, folder context.Context) bool {
+	return parent.Err() == nil && errors.Is(folder.Err(), context.DeadlineExceeded)
+}
+
+// timedOutFolders collects the folders skipped by the folder timeout.
+type timedOutFolders struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (t *timedOutFolders) add(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.names = append(t.names, name)
+}
+
+// warn logs the collected folders, if any folder has timed out.
+func (t *timedOutFolders) warn(logger *slog.Logger, timeout time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if logger == nil || len(t.names) == 0 {
+		return
+	}
+
+	sort.Strings(t.names)
+
+	logger.Warn("文件夹请求超时，已跳过",
+		"超时", timeout,
+		"文件夹", t.names,
+	)
+}
@@ -0,0 +1,89 @@
+package jenkins
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobClientAllFolderTimeout(t *testing.T) {
+	routes := map[string]string{
+		"/api/json": `{"jobs": [
+			{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "fast", "url": "$URL/job/fast/"},
+			{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "slow", "url": "$URL/job/slow/"}
+		]}`,
+		"/job/fast/api/json": `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "fast", "url": "$URL/job/fast/", "jobs": [
+			{"_class": "hudson.model.FreeStyleProject", "name": "one", "url": "$URL/job/fast/job/one/"}
+		]}`,
+		"/job/fast/job/one/api/json": `{"_class": "hudson.model.FreeStyleProject", "name": "one", "fullName": "fast/one", "url": "$URL/job/fast/job/one/"}`,
+		"/job/slow/api/json": `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "slow", "url": "$URL/job/slow/", "jobs": [
+			{"_class": "hudson.model.FreeStyleProject", "name": "two", "url": "$URL/job/slow/job/two/"}
+		]}`,
+		"/job/slow/job/two/api/json": `{"_class": "hudson.model.FreeStyleProject", "name": "two", "fullName": "slow/two", "url": "$URL/job/slow/job/two/"}`,
+	}
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 慢文件夹的响应超过单个文件夹的超时
+		if r.URL.Path == "/job/slow/api/json" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, strings.ReplaceAll(routes[r.URL.Path], "$URL", srv.URL))
+	}))
+	t.Cleanup(srv.Close)
+
+	buf := &bytes.Buffer{}
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+		WithFolderTimeout(100*time.Millisecond),
+		WithLogger(slog.New(slog.NewTextHandler(buf, nil))),
+	)
+	assert.NoError(t, err)
+
+	jobs, _, err := client.Job.All(context.Background(), nil)
+	assert.NoError(t, err)
+
+	names := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		names = append(names, job.Path)
+	}
+
+	assert.Equal(t, []string{"fast/one"}, names)
+	assert.Contains(t, buf.String(), "文件夹请求超时")
+	assert.Contains(t, buf.String(), "slow")
+	assert.NotContains(t, buf.String(), "fast")
+}
+
+func TestFolderTimedOut(t *testing.T) {
+	parent, cancelParent := context.WithCancel(context.Background())
+	defer cancelParent()
+
+	folder, cancel := context.WithTimeout(parent, time.Nanosecond)
+	defer cancel()
+	<-folder.Done()
+
+	assert.True(t, folderTimedOut(parent, folder))
+
+	// 父上下文取消时不属于文件夹超时
+	cancelParent()
+	assert.False(t, folderTimedOut(parent, folder))
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.False(t, folderTimedOut(context.Background(), canceled))
+}
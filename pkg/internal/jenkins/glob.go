@@ -0,0 +1,131 @@
+package jenkins
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// WithFolderGlob configures a Client to treat the configured folders as glob
+// patterns like "team-*" or "*/prod", which get matched against the folder
+// paths while traversing.
+func WithFolderGlob(enabled bool) ClientOption {
+	return func(client *Client) error {
+		client.folderGlob = enabled
+		return nil
+	}
+}
+
+// FolderGlob returns true if the configured folders are glob patterns.
+func (c *Client) FolderGlob() bool {
+	return c.folderGlob
+}
+
+// MatchFolders returns the folders whose path matches any of the patterns,
+// the names of the returned folders contain the full "folder/subfolder" path.
+// Every segment of a pattern gets matched against one level of the folder
+// tree, only folders matching the leading segments are traversed.
+func (c *JobClient) MatchFolders(ctx context.Context, patterns []string) ([]Folder, error) {
+	hudson, err := c.Root(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.matchFolders(ctx, hudson.Folders, patterns)
+}
+
+// matchFolders matches the patterns against the given top-level folders.
+func (c *JobClient) matchFolders(ctx context.Context, roots []Folder, patterns []string) ([]Folder, error) {
+	children := make(map[string][]Folder)
+	matched := make(map[string]Folder)
+
+	for _, pattern := range patterns {
+		segments := strings.Split(strings.Trim(pattern, "/"), "/")
+		candidates := roots
+
+		for i, segment := range segments {
+			next := make([]Folder, 0)
+
+			for _, folder := range candidates {
+				ok, err := path.Match(segment, path.Base(folder.Name))
+
+				if err != nil {
+					return nil, fmt.Errorf("invalid folder pattern %q: %w", pattern, err)
+				}
+
+				if !ok {
+					continue
+				}
+
+				if i == len(segments)-1 {
+					matched[folder.Name] = folder
+					continue
+				}
+
+				// 只有文件夹才需要继续匹配下一级
+				if !strings.Contains(folder.Class, "Folder") {
+					continue
+				}
+
+				if _, ok := children[folder.URL]; !ok {
+					listing, err := c.folderChildren(ctx, folder)
+
+					if err != nil {
+						return nil, err
+					}
+
+					children[folder.URL] = listing
+				}
+
+				next = append(next, children[folder.URL]...)
+			}
+
+			candidates = next
+		}
+	}
+
+	result := make([]Folder, 0, len(matched))
+	for _, folder := range matched {
+		result = append(result, folder)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+// folderChildren returns the direct children of a folder, their names are
+// prefixed with the path of the folder.
+func (c *JobClient) folderChildren(ctx context.Context, folder Folder) ([]Folder, error) {
+	result := Folder{}
+	req, err := c.client.NewRequest(ctx, "GET", fmt.Sprintf("%s/api/json?tree=jobs[_class,name,url]", strings.TrimRight(folder.URL, "/")), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.client.Do(req, &result); err != nil {
+		return nil, err
+	}
+
+	for i := range result.Folders {
+		result.Folders[i].Name = folder.Name + "/" + result.Folders[i].Name
+	}
+
+	return result.Folders, nil
+}
+
+// folderNames returns the names of the given folders.
+func folderNames(folders []Folder) []string {
+	names := make([]string, 0, len(folders))
+	for _, folder := range folders {
+		names = append(names, folder.Name)
+	}
+
+	return names
+}
@@ -0,0 +1,92 @@
+package jenkins
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkinstest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobClientAllFolderGlob(t *testing.T) {
+	routes := map[string]string{
+		"/api/json": `{"jobs": [
+			{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "team-a", "url": "$URL/job/team-a/"},
+			{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "infra", "url": "$URL/job/infra/"},
+			{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "legacy", "url": "$URL/job/legacy/"}
+		]}`,
+		"/job/team-a/api/json": `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "team-a", "url": "$URL/job/team-a/", "jobs": [
+			{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/team-a/job/app/"}
+		]}`,
+		"/job/team-a/job/app/api/json": `{"_class": "hudson.model.FreeStyleProject", "name": "app", "fullName": "team-a/app", "url": "$URL/job/team-a/job/app/"}`,
+		"/job/infra/api/json": `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "infra", "url": "$URL/job/infra/", "jobs": [
+			{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "prod", "url": "$URL/job/infra/job/prod/"},
+			{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "dev", "url": "$URL/job/infra/job/dev/"}
+		]}`,
+		"/job/infra/job/prod/api/json": `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "prod", "url": "$URL/job/infra/job/prod/", "jobs": [
+			{"_class": "hudson.model.FreeStyleProject", "name": "deploy", "url": "$URL/job/infra/job/prod/job/deploy/"}
+		]}`,
+		"/job/infra/job/prod/job/deploy/api/json": `{"_class": "hudson.model.FreeStyleProject", "name": "deploy", "fullName": "infra/prod/deploy", "url": "$URL/job/infra/job/prod/job/deploy/"}`,
+		"/job/infra/job/dev/api/json":             `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "dev", "url": "$URL/job/infra/job/dev/", "jobs": []}`,
+		"/job/legacy/api/json":                    `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "legacy", "url": "$URL/job/legacy/", "jobs": []}`,
+	}
+
+	var mu sync.Mutex
+	requested := make(map[string]bool)
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested[r.URL.Path+"?"+r.URL.RawQuery] = true
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, strings.ReplaceAll(routes[r.URL.Path], "$URL", srv.URL))
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+		WithFolderGlob(true),
+	)
+	assert.NoError(t, err)
+
+	jobs, _, err := client.Job.All(context.Background(), []string{"team-*", "*/prod"})
+	assert.NoError(t, err)
+
+	names := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		names = append(names, job.Path)
+	}
+
+	assert.ElementsMatch(t, []string{"team-a/app", "infra/prod/deploy"}, names)
+
+	// 不匹配的文件夹只会被列出子项，不会被遍历
+	assert.False(t, requested["/job/legacy/api/json?depth=1"])
+	assert.False(t, requested["/job/infra/api/json?depth=1"])
+	assert.False(t, requested["/job/infra/job/dev/api/json?depth=1"])
+	assert.True(t, requested["/job/infra/job/prod/api/json?depth=1"])
+}
+
+func TestCheckFoldersGlob(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json": `{"jobs": [{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "team-a", "url": "$URL/job/team-a/"}]}`,
+	})
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+		WithFolderGlob(true),
+	)
+	assert.NoError(t, err)
+
+	assert.NoError(t, CheckFolders(context.Background(), client, []string{"team-*"}))
+	assert.ErrorIs(t, CheckFolders(context.Background(), client, []string{"ops-*"}), ErrFolderNotFound)
+}
@@ -0,0 +1,50 @@
+package jenkins
+
+import (
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/promhippie/jenkins_exporter/pkg/internal/storage"
+)
+
+// newTestClient creates a client for the fake Jenkins with an initialized SDK.
+func newTestClient(t *testing.T, srv *httptest.Server) *Client {
+	t.Helper()
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.InitSDK(testLogger()); err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
+
+// newTestRepo creates a job repository backed by a temporary SQLite file.
+func newTestRepo(t *testing.T) *storage.JobRepo {
+	t.Helper()
+
+	db, err := storage.NewSQLite(filepath.Join(t.TempDir(), "jobs.db"), testLogger())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = db.Close() })
+	return storage.NewJobRepo(db, testLogger())
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
@@ -1,6 +1,29 @@
 package jenkins
 
+import (
+	"errors"
+	"net/http"
+)
+
 const (
 	// Version defines the version of this library.
 	Version = "0.1.0"
 )
+
+// ErrNeverBuilt is returned when a job exists but has never been built.
+var ErrNeverBuilt = errors.New("job has never been built")
+
+// ErrForbidden is returned when the credentials are not allowed to access
+// the requested resource.
+var ErrForbidden = errors.New(http.StatusText(http.StatusForbidden))
+
+// ErrUnauthorized is returned when Jenkins rejects the configured
+// credentials.
+var ErrUnauthorized = errors.New(http.StatusText(http.StatusUnauthorized))
+
+// ErrFolderNotFound is returned when a configured folder doesn't exist.
+var ErrFolderNotFound = errors.New("folder not found")
+
+// ErrResponseTooLarge is returned when a response exceeds the configured
+// maximum size.
+var ErrResponseTooLarge = errors.New("response too large")
@@ -1,11 +1,15 @@
 package jenkins
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 // JobClient is a client for the jobs API.
@@ -29,11 +33,10 @@ func (c *JobClient) Root(ctx context.Context) (Hudson, error) {
 	return result, nil
 }
 
-// Build returns a specific build.
-func (c *JobClient) Build(ctx context.Context, build *BuildNumber) (Build, error) {
-	result := Build{}
-	url := strings.TrimRight(build.URL, "/")
-	req, err := c.client.NewRequest(ctx, "GET", fmt.Sprintf("%s/api/json", url), nil)
+// Status returns the root API response without the job listing.
+func (c *JobClient) Status(ctx context.Context) (Hudson, error) {
+	result := Hudson{}
+	req, err := c.client.NewRequest(ctx, "GET", fmt.Sprintf("%s/api/json?tree=mode,numExecutors,quietingDown", c.client.endpoint), nil)
 
 	if err != nil {
 		return result, err
@@ -46,25 +49,152 @@ func (c *JobClient) Build(ctx context.Context, build *BuildNumber) (Build, error
 	return result, nil
 }
 
-// GetLastCompletedBuild returns the last completed build for a job by job name (full path).
-// Returns (build, buildNumber, nil) if found, or (nil, 0, nil) if no completed build exists.
-func (c *JobClient) GetLastCompletedBuild(ctx context.Context, jobName string) (*Build, int64, error) {
-	// 构建 job API URL
-	// jobName 格式可能是 "folder/job" 或 "folder/subfolder/job"
-	// 需要转换为 Jenkins API 路径格式：/job/folder/job/folder/job/...
-	// 注意：Jenkins API 路径中的 job 名称需要进行 URL 编码
-	pathParts := strings.Split(jobName, "/")
-	apiPath := ""
-	for _, part := range pathParts {
-		if part != "" {
-			// URL 编码每个部分，处理特殊字符
-			encodedPart := url.PathEscape(part)
-			apiPath += "/job/" + encodedPart
+// TopLevelNames returns the names of all top-level jobs and folders.
+func (c *JobClient) TopLevelNames(ctx context.Context) ([]string, error) {
+	result := Hudson{}
+	req, err := c.client.NewRequest(ctx, "GET", fmt.Sprintf("%s/api/json?tree=jobs[name]", c.client.endpoint), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.client.Do(req, &result); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(result.Folders))
+	for _, folder := range result.Folders {
+		names = append(names, folder.Name)
+	}
+
+	return names, nil
+}
+
+// RecentResults returns the results of the most recent builds of a job, using
+// a tree query to fetch them within a single request.
+func (c *JobClient) RecentResults(ctx context.Context, job Job, count int) ([]string, error) {
+	result := struct {
+		Builds []struct {
+			Result string `json:"result"`
+		} `json:"builds"`
+	}{}
+
+	url := strings.TrimRight(job.URL, "/")
+	req, err := c.client.NewRequest(ctx, "GET", fmt.Sprintf("%s/api/json?tree=builds[result]{0,%d}", url, count), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.client.Do(req, &result); err != nil {
+		return nil, err
+	}
+
+	results := make([]string, 0, len(result.Builds))
+	for _, build := range result.Builds {
+		results = append(results, build.Result)
+	}
+
+	return results, nil
+}
+
+// RetainedBuilds returns the number of builds retained for a job. The builds
+// field of the job response is trimmed to 100 entries, so allBuilds gets
+// requested explicitly.
+func (c *JobClient) RetainedBuilds(ctx context.Context, job Job) (int, error) {
+	result := struct {
+		Builds []struct {
+			Number int `json:"number"`
+		} `json:"allBuilds"`
+	}{}
+
+	url := strings.TrimRight(job.URL, "/")
+	req, err := c.client.NewRequest(ctx, "GET", fmt.Sprintf("%s/api/json?tree=allBuilds[number]", url), nil)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := c.client.Do(req, &result); err != nil {
+		return 0, err
+	}
+
+	return len(result.Builds), nil
+}
+
+// ParameterDefaults returns the default values of the parameters defined for
+// a job, jobs without parameters return an empty map.
+func (c *JobClient) ParameterDefaults(ctx context.Context, job Job) (map[string]interface{}, error) {
+	result := struct {
+		Property []struct {
+			ParameterDefinitions []struct {
+				Name                  string `json:"name"`
+				DefaultParameterValue *struct {
+					Value interface{} `json:"value"`
+				} `json:"defaultParameterValue"`
+			} `json:"parameterDefinitions"`
+		} `json:"property"`
+	}{}
+
+	url := strings.TrimRight(job.URL, "/")
+	req, err := c.client.NewRequest(ctx, "GET", fmt.Sprintf("%s/api/json?tree=property[parameterDefinitions[name,defaultParameterValue[value]]]", url), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.client.Do(req, &result); err != nil {
+		return nil, err
+	}
+
+	defaults := make(map[string]interface{})
+	for _, property := range result.Property {
+		for _, definition := range property.ParameterDefinitions {
+			// 部分参数类型（例如密码）没有默认值
+			if definition.DefaultParameterValue == nil {
+				defaults[definition.Name] = nil
+				continue
+			}
+
+			defaults[definition.Name] = definition.DefaultParameterValue.Value
 		}
 	}
 
+	return defaults, nil
+}
+
+// Build returns a specific build.
+func (c *JobClient) Build(ctx context.Context, build *BuildNumber) (Build, error) {
+	result, _, err := c.build(ctx, build)
+	return result, err
+}
+
+// build fetches the details of a build, the response gets returned to
+// inspect the status code.
+func (c *JobClient) build(ctx context.Context, build *BuildNumber) (Build, *Response, error) {
+	result := Build{}
+	url := strings.TrimRight(build.URL, "/")
+	req, err := c.client.NewRequest(ctx, "GET", fmt.Sprintf("%s/api/json", url), nil)
+
+	if err != nil {
+		return result, nil, err
+	}
+
+	res, err := c.client.Do(req, &result)
+
+	if err != nil {
+		return result, res, err
+	}
+
+	return result, res, nil
+}
+
+// GetLastCompletedBuild returns the last completed build for a job by job name (full path).
+// Returns (build, buildNumber, nil) if found, (nil, 0, nil) if no completed build exists yet,
+// or ErrNeverBuilt if the job exists but has never been built.
+func (c *JobClient) GetLastCompletedBuild(ctx context.Context, jobName string) (*Build, int64, error) {
 	// 获取 job 信息
-	jobURL := fmt.Sprintf("%s%s/api/json", c.client.endpoint, apiPath)
+	jobURL := fmt.Sprintf("%s%s/api/json", c.client.endpoint, jobAPIPath(jobName))
 	req, err := c.client.NewRequest(ctx, "GET", jobURL, nil)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to create request for job %s (URL: %s): %w", jobName, jobURL, err)
@@ -75,6 +205,11 @@ func (c *JobClient) GetLastCompletedBuild(ctx context.Context, jobName string) (
 		return nil, 0, fmt.Errorf("failed to get job %s (URL: %s): %w", jobName, jobURL, err)
 	}
 
+	// job 存在但从未构建过
+	if job.LastBuild == nil && job.LastCompletedBuild == nil {
+		return nil, 0, ErrNeverBuilt
+	}
+
 	// 如果没有 lastCompletedBuild，返回 nil
 	if job.LastCompletedBuild == nil {
 		return nil, 0, nil
@@ -83,7 +218,13 @@ func (c *JobClient) GetLastCompletedBuild(ctx context.Context, jobName string) (
 	buildNumber := int64(job.LastCompletedBuild.Number)
 
 	// 获取构建详情
-	build, err := c.Build(ctx, job.LastCompletedBuild)
+	build, res, err := c.build(ctx, job.LastCompletedBuild)
+
+	// 构建可能在获取 job 和获取构建之间被删除，视为没有已完成的构建
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		return nil, 0, nil
+	}
+
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get build details for job %s: %w", jobName, err)
 	}
@@ -91,13 +232,86 @@ func (c *JobClient) GetLastCompletedBuild(ctx context.Context, jobName string) (
 	return &build, buildNumber, nil
 }
 
+// Config returns the raw config.xml of a job by job name (full path).
+func (c *JobClient) Config(ctx context.Context, jobName string) (string, error) {
+	configURL := fmt.Sprintf("%s%s/config.xml", c.client.endpoint, jobAPIPath(jobName))
+	req, err := c.client.NewRequest(ctx, "GET", configURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request for job %s (URL: %s): %w", jobName, configURL, err)
+	}
+
+	body := &bytes.Buffer{}
+	if _, err := c.client.Do(req, body); err != nil {
+		return "", fmt.Errorf("failed to get config of job %s: %w", jobName, err)
+	}
+
+	return body.String(), nil
+}
+
+// jobAPIPath converts a job name in the "folder/job" format to the path of the
+// job within the Jenkins API, like /job/folder/job/job.
+func jobAPIPath(jobName string) string {
+	// 注意：Jenkins API 路径中的 job 名称需要进行 URL 编码
+	apiPath := ""
+	for _, part := range strings.Split(jobName, "/") {
+		if part != "" {
+			// URL 编码每个部分，处理特殊字符
+			apiPath += "/job/" + url.PathEscape(part)
+		}
+	}
+
+	return apiPath
+}
+
 // All returns all available jobs.
 // If folders is not empty, only jobs from the specified folders will be returned.
-func (c *JobClient) All(ctx context.Context, folders []string) ([]Job, error) {
+// Jobs reachable through overlapping folders are only returned once. Folders
+// which failed to load are skipped and counted within the returned stats, in
+// that case the job list is incomplete.
+func (c *JobClient) All(ctx context.Context, folders []string) ([]Job, RecursionStats, error) {
+	jobs, stats, err := c.all(ctx, folders)
+
+	// 重叠的文件夹（例如 team 和 team/sub）会返回重复的 job
+	jobs, duplicates := dedupeJobs(jobs)
+
+	if duplicates > 0 && c.client.logger != nil {
+		c.client.logger.Warn("移除了通过多个文件夹获取到的重复 job",
+			"重复数量", duplicates,
+			"指定文件夹", folders,
+		)
+	}
+
+	// 不匹配包含或匹配排除正则表达式的 job 不返回
+	jobs, filtered := filterJobs(jobs, c.client.jobFilter)
+
+	if filtered > 0 && c.client.logger != nil {
+		c.client.logger.Debug("按正则表达式过滤掉的 job",
+			"过滤数量", filtered,
+		)
+	}
+
+	return jobs, stats, err
+}
+
+func (c *JobClient) all(ctx context.Context, folders []string) ([]Job, RecursionStats, error) {
 	hudson, err := c.Root(ctx)
 
 	if err != nil {
-		return []Job{}, err
+		return []Job{}, RecursionStats{}, err
+	}
+
+	// 文件夹作为 glob 模式时，只遍历匹配的文件夹路径
+	if len(folders) > 0 && c.client.folderGlob {
+		matched, err := c.matchFolders(ctx, hudson.Folders, folders)
+		if err != nil {
+			return []Job{}, RecursionStats{}, err
+		}
+
+		if len(matched) == 0 {
+			return []Job{}, RecursionStats{}, fmt.Errorf("没有匹配的文件夹: %v", folders)
+		}
+
+		return c.recursiveFolders(ctx, matched)
 	}
 
 	// 如果指定了文件夹，只处理这些文件夹
@@ -125,38 +339,45 @@ func (c *JobClient) All(ctx context.Context, folders []string) ([]Job, error) {
 		if len(notFoundFolders) > 0 {
 			// 仍然继续处理找到的文件夹，但错误信息会包含警告
 			if len(filteredFolders) == 0 {
-				return []Job{}, fmt.Errorf("指定的文件夹不存在: %v (可用的顶层文件夹: %v)", folders, allTopLevelFolders)
+				return []Job{}, RecursionStats{}, fmt.Errorf("指定的文件夹不存在: %v (可用的顶层文件夹: %v)", folders, allTopLevelFolders)
 			}
 			// 如果有部分文件夹不存在，仍然处理找到的文件夹，但返回错误信息
 			// 注意：这里不返回错误，而是继续处理，让调用者决定如何处理
 		}
 
 		if len(filteredFolders) == 0 {
-			return []Job{}, fmt.Errorf("指定的文件夹不存在: %v (可用的顶层文件夹: %v)", folders, allTopLevelFolders)
+			return []Job{}, RecursionStats{}, fmt.Errorf("指定的文件夹不存在: %v (可用的顶层文件夹: %v)", folders, allTopLevelFolders)
 		}
 
-		jobs, err := c.recursiveFolders(ctx, filteredFolders)
+		jobs, stats, err := c.recursiveFolders(ctx, filteredFolders)
 		if err != nil {
-			return []Job{}, err
+			return []Job{}, stats, err
 		}
-		return jobs, nil
+		return jobs, stats, nil
 	}
 
 	// 如果没有指定文件夹，获取所有文件夹下的作业
-	jobs, err := c.recursiveFolders(ctx, hudson.Folders)
+	jobs, stats, err := c.recursiveFolders(ctx, hudson.Folders)
 
 	if err != nil {
-		return []Job{}, err
+		return []Job{}, stats, err
 	}
 
-	return jobs, nil
+	return jobs, stats, nil
 }
 
-func (c *JobClient) recursiveFolders(ctx context.Context, folders []Folder) ([]Job, error) {
-	return c.recursiveFoldersParallel(ctx, folders, 10) // 最多10个并发
+func (c *JobClient) recursiveFolders(ctx context.Context, folders []Folder) ([]Job, RecursionStats, error) {
+	truncated := &truncatedFolders{}
+	timedOut := &timedOutFolders{}
+	failed := &atomic.Int64{}
+	jobs, err := c.recursiveFoldersParallel(ctx, folders, 1, truncated, timedOut, failed, 10) // 最多10个并发
+	truncated.warn(c.client.logger, c.client.folderDepth)
+	timedOut.warn(c.client.logger, c.client.folderTimeout)
+
+	return jobs, RecursionStats{FailedFolders: int(failed.Load())}, err
 }
 
-func (c *JobClient) recursiveFoldersParallel(ctx context.Context, folders []Folder, maxConcurrency int) ([]Job, error) {
+func (c *JobClient) recursiveFoldersParallel(ctx context.Context, folders []Folder, level int, truncated *truncatedFolders, timedOut *timedOutFolders, failed *atomic.Int64, maxConcurrency int) ([]Job, error) {
 	if len(folders) == 0 {
 		return []Job{}, nil
 	}
@@ -185,6 +406,22 @@ func (c *JobClient) recursiveFoldersParallel(ctx context.Context, folders []Fold
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
+			// 超过最大深度的文件夹不再请求
+			if strings.Contains(f.Class, "Folder") && exceedsDepth(level, c.client.folderDepth) {
+				truncated.add(folderPath(f))
+				return
+			}
+
+			// 每个文件夹使用单独的超时，避免一个慢文件夹耗尽整个采集的时间
+			fctx, cancel := c.client.folderContext(ctx)
+			defer cancel()
+
+			defer func() {
+				if folderTimedOut(ctx, fctx) {
+					timedOut.add(folderPath(f))
+				}
+			}()
+
 			var jobs []Job
 			var err error
 
@@ -192,17 +429,19 @@ func (c *JobClient) recursiveFoldersParallel(ctx context.Context, folders []Fold
 			// 这样可以处理所有类型的文件夹，不仅仅是 com.cloudbees.hudson.plugins.folder.Folder
 			// 注意：depth=1 只获取直接子项，不会递归获取所有层级
 			url := strings.TrimRight(f.URL, "/")
-			req, reqErr := c.client.NewRequest(ctx, "GET", fmt.Sprintf("%s/api/json?depth=1", url), nil)
+			req, reqErr := c.client.NewRequest(fctx, "GET", fmt.Sprintf("%s/api/json?depth=1", url), nil)
 
 			if reqErr != nil {
 				// 如果请求失败，尝试作为作业处理
-				req, reqErr = c.client.NewRequest(ctx, "GET", fmt.Sprintf("%s/api/json", url), nil)
+				req, reqErr = c.client.NewRequest(fctx, "GET", fmt.Sprintf("%s/api/json", url), nil)
 				if reqErr != nil {
+					failed.Add(1)
 					return // 跳过
 				}
 
 				job := Job{}
 				if _, reqErr := c.client.Do(req, &job); reqErr != nil {
+					failed.Add(1)
 					return // 跳过
 				}
 
@@ -210,15 +449,25 @@ func (c *JobClient) recursiveFoldersParallel(ctx context.Context, folders []Fold
 			} else {
 				// 尝试作为文件夹处理
 				nextFolder := Folder{}
-				if _, reqErr := c.client.Do(req, &nextFolder); reqErr != nil {
+				if _, reqErr := c.client.Do(req, &nextFolder); errors.Is(reqErr, ErrUnauthorized) {
+					// 凭据错误时所有请求都会失败，不能当作空文件夹跳过
+					errMu.Lock()
+					if firstErr == nil {
+						firstErr = reqErr
+					}
+					errMu.Unlock()
+					return
+				} else if reqErr != nil {
 					// 如果解析失败，尝试作为作业处理
-					req, reqErr = c.client.NewRequest(ctx, "GET", fmt.Sprintf("%s/api/json", url), nil)
+					req, reqErr = c.client.NewRequest(fctx, "GET", fmt.Sprintf("%s/api/json", url), nil)
 					if reqErr != nil {
+						failed.Add(1)
 						return // 跳过
 					}
 
 					job := Job{}
 					if _, reqErr := c.client.Do(req, &job); reqErr != nil {
+						failed.Add(1)
 						return // 跳过
 					}
 
@@ -236,7 +485,10 @@ func (c *JobClient) recursiveFoldersParallel(ctx context.Context, folders []Fold
 						// 即使文件夹为空，也要继续处理，因为可能有作业在下一层
 						if len(nextFolder.Folders) > 0 {
 							// 有子文件夹或作业，递归处理所有内容
-							jobs, err = c.recursiveFoldersParallel(ctx, nextFolder.Folders, maxConcurrency)
+							// 子文件夹使用各自的超时，当前文件夹的请求已经完成
+							cancel()
+
+							jobs, err = c.recursiveFoldersParallel(ctx, nextFolder.Folders, level+1, truncated, timedOut, failed, maxConcurrency)
 							if err != nil {
 								errMu.Lock()
 								if firstErr == nil {
@@ -250,13 +502,15 @@ func (c *JobClient) recursiveFoldersParallel(ctx context.Context, folders []Fold
 					} else {
 						// 这是作业，直接获取作业详情
 						// 即使 _class 不是明确的作业类型，只要不是文件夹，就当作作业处理
-						req, reqErr := c.client.NewRequest(ctx, "GET", fmt.Sprintf("%s/api/json", url), nil)
+						req, reqErr := c.client.NewRequest(fctx, "GET", fmt.Sprintf("%s/api/json", url), nil)
 						if reqErr != nil {
+							failed.Add(1)
 							return // 跳过
 						}
 
 						job := Job{}
 						if _, reqErr := c.client.Do(req, &job); reqErr != nil {
+							failed.Add(1)
 							return // 跳过
 						}
 
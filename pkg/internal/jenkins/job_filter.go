@@ -0,0 +1,98 @@
+package jenkins
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// JobFilter includes or excludes jobs by regular expressions matched against
+// the full path of the job, the exclude pattern wins if both match.
+type JobFilter struct {
+	include *regexp.Regexp
+	exclude *regexp.Regexp
+}
+
+// NewJobFilter compiles the include and exclude patterns, empty patterns are
+// ignored. It returns nil if both patterns are empty.
+func NewJobFilter(include, exclude string) (*JobFilter, error) {
+	if include == "" && exclude == "" {
+		return nil, nil
+	}
+
+	filter := &JobFilter{}
+
+	if include != "" {
+		re, err := regexp.Compile(include)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid include job regex %q: %w", include, err)
+		}
+
+		filter.include = re
+	}
+
+	if exclude != "" {
+		re, err := regexp.Compile(exclude)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude job regex %q: %w", exclude, err)
+		}
+
+		filter.exclude = re
+	}
+
+	return filter, nil
+}
+
+// Match checks if the job with the given full path should be collected, a nil
+// filter matches all jobs.
+func (f *JobFilter) Match(path string) bool {
+	if f == nil {
+		return true
+	}
+
+	if f.exclude != nil && f.exclude.MatchString(path) {
+		return false
+	}
+
+	return f.include == nil || f.include.MatchString(path)
+}
+
+// WithJobFilter configures a Client to only return the jobs matching the
+// filter, it's applied to the job list and the Discovery of the SQLite mode.
+func WithJobFilter(filter *JobFilter) ClientOption {
+	return func(client *Client) error {
+		client.jobFilter = filter
+		return nil
+	}
+}
+
+// JobFilter returns the configured job filter, nil if not configured.
+func (c *Client) JobFilter() *JobFilter {
+	return c.jobFilter
+}
+
+// filterJobs removes the jobs not matching the filter, it returns the
+// remaining jobs and the number of removed jobs.
+func filterJobs(jobs []Job, filter *JobFilter) ([]Job, int) {
+	if filter == nil {
+		return jobs, 0
+	}
+
+	result := make([]Job, 0, len(jobs))
+
+	for _, job := range jobs {
+		path := job.Path
+
+		if names := jobNamesFromURL(job.URL); len(names) > 0 {
+			path = strings.Join(names, "/")
+		}
+
+		if filter.Match(path) {
+			result = append(result, job)
+		}
+	}
+
+	return result, len(jobs) - len(result)
+}
@@ -0,0 +1,91 @@
+package jenkins
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkinstest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobFilter(t *testing.T) {
+	filter, err := NewJobFilter(`^team/`, `-sandbox$`)
+	assert.NoError(t, err)
+
+	assert.True(t, filter.Match("team/app"))
+	assert.False(t, filter.Match("other/app"))
+
+	// 同时匹配时排除优先
+	assert.False(t, filter.Match("team/app-sandbox"))
+
+	filter, err = NewJobFilter("", `-sandbox$`)
+	assert.NoError(t, err)
+	assert.True(t, filter.Match("other/app"))
+	assert.False(t, filter.Match("other/app-sandbox"))
+
+	filter, err = NewJobFilter("", "")
+	assert.NoError(t, err)
+	assert.Nil(t, filter)
+	assert.True(t, filter.Match("anything"))
+
+	_, err = NewJobFilter(`team/(`, "")
+	assert.ErrorContains(t, err, "invalid include job regex")
+
+	_, err = NewJobFilter("", `[`)
+	assert.ErrorContains(t, err, "invalid exclude job regex")
+}
+
+func TestJobClientAllJobFilter(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json": `{"jobs": [
+			{"_class": "hudson.model.FreeStyleProject", "name": "app", "url": "$URL/job/app/"},
+			{"_class": "hudson.model.FreeStyleProject", "name": "app-sandbox", "url": "$URL/job/app-sandbox/"}
+		]}`,
+		"/job/app/api/json":         `{"_class": "hudson.model.FreeStyleProject", "name": "app", "fullName": "app", "url": "$URL/job/app/"}`,
+		"/job/app-sandbox/api/json": `{"_class": "hudson.model.FreeStyleProject", "name": "app-sandbox", "fullName": "app-sandbox", "url": "$URL/job/app-sandbox/"}`,
+	})
+
+	filter, err := NewJobFilter("", `-sandbox$`)
+	assert.NoError(t, err)
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+		WithJobFilter(filter),
+	)
+	assert.NoError(t, err)
+
+	jobs, _, err := client.Job.All(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, "app", jobs[0].Path)
+}
+
+func TestSyncJobsOnceJobFilter(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":                          `{"jobs": [{"name": "team"}]}`,
+		"/job/team/api/json":                 `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "team", "jobs": [{"_class": "hudson.model.FreeStyleProject", "name": "app"}, {"_class": "hudson.model.FreeStyleProject", "name": "app-sandbox"}]}`,
+		"/job/team/job/app/api/json":         `{"_class": "hudson.model.FreeStyleProject", "name": "app"}`,
+		"/job/team/job/app-sandbox/api/json": `{"_class": "hudson.model.FreeStyleProject", "name": "app-sandbox"}`,
+	})
+
+	filter, err := NewJobFilter(`^team/`, `-sandbox$`)
+	assert.NoError(t, err)
+
+	client := newTestClient(t, srv)
+	client.jobFilter = filter
+
+	repo := newTestRepo(t)
+	metrics := NewDiscoveryMetrics()
+
+	assert.NoError(t, syncJobsOnce(context.Background(), client, repo, nil, nil, nil, metrics, testLogger()))
+
+	jobs, err := repo.ListEnabledJobs()
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, "team/job/app", jobs[0].JobName)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.excluded))
+}
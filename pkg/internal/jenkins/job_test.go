@@ -0,0 +1,28 @@
+package jenkins
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkinstest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLastCompletedBuildDeleted(t *testing.T) {
+	// 构建 7 在获取 job 之后被删除，构建地址返回 404
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/job/app/api/json": `{"_class": "hudson.model.FreeStyleProject", "name": "app", "lastBuild": {"number": 7, "url": "$URL/job/app/7/"}, "lastCompletedBuild": {"number": 7, "url": "$URL/job/app/7/"}}`,
+	})
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+	)
+	assert.NoError(t, err)
+
+	build, number, err := client.Job.GetLastCompletedBuild(context.Background(), "app")
+	assert.NoError(t, err)
+	assert.Nil(t, build)
+	assert.Equal(t, int64(0), number)
+}
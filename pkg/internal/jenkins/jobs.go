@@ -0,0 +1,83 @@
+package jenkins
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/storage"
+)
+
+// JobStatus defines the current status of a job, it's served as JSON for
+// tooling which doesn't want to parse the Prometheus exposition.
+type JobStatus struct {
+	JobName   string     `json:"job_name"`
+	Status    string     `json:"status"`
+	Commit    string     `json:"commit"`
+	Branch    string     `json:"branch"`
+	LastBuild int64      `json:"last_build"`
+	LastSeen  *time.Time `json:"last_seen"`
+}
+
+// Statuses returns the current status of all enabled jobs, based on the
+// database and the current gauge values without requesting Jenkins. The
+// status is empty for jobs which haven't been collected yet.
+func (c *BuildCollector) Statuses() ([]JobStatus, error) {
+	jobs, err := c.repo.ListEnabledJobs()
+	if err != nil {
+		return nil, err
+	}
+
+	results := c.lastResults()
+	statuses := make([]JobStatus, 0, len(jobs))
+
+	for _, job := range jobs {
+		result := results[job.JobName]
+
+		statuses = append(statuses, JobStatus{
+			JobName:   job.JobName,
+			Status:    result["status"],
+			Commit:    result["check_commitID"],
+			Branch:    result["gitBranch"],
+			LastBuild: job.LastSeenBuild,
+			LastSeen:  job.LastSyncTime,
+		})
+	}
+
+	return statuses, nil
+}
+
+// AllJobs returns all jobs known to the database, including the jobs which
+// have been disabled by Discovery.
+func (c *BuildCollector) AllJobs() ([]storage.Job, error) {
+	return c.repo.ListAllJobs()
+}
+
+// lastResults returns the labels of the last build result gauges by job name.
+func (c *BuildCollector) lastResults() map[string]map[string]string {
+	metrics := make(chan prometheus.Metric)
+	go func() {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+
+		c.buildResultGauge.Collect(metrics)
+		close(metrics)
+	}()
+
+	results := make(map[string]map[string]string)
+	for metric := range metrics {
+		m := &dto.Metric{}
+		if err := metric.Write(m); err != nil {
+			continue
+		}
+
+		labels := make(map[string]string, len(m.GetLabel()))
+		for _, label := range m.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+
+		results[labels["job_name"]] = labels
+	}
+
+	return results
+}
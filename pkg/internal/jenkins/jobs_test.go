@@ -0,0 +1,39 @@
+package jenkins
+
+import (
+	"context"
+	"testing"
+
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkinstest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildCollectorStatuses(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":           `{"jobs": []}`,
+		"/job/app/api/json":   `{"_class": "hudson.model.FreeStyleProject", "name": "app", "lastBuild": {"number": 3, "url": "$URL/job/app/3/"}, "lastCompletedBuild": {"number": 3, "url": "$URL/job/app/3/"}}`,
+		"/job/app/3/api/json": `{"number": 3, "result": "FAILURE", "building": false, "duration": 1000, "actions": [{"_class": "hudson.model.ParametersAction", "parameters": [{"name": "check_commitID", "value": "abc123"}, {"name": "gitBranch", "value": "main"}]}]}`,
+	})
+
+	repo := newTestRepo(t)
+	assert.NoError(t, repo.SyncJobs([]string{"app", "fresh"}))
+
+	c := NewBuildCollector(newTestClient(t, srv), repo, testLogger(), 1)
+
+	assert.NoError(t, c.collectOnce(context.Background()))
+
+	statuses, err := c.Statuses()
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 2)
+
+	assert.Equal(t, "app", statuses[0].JobName)
+	assert.Equal(t, "failure", statuses[0].Status)
+	assert.Equal(t, "abc123", statuses[0].Commit)
+	assert.Equal(t, "main", statuses[0].Branch)
+	assert.Equal(t, int64(3), statuses[0].LastBuild)
+	assert.NotNil(t, statuses[0].LastSeen)
+
+	// 尚未采集的 job 没有状态
+	assert.Equal(t, "fresh", statuses[1].JobName)
+	assert.Equal(t, "", statuses[1].Status)
+}
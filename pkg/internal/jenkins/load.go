@@ -0,0 +1,28 @@
+package jenkins
+
+import (
+	"context"
+	"fmt"
+)
+
+// LoadClient is a client for the overall load API.
+type LoadClient struct {
+	client *Client
+}
+
+// Overall returns the load statistics of the whole instance, a tree query is
+// used as the time series are omitted on the default depth.
+func (c *LoadClient) Overall(ctx context.Context) (OverallLoad, error) {
+	result := OverallLoad{}
+	req, err := c.client.NewRequest(ctx, "GET", fmt.Sprintf("%s/overallLoad/api/json?tree=busyExecutors[sec10[latest]],queueLength[sec10[latest]]", c.client.endpoint), nil)
+
+	if err != nil {
+		return result, err
+	}
+
+	if _, err := c.client.Do(req, &result); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,10 @@
+package jenkins
+
+import (
+	"log/slog"
+)
+
+// LevelTrace is the level of the per-job debug messages, which dominate the
+// output on instances with many jobs. It's only enabled by the debug log level
+// to keep the other levels readable.
+const LevelTrace = slog.LevelDebug - 4
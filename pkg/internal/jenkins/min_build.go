@@ -0,0 +1,16 @@
+package jenkins
+
+// WithMinBuildNumber configures a minimum build number, builds with a lower
+// number are treated like no build exists yet, e.g. for jobs migrated from
+// another system. A number of 0 disables the limit.
+func WithMinBuildNumber(number int64) BuildCollectorOption {
+	return func(c *BuildCollector) {
+		c.minBuild = number
+	}
+}
+
+// belowMinBuild checks if the build number is lower than the minimum build
+// number.
+func (c *BuildCollector) belowMinBuild(number int64) bool {
+	return c.minBuild > 0 && number < c.minBuild
+}
@@ -0,0 +1,57 @@
+package jenkins
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	// paramLabelName matches the valid Prometheus label names.
+	paramLabelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// WithParamLabels configures build parameters which get exported as
+// additional labels of the last build result metric, missing parameters are
+// exported with an empty value.
+func WithParamLabels(names []string) BuildCollectorOption {
+	return func(c *BuildCollector) {
+		c.paramLabels = names
+	}
+}
+
+// ValidateParamLabels checks that the parameter names are usable as label
+// names and don't collide with the labels of the last build result metric.
+func ValidateParamLabels(names []string) error {
+	reserved := BuildLastResultLabels(true, nil)
+
+	for idx, name := range names {
+		if !paramLabelName.MatchString(name) || strings.HasPrefix(name, "__") {
+			return fmt.Errorf("parameter %q is not a valid label name", name)
+		}
+
+		if slices.Contains(reserved, name) {
+			return fmt.Errorf("parameter %q collides with an existing label", name)
+		}
+
+		if slices.Contains(names[:idx], name) {
+			return fmt.Errorf("parameter %q is configured more than once", name)
+		}
+	}
+
+	return nil
+}
+
+// ParamLabelValues returns the values of the configured parameters in their
+// order, missing parameters result in an empty value to keep the label set
+// stable.
+func ParamLabelValues(names []string, params map[string]string) []string {
+	values := make([]string, 0, len(names))
+
+	for _, name := range names {
+		values = append(values, params[name])
+	}
+
+	return values
+}
@@ -0,0 +1,43 @@
+package jenkins
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkinstest"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateParamLabels(t *testing.T) {
+	assert.NoError(t, ValidateParamLabels(nil))
+	assert.NoError(t, ValidateParamLabels([]string{"DEPLOY_ENV", "SERVICE"}))
+
+	assert.ErrorContains(t, ValidateParamLabels([]string{"deploy-env"}), "not a valid label name")
+	assert.ErrorContains(t, ValidateParamLabels([]string{"__name"}), "not a valid label name")
+	assert.ErrorContains(t, ValidateParamLabels([]string{"status"}), "collides")
+	assert.ErrorContains(t, ValidateParamLabels([]string{"SERVICE", "SERVICE"}), "more than once")
+}
+
+func TestProcessJobParamLabels(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":           `{"jobs": []}`,
+		"/job/app/api/json":   `{"_class": "hudson.model.FreeStyleProject", "name": "app", "lastBuild": {"number": 3, "url": "$URL/job/app/3/"}, "lastCompletedBuild": {"number": 3, "url": "$URL/job/app/3/"}}`,
+		"/job/app/3/api/json": `{"number": 3, "result": "SUCCESS", "building": false, "timestamp": 1700000000000, "duration": 1000, "actions": [{"_class": "hudson.model.ParametersAction", "parameters": [{"name": "DEPLOY_ENV", "value": "prod"}, {"name": "gitBranch", "value": "main"}]}]}`,
+	})
+
+	c := NewBuildCollector(newTestClient(t, srv), newTestRepo(t), testLogger(), 1, WithParamLabels([]string{"DEPLOY_ENV", "SERVICE"}))
+
+	_, err := c.processJob(context.Background(), storage.Job{JobName: "app"})
+	assert.NoError(t, err)
+
+	expected := `
+# HELP jenkins_build_last_result ` + BuildLastResultHelp + `
+# TYPE jenkins_build_last_result gauge
+jenkins_build_last_result{DEPLOY_ENV="prod",SERVICE="",check_commitID="",gitBranch="main",job_name="app",status="success"} 1
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c.buildResultGauge, strings.NewReader(expected)))
+}
@@ -0,0 +1,45 @@
+package jenkins
+
+import (
+	"net/url"
+	"strings"
+)
+
+// isAmbiguousName checks if a single job name contains the path separator.
+// Stored job paths and API paths are joined by "/", so such names can't be
+// represented without corrupting the path.
+func isAmbiguousName(name string) bool {
+	return strings.Contains(name, "/")
+}
+
+// jobNamesFromURL extracts the job names from the URL of a job, every name
+// follows a "job" segment. In contrast to splitting the full name by "/" the
+// names are taken from the escaped URL segments, so a name containing a slash
+// stays a single name.
+func jobNamesFromURL(jobURL string) []string {
+	parsed, err := url.Parse(jobURL)
+
+	if err != nil {
+		return nil
+	}
+
+	segments := strings.Split(strings.Trim(parsed.EscapedPath(), "/"), "/")
+	names := make([]string, 0, len(segments)/2)
+
+	for i := 0; i+1 < len(segments); i++ {
+		if segments[i] != "job" {
+			continue
+		}
+
+		name, err := url.PathUnescape(segments[i+1])
+
+		if err != nil {
+			return nil
+		}
+
+		names = append(names, name)
+		i++
+	}
+
+	return names
+}
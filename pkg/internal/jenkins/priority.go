@@ -0,0 +1,43 @@
+package jenkins
+
+import (
+	"slices"
+	"strings"
+)
+
+// WithPriorityJobs configures path prefixes of jobs or folders which get
+// processed before all other jobs, earlier prefixes take precedence.
+func WithPriorityJobs(prefixes []string) BuildCollectorOption {
+	return func(c *BuildCollector) {
+		c.priorityJobs = prefixes
+	}
+}
+
+// PrioritizeJobs orders the jobs by the first matching priority prefix of
+// their full path, jobs without matching prefix follow in their original
+// order. The given slice is not modified.
+func PrioritizeJobs[T any](jobs []T, path func(T) string, prefixes []string) []T {
+	if len(prefixes) == 0 {
+		return jobs
+	}
+
+	result := slices.Clone(jobs)
+
+	slices.SortStableFunc(result, func(a, b T) int {
+		return priority(path(a), prefixes) - priority(path(b), prefixes)
+	})
+
+	return result
+}
+
+// priority returns the index of the first prefix matching the path, or the
+// number of prefixes if none matches.
+func priority(path string, prefixes []string) int {
+	for idx, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return idx
+		}
+	}
+
+	return len(prefixes)
+}
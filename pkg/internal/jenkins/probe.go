@@ -0,0 +1,153 @@
+package jenkins
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// probeCollector exports the last build result of a single job, it gets
+// fetched from Jenkins on every collect.
+type probeCollector struct {
+	collector *BuildCollector
+	ctx       context.Context
+	job       string
+
+	success     *prometheus.Desc
+	duration    *prometheus.Desc
+	buildResult *prometheus.Desc
+	buildStatus *prometheus.Desc
+}
+
+// Probe returns a collector exporting the last build result of the given job
+// in the "folder/job" format, it's intended to be registered within a fresh
+// registry per request.
+func (c *BuildCollector) Probe(ctx context.Context, jobName string) prometheus.Collector {
+	return &probeCollector{
+		collector: c,
+		ctx:       ctx,
+		job:       jobName,
+
+		success: prometheus.NewDesc(
+			"jenkins_probe_success",
+			"1 if the last build of the probed job could be fetched, 0 otherwise",
+			nil,
+			nil,
+		),
+		duration: prometheus.NewDesc(
+			"jenkins_probe_duration_seconds",
+			"Duration of the probe in seconds",
+			nil,
+			nil,
+		),
+		buildResult: prometheus.NewDesc(
+			"jenkins_build_last_result",
+			BuildLastResultHelp,
+			BuildLastResultLabels(c.resultLabel, c.paramLabels),
+			nil,
+		),
+		buildStatus: prometheus.NewDesc(
+			"jenkins_job_build_status",
+			BuildStatusHelp,
+			[]string{"job_name"},
+			nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (p *probeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- p.success
+	ch <- p.duration
+	ch <- p.buildResult
+	ch <- p.buildStatus
+}
+
+// Collect implements prometheus.Collector.
+func (p *probeCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	status, details, err := p.probe()
+
+	ch <- prometheus.MustNewConstMetric(
+		p.duration,
+		prometheus.GaugeValue,
+		time.Since(start).Seconds(),
+	)
+
+	if err != nil {
+		p.collector.logger.Warn("探测 job 失败",
+			"job_name", p.job,
+			"错误", err,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			p.success,
+			prometheus.GaugeValue,
+			0.0,
+		)
+
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		p.success,
+		prometheus.GaugeValue,
+		1.0,
+	)
+
+	// 探测结果使用与采集器相同的标签，包括 result 和参数标签
+	commit, branch := commitAndBranch(details.Parameters)
+	values := []string{p.job, commit, branch, status}
+
+	if p.collector.resultLabel {
+		values = append(values, details.Result)
+	}
+
+	values = append(values, ParamLabelValues(p.collector.paramLabels, details.Parameters)...)
+
+	ch <- prometheus.MustNewConstMetric(
+		p.buildResult,
+		prometheus.GaugeValue,
+		1.0,
+		values...,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		p.buildStatus,
+		prometheus.GaugeValue,
+		BuildStatusValue(status),
+		p.job,
+	)
+}
+
+// probe fetches the last completed build of the job and returns its status
+// together with the build details, jobs without a completed build get empty
+// details.
+func (p *probeCollector) probe() (string, *BuildDetails, error) {
+	c := p.collector
+
+	if err := c.client.InitBackend(c.logger); err != nil {
+		return "", nil, err
+	}
+
+	lastCompletedBuild := c.sdkLastCompletedBuild
+	if !c.client.UseSDK() {
+		lastCompletedBuild = c.restLastCompletedBuild
+	}
+
+	// 探测参数使用 "folder/job" 格式，需要转换为 SDK 格式
+	details, _, err := lastCompletedBuild(p.ctx, convertJobPathForSDK(p.job))
+
+	// 从未构建或没有已完成的构建，视为 not_built
+	if errors.Is(err, ErrNeverBuilt) || (err == nil && details == nil) {
+		return "not_built", &BuildDetails{}, nil
+	}
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return parseBuildStatus(details.Result, details.Building, details.Duration, c.buildingResults, c.emptyResult), details, nil
+}
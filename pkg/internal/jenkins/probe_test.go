@@ -0,0 +1,53 @@
+package jenkins
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/promhippie/jenkins_exporter/pkg/internal/jenkinstest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildCollectorProbe(t *testing.T) {
+	srv := jenkinstest.NewServer(t, map[string]string{
+		"/api/json":                    `{"jobs": []}`,
+		"/job/team/api/json":           `{"_class": "com.cloudbees.hudson.plugins.folder.Folder", "name": "team"}`,
+		"/job/team/job/app/api/json":   `{"_class": "hudson.model.FreeStyleProject", "name": "app", "lastBuild": {"number": 5, "url": "$URL/job/team/job/app/5/"}, "lastCompletedBuild": {"number": 5, "url": "$URL/job/team/job/app/5/"}}`,
+		"/job/team/job/app/5/api/json": `{"number": 5, "result": "FAILURE", "building": false, "actions": [{"_class": "hudson.model.ParametersAction", "parameters": [{"name": "gitBranch", "value": "main"}, {"name": "SERVICE", "value": "api"}]}]}`,
+	})
+
+	c := NewBuildCollector(newTestClient(t, srv), nil, testLogger(), 1)
+
+	expected := `
+# HELP jenkins_build_last_result Last build result: 1 indicates current status, status label contains the actual status (success, failure, aborted, unstable, in_progress, waiting, not_built, unknown)
+# TYPE jenkins_build_last_result gauge
+jenkins_build_last_result{check_commitID="",gitBranch="main",job_name="team/app",status="failure"} 1
+# HELP jenkins_probe_success 1 if the last build of the probed job could be fetched, 0 otherwise
+# TYPE jenkins_probe_success gauge
+jenkins_probe_success 1
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c.Probe(context.Background(), "team/app"), strings.NewReader(expected), "jenkins_build_last_result", "jenkins_probe_success"))
+
+	// 不存在的 job 探测失败
+	expected = `
+# HELP jenkins_probe_success 1 if the last build of the probed job could be fetched, 0 otherwise
+# TYPE jenkins_probe_success gauge
+jenkins_probe_success 0
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c.Probe(context.Background(), "team/missing"), strings.NewReader(expected), "jenkins_build_last_result", "jenkins_probe_success"))
+
+	// 探测结果与采集器使用相同的 result 和参数标签
+	c = NewBuildCollector(newTestClient(t, srv), nil, testLogger(), 1, WithResultLabel(true), WithParamLabels([]string{"DEPLOY_ENV", "SERVICE"}))
+
+	expected = `
+# HELP jenkins_build_last_result Last build result: 1 indicates current status, status label contains the actual status (success, failure, aborted, unstable, in_progress, waiting, not_built, unknown)
+# TYPE jenkins_build_last_result gauge
+jenkins_build_last_result{DEPLOY_ENV="",SERVICE="api",check_commitID="",gitBranch="main",job_name="team/app",result="FAILURE",status="failure"} 1
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(c.Probe(context.Background(), "team/app"), strings.NewReader(expected), "jenkins_build_last_result"))
+}
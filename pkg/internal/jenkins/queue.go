@@ -0,0 +1,65 @@
+package jenkins
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// queueTree limits the queue response to the fields of QueueItem.
+const queueTree = "items[id,why,blocked,stuck,inQueueSince,task[name,url]]"
+
+// QueueClient is a client for the queue API.
+type QueueClient struct {
+	client *Client
+}
+
+// All returns all items waiting in the build queue, only the required fields
+// get requested to keep the response small on large instances.
+func (c *QueueClient) All(ctx context.Context) (Queue, error) {
+	result := Queue{}
+	req, err := c.client.NewRequest(ctx, "GET", fmt.Sprintf("%s/queue/api/json?tree=%s", c.client.endpoint, queueTree), nil)
+
+	if err != nil {
+		return result, err
+	}
+
+	if _, err := c.client.Do(req, &result); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// JobName returns the full path of the queued job, it gets derived from the
+// task URL and falls back to the task name.
+func (i QueueItem) JobName() string {
+	parsed, err := url.Parse(i.Task.URL)
+
+	if err != nil || !strings.Contains(parsed.Path, "/job/") {
+		return i.Task.Name
+	}
+
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	names := make([]string, 0, len(parts)/2)
+
+	for idx := 0; idx+1 < len(parts); idx++ {
+		if parts[idx] == "job" {
+			name, err := url.PathUnescape(parts[idx+1])
+
+			if err != nil {
+				name = parts[idx+1]
+			}
+
+			names = append(names, name)
+			idx++
+		}
+	}
+
+	if len(names) == 0 {
+		return i.Task.Name
+	}
+
+	return strings.Join(names, "/")
+}
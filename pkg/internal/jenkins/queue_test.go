@@ -0,0 +1,44 @@
+package jenkins
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTreeTestServer serves the given body and records the requested tree.
+func newTreeTestServer(t *testing.T, body string, tree *string) *Client {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*tree = r.URL.Query().Get("tree")
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+	)
+	assert.NoError(t, err)
+
+	return client
+}
+
+func TestQueueAllRequestsTree(t *testing.T) {
+	var tree string
+	client := newTreeTestServer(t, `{"items": [{"id": 7, "why": "Waiting for next available executor", "inQueueSince": 1714564800000, "task": {"name": "app", "url": "https://ci.example.com/job/team/job/app/"}}]}`, &tree)
+
+	queue, err := client.Queue.All(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, queueTree, tree)
+	assert.Len(t, queue.Items, 1)
+	assert.Equal(t, int64(1714564800000), queue.Items[0].InQueueSince)
+	assert.Equal(t, "team/app", queue.Items[0].JobName())
+}
@@ -0,0 +1,53 @@
+package jenkins
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// WithResultLabel configures the last build result metric to carry the result
+// reported by Jenkins, like UNSTABLE or NOT_BUILT, within the result label in
+// addition to the normalized status.
+func WithResultLabel(enabled bool) BuildCollectorOption {
+	return func(c *BuildCollector) {
+		c.resultLabel = enabled
+	}
+}
+
+// setBuildResult replaces the last build result metric of the job, the raw
+// result is only used if the result label is enabled and the params only for
+// the configured parameter labels. If the series guard drops the new series
+// the previous series of the job is kept. The caller has to hold the metrics
+// lock.
+func (c *BuildCollector) setBuildResult(jobName, commitID, branch, status, result string, params map[string]string) {
+	values := []string{jobName, commitID, branch, status}
+
+	if c.resultLabel {
+		values = append(values, result)
+	}
+
+	values = append(values, ParamLabelValues(c.paramLabels, params)...)
+
+	// 超过最大序列数量时不再添加新的序列，已有的序列继续更新
+	if !c.resultSeries.allow(jobName, values, c.clock.Now()) {
+		c.cardinality.WithLabelValues("jenkins_build_last_result").Inc()
+
+		if c.resultSeries.limited() {
+			c.logger.Warn("构建结果指标达到最大序列数量，不再添加新的序列",
+				"最大序列数量", c.resultSeries.limit,
+				"job_name", jobName,
+			)
+		}
+
+		return
+	}
+
+	c.deleteBuildResult(jobName)
+	c.buildResultGauge.WithLabelValues(values...).Set(1.0)
+}
+
+// deleteBuildResult removes the last build result metric of the job, the
+// series still count against the limit of the series guard until the series
+// window passed. The caller has to hold the metrics lock.
+func (c *BuildCollector) deleteBuildResult(jobName string) {
+	c.buildResultGauge.DeletePartialMatch(prometheus.Labels{"job_name": jobName})
+}
@@ -0,0 +1,93 @@
+package jenkins
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// WithRetry configures a Client to retry GET requests on server errors and
+// network errors, up to the given number of attempts with exponential
+// backoff starting at the base delay. Both the REST client and the SDK retry
+// their requests, all attempts share the timeout of the client. Disabled if
+// attempts is at most 1.
+func WithRetry(attempts int, baseDelay time.Duration) ClientOption {
+	return func(client *Client) error {
+		client.retryAttempts = attempts
+		client.retryDelay = baseDelay
+		return nil
+	}
+}
+
+// retryTransport retries the idempotent requests of both the REST client and
+// the SDK which failed transiently.
+type retryTransport struct {
+	base     http.RoundTripper
+	attempts int
+	delay    time.Duration
+}
+
+// RoundTrip implements http.RoundTripper, the last response or error gets
+// returned if all attempts fail.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	if req.Method != http.MethodGet {
+		return base.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+
+	for attempt := 1; ; attempt++ {
+		res, err := base.RoundTrip(req)
+
+		if !retryable(ctx, res, err) || attempt >= t.attempts {
+			return res, err
+		}
+
+		delay := backoff(t.delay, attempt)
+
+		// 等待后会超过请求的截止时间，直接返回本次结果
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return res, err
+		}
+
+		if res != nil {
+			_ = res.Body.Close()
+		}
+
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryable checks if a request failed transiently, canceled requests are
+// never retried.
+func retryable(ctx context.Context, res *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return true
+	}
+
+	return res.StatusCode >= http.StatusInternalServerError
+}
+
+// backoff returns the exponential delay before the next attempt with up to
+// 50% random jitter, so concurrent requests don't retry in lockstep.
+func backoff(base time.Duration, attempt int) time.Duration {
+	delay := base << (attempt - 1)
+
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay + time.Duration(rand.Int64N(int64(delay)/2+1))
+}
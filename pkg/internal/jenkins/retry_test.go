@@ -0,0 +1,107 @@
+package jenkins
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newFlakyServer(t *testing.T, failures int32) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) <= failures {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		_, _ = io.WriteString(w, `{"quietingDown": true}`)
+	}))
+
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestClientRetriesServerErrors(t *testing.T) {
+	srv, requests := newFlakyServer(t, 2)
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+		WithRetry(3, time.Millisecond),
+	)
+	assert.NoError(t, err)
+
+	status, err := client.Job.Status(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, status.QuietingDown)
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestClientRetryReturnsLastError(t *testing.T) {
+	srv, requests := newFlakyServer(t, 5)
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+		WithRetry(3, time.Millisecond),
+	)
+	assert.NoError(t, err)
+
+	_, err = client.Job.Status(context.Background())
+	assert.EqualError(t, err, http.StatusText(http.StatusBadGateway))
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestSDKRetriesServerErrors(t *testing.T) {
+	srv, requests := newFlakyServer(t, 2)
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+		WithRetry(3, time.Millisecond),
+	)
+	assert.NoError(t, err)
+
+	// SDK 初始化时的请求同样重试
+	assert.NoError(t, client.InitSDK(testLogger()))
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestClientRetryRespectsDeadline(t *testing.T) {
+	srv, requests := newFlakyServer(t, 5)
+
+	client, err := NewClient(
+		WithEndpoint(srv.URL),
+		WithTimeout(5*time.Second),
+		WithRetry(3, time.Minute),
+	)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err = client.Job.Status(ctx)
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 1; attempt <= 3; attempt++ {
+		base := 100 * time.Millisecond << (attempt - 1)
+		delay := backoff(100*time.Millisecond, attempt)
+
+		assert.GreaterOrEqual(t, delay, base)
+		assert.LessOrEqual(t, delay, base+base/2)
+	}
+}
@@ -0,0 +1,86 @@
+package jenkins
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// pollLogLayouts defines the date formats Jenkins uses for the start line of
+// the polling log, depending on the Java version of the controller.
+var pollLogLayouts = []string{
+	"Jan 2, 2006, 3:04:05 PM",
+	"Jan 2, 2006 3:04:05 PM",
+	"2006-01-02 15:04:05",
+}
+
+// PollLog defines the parsed result of the last SCM poll of a job.
+type PollLog struct {
+	StartedAt  time.Time // 轮询开始时间，无法解析时为零值
+	HasChanges bool      // 轮询是否发现变更
+	Finished   bool      // 日志中是否包含轮询结果
+}
+
+// PollLog returns the parsed log of the last SCM poll, it returns nil if the
+// job doesn't poll its SCM.
+func (c *JobClient) PollLog(ctx context.Context, job Job) (*PollLog, error) {
+	url := strings.TrimRight(job.URL, "/")
+	req, err := c.client.NewRequest(ctx, "GET", fmt.Sprintf("%s/scmPollLog/pollingLog", url), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	body := &bytes.Buffer{}
+	res, err := c.client.Do(req, body)
+
+	// 未配置 SCM 轮询的作业不存在轮询日志
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParsePollLog(body.String(), time.Local), nil
+}
+
+// ParsePollLog parses the plain text SCM polling log. The start time gets
+// interpreted within the given location as the log doesn't contain a zone,
+// the controller's zone is unknown so callers use the local one.
+func ParsePollLog(text string, loc *time.Location) *PollLog {
+	result := &PollLog{}
+	scanner := bufio.NewScanner(strings.NewReader(text))
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case strings.HasPrefix(line, "Started on "):
+			value := strings.TrimPrefix(line, "Started on ")
+
+			// 较新的 Java 版本在时间和 AM/PM 之间使用窄不换行空格
+			value = strings.ReplaceAll(value, "\u202f", " ")
+
+			for _, layout := range pollLogLayouts {
+				if started, err := time.ParseInLocation(layout, value, loc); err == nil {
+					result.StartedAt = started
+					break
+				}
+			}
+		case line == "Changes found":
+			result.HasChanges = true
+			result.Finished = true
+		case line == "No changes":
+			result.HasChanges = false
+			result.Finished = true
+		}
+	}
+
+	return result
+}
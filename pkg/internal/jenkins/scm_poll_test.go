@@ -0,0 +1,22 @@
+package jenkins
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePollLog(t *testing.T) {
+	poll := ParsePollLog("Started on May 1, 2024, 3:04:05 PM\nUsing strategy: Default\nDone. Took 12 ms\nNo changes\n", time.UTC)
+
+	assert.True(t, poll.Finished)
+	assert.False(t, poll.HasChanges)
+	assert.Equal(t, time.Date(2024, 5, 1, 15, 4, 5, 0, time.UTC), poll.StartedAt)
+
+	// 轮询仍在进行时没有结果
+	poll = ParsePollLog("Started on an unknown date\n", time.UTC)
+
+	assert.False(t, poll.Finished)
+	assert.True(t, poll.StartedAt.IsZero())
+}
@@ -0,0 +1,54 @@
+package jenkins
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+)
+
+const (
+	// ScrapeErrorTimeout is the category of requests exceeding the timeout.
+	ScrapeErrorTimeout = "timeout"
+
+	// ScrapeErrorAuth is the category of requests rejected with 401 or 403.
+	ScrapeErrorAuth = "auth"
+
+	// ScrapeErrorNonJSON is the category of responses which can't be parsed
+	// as JSON, e.g. HTML login pages of a proxy.
+	ScrapeErrorNonJSON = "non_json"
+
+	// ScrapeErrorConnection is the category of requests failing before any
+	// response has been received.
+	ScrapeErrorConnection = "connection"
+
+	// ScrapeErrorOther is the category of all remaining errors, e.g. server
+	// errors of Jenkins.
+	ScrapeErrorOther = "other"
+)
+
+// ScrapeErrorCategory buckets the error of a failed scrape into one of the
+// ScrapeError categories, so it can be used as a label with a low
+// cardinality.
+func ScrapeErrorCategory(err error) string {
+	var (
+		netErr    net.Error
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ScrapeErrorTimeout
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ScrapeErrorTimeout
+	case errors.Is(err, ErrUnauthorized), errors.Is(err, ErrForbidden):
+		return ScrapeErrorAuth
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return ScrapeErrorNonJSON
+	case errors.As(err, &netErr):
+		return ScrapeErrorConnection
+	default:
+		return ScrapeErrorOther
+	}
+}
@@ -0,0 +1,67 @@
+package jenkins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScrapeErrorCategory(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth":
+			w.WriteHeader(http.StatusUnauthorized)
+		case "/fail":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/html":
+			_, _ = io.WriteString(w, "<html><body>Login</body></html>")
+		default:
+			_, _ = io.WriteString(w, `{"jobs": []}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	client := newTestClient(t, srv)
+
+	for path, expected := range map[string]string{
+		"/auth": ScrapeErrorAuth,
+		"/fail": ScrapeErrorOther,
+		"/html": ScrapeErrorNonJSON,
+	} {
+		req, err := client.NewRequest(context.Background(), http.MethodGet, srv.URL+path, nil)
+		assert.NoError(t, err)
+
+		_, err = client.Do(req, &Hudson{})
+		assert.Equal(t, expected, ScrapeErrorCategory(err), path)
+	}
+
+	// 关闭的服务无法建立连接
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	req, err := client.NewRequest(context.Background(), http.MethodGet, closed.URL, nil)
+	assert.NoError(t, err)
+
+	_, err = client.Do(req, &Hudson{})
+	assert.Equal(t, ScrapeErrorConnection, ScrapeErrorCategory(err))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	req, err = client.NewRequest(ctx, http.MethodGet, srv.URL+"/api/json", nil)
+	assert.NoError(t, err)
+
+	_, err = client.Do(req, &Hudson{})
+	assert.Equal(t, ScrapeErrorTimeout, ScrapeErrorCategory(err))
+
+	assert.Equal(t, ScrapeErrorAuth, ScrapeErrorCategory(fmt.Errorf("wrapped: %w", ErrForbidden)))
+	assert.Equal(t, ScrapeErrorOther, ScrapeErrorCategory(errors.New("unknown")))
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"strings"
 	"time"
 
@@ -13,14 +14,15 @@ import (
 
 // SDKClient wraps gojenkins SDK for better integration.
 type SDKClient struct {
-	jenkins *gojenkins.Jenkins
-	logger  *slog.Logger
+	jenkins     *gojenkins.Jenkins
+	logger      *slog.Logger
+	folderDepth int // 最大的文件夹递归深度，0 表示不限制
 }
 
 // NewSDKClient creates a new SDK client.
-func NewSDKClient(endpoint, username, password string, timeout time.Duration, logger *slog.Logger) (*SDKClient, error) {
+func NewSDKClient(httpClient *http.Client, endpoint, username, password string, timeout time.Duration, logger *slog.Logger) (*SDKClient, error) {
 	// 创建 gojenkins 实例
-	jenkins := gojenkins.CreateJenkins(nil, endpoint, username, password)
+	jenkins := gojenkins.CreateJenkins(httpClient, endpoint, username, password)
 
 	// 初始化连接（需要 context）
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
@@ -41,12 +43,6 @@ func NewSDKClient(endpoint, username, password string, timeout time.Duration, lo
 	}, nil
 }
 
-// excludedFolders 是需要排除的文件夹列表（不采集这些文件夹下的 job）
-var excludedFolders = map[string]bool{
-	"prod-ebpay-new":  true,
-	"pre-ebpay-new":   true,
-	"prod-gray-ebpay":  true,
-}
 
 // JobWithPath wraps a gojenkins.Job with its full path.
 // This is needed because gojenkins.Job.GetName() may return relative names for nested jobs.
@@ -55,12 +51,30 @@ type JobWithPath struct {
 	FullPath string
 }
 
+// RecursionStats contains the folders skipped while walking the job tree.
+type RecursionStats struct {
+	FailedFolders   int // 获取失败的文件夹数量
+	DisabledFolders int // 跳过的已禁用文件夹数量
+	AmbiguousNames  int // 名称包含路径分隔符而跳过的 job 数量
+	ExcludedFolders int // 跳过的排除文件夹数量
+
+	TruncatedFolders []string // 超过最大深度而未递归的文件夹
+}
+
+// isDisabledFolder checks if the folder is disabled, folders don't expose a
+// buildable state, so the color reported for disabled items is used.
+func isDisabledFolder(job *gojenkins.Job) bool {
+	return job.Raw != nil && strings.HasPrefix(job.Raw.Color, "disabled")
+}
+
 // GetAllJobsRecursive recursively gets all jobs from specified folders, filtering out folder-type jobs.
 // Returns jobs and a map of job to full path (e.g., "folder/job").
 // The path map is needed because gojenkins.Job.GetName() may return relative names for nested jobs.
-func (c *SDKClient) GetAllJobsRecursive(ctx context.Context, folderNames []string, logger *slog.Logger) ([]*gojenkins.Job, map[*gojenkins.Job]string, error) {
+// Folders which fail or are disabled are skipped and counted within the returned stats.
+func (c *SDKClient) GetAllJobsRecursive(ctx context.Context, folderNames []string, excludedFolders map[string]bool, logger *slog.Logger) ([]*gojenkins.Job, map[*gojenkins.Job]string, RecursionStats, error) {
 	allJobs := make([]*gojenkins.Job, 0)
 	jobPathMap := make(map[*gojenkins.Job]string)
+	stats := RecursionStats{}
 
 	// 如果没有指定文件夹，获取根目录下的所有内容
 	if len(folderNames) == 0 {
@@ -69,7 +83,7 @@ func (c *SDKClient) GetAllJobsRecursive(ctx context.Context, folderNames []strin
 		// 所以我们需要手动递归处理每个 job
 		rootJobs, err := c.jenkins.GetAllJobs(ctx)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to get root jobs: %w", err)
+			return nil, nil, stats, fmt.Errorf("failed to get root jobs: %w", err)
 		}
 
 		logger.Debug("获取到根目录下的顶层 job",
@@ -80,20 +94,21 @@ func (c *SDKClient) GetAllJobsRecursive(ctx context.Context, folderNames []strin
 		for i, job := range rootJobs {
 			// 检查 context 是否已取消
 			if ctx.Err() != nil {
-				return allJobs, jobPathMap, ctx.Err()
+				return allJobs, jobPathMap, stats, ctx.Err()
 			}
 
 			jobName := job.GetName()
 			
 			// 检查是否是排除的文件夹
-			if excludedFolders[jobName] {
-				logger.Debug("跳过排除的文件夹",
+			if isExcludedFolder(jobName, excludedFolders) {
+				logger.Log(ctx, LevelTrace, "跳过排除的文件夹",
 					"folder_name", jobName,
 				)
+				stats.ExcludedFolders++
 				continue
 			}
 
-			logger.Debug("处理顶层 job",
+			logger.Log(ctx, LevelTrace, "处理顶层 job",
 				"序号", i+1,
 				"总数", len(rootJobs),
 				"job_name", jobName,
@@ -102,16 +117,17 @@ func (c *SDKClient) GetAllJobsRecursive(ctx context.Context, folderNames []strin
 			// 记录顶层 job 的路径
 			jobPathMap[job] = jobName
 			
-			jobs, paths, err := c.recursiveGetJobsWithPathMap(ctx, job, jobName, jobPathMap, logger)
+			jobs, paths, err := c.recursiveGetJobsWithPathMap(ctx, job, jobName, 1, jobPathMap, excludedFolders, &stats, logger)
 			if err != nil {
 				// 如果是 context canceled，直接返回
 				if errors.Is(err, context.Canceled) || ctx.Err() == context.Canceled {
-					return allJobs, jobPathMap, err
+					return allJobs, jobPathMap, stats, err
 				}
 				logger.Warn("递归获取 job 失败",
 					"job_name", jobName,
 					"error", err,
 				)
+				stats.FailedFolders++
 				continue
 			}
 			allJobs = append(allJobs, jobs...)
@@ -124,12 +140,15 @@ func (c *SDKClient) GetAllJobsRecursive(ctx context.Context, folderNames []strin
 		// 如果指定了文件夹，只处理这些文件夹
 		for _, folderName := range folderNames {
 			// 获取文件夹
-			folderJob, err := c.jenkins.GetJob(ctx, folderName)
+			// 嵌套的文件夹路径需要拆分为父级和名称
+			segments := strings.Split(folderName, "/")
+			folderJob, err := c.jenkins.GetJob(ctx, segments[len(segments)-1], segments[:len(segments)-1]...)
 			if err != nil {
 				logger.Warn("获取文件夹失败",
 					"folder_name", folderName,
 					"error", err,
 				)
+				stats.FailedFolders++
 				continue
 			}
 
@@ -137,12 +156,13 @@ func (c *SDKClient) GetAllJobsRecursive(ctx context.Context, folderNames []strin
 			jobPathMap[folderJob] = folderName
 			
 			// 递归获取文件夹下的所有 job
-			jobs, paths, err := c.recursiveGetJobsWithPathMap(ctx, folderJob, folderName, jobPathMap, logger)
+			jobs, paths, err := c.recursiveGetJobsWithPathMap(ctx, folderJob, folderName, 1, jobPathMap, excludedFolders, &stats, logger)
 			if err != nil {
 				logger.Warn("递归获取文件夹下的 job 失败",
 					"folder_name", folderName,
 					"error", err,
 				)
+				stats.FailedFolders++
 				continue
 			}
 			allJobs = append(allJobs, jobs...)
@@ -156,32 +176,40 @@ func (c *SDKClient) GetAllJobsRecursive(ctx context.Context, folderNames []strin
 	logger.Info("递归获取 job 列表完成",
 		"总数", len(allJobs),
 		"指定文件夹", folderNames,
+		"失败的文件夹", stats.FailedFolders,
+		"跳过的已禁用文件夹", stats.DisabledFolders,
+		"名称包含路径分隔符的 job", stats.AmbiguousNames,
 	)
 
-	return allJobs, jobPathMap, nil
+	if len(stats.TruncatedFolders) > 0 {
+		logger.Warn("文件夹超过最大深度，未继续递归",
+			"最大深度", c.folderDepth,
+			"文件夹", stats.TruncatedFolders,
+		)
+	}
+
+	return allJobs, jobPathMap, stats, nil
 }
 
 // recursiveGetJobsWithPathMap recursively gets all jobs and tracks their full paths.
 // This ensures we always use the full path (folder/job) instead of just job name.
-func (c *SDKClient) recursiveGetJobsWithPathMap(ctx context.Context, job *gojenkins.Job, fullPath string, jobPathMap map[*gojenkins.Job]string, logger *slog.Logger) ([]*gojenkins.Job, map[*gojenkins.Job]string, error) {
+// Failed and disabled nested folders are skipped and counted within stats,
+// folders nested deeper than the folder depth are collected within stats.
+func (c *SDKClient) recursiveGetJobsWithPathMap(ctx context.Context, job *gojenkins.Job, fullPath string, level int, jobPathMap map[*gojenkins.Job]string, excludedFolders map[string]bool, stats *RecursionStats, logger *slog.Logger) ([]*gojenkins.Job, map[*gojenkins.Job]string, error) {
 	allJobs := make([]*gojenkins.Job, 0)
 
 	jobName := fullPath // 使用传入的完整路径
 	// 记录当前 job 的完整路径
 	jobPathMap[job] = fullPath
 	
-	// 检查是否是排除的文件夹（检查完整路径中的任何部分）
-	// 例如：如果 jobName 是 "prod-gray-ebpay/some-job"，需要检查路径的第一部分
-	parts := strings.Split(jobName, "/")
-	if len(parts) > 0 {
-		topLevelFolder := parts[0]
-		if excludedFolders[topLevelFolder] {
-			logger.Debug("跳过排除的文件夹路径",
-				"job_name", jobName,
-				"顶层文件夹", topLevelFolder,
-			)
-			return allJobs, jobPathMap, nil // 返回空列表，不递归处理
-		}
+	// 检查是否是排除的文件夹（检查完整路径的第一部分）
+	// 例如：如果 jobName 是 "legacy/some-job"，需要检查顶层文件夹 "legacy"
+	if isExcludedFolder(jobName, excludedFolders) {
+		logger.Log(ctx, LevelTrace, "跳过排除的文件夹路径",
+			"job_name", jobName,
+		)
+		stats.ExcludedFolders++
+		return allJobs, jobPathMap, nil // 返回空列表，不递归处理
 	}
 
 	// 检查是否是文件夹类型
@@ -195,7 +223,7 @@ func (c *SDKClient) recursiveGetJobsWithPathMap(ctx context.Context, job *gojenk
 			   strings.Contains(jobClass, "folder") ||
 			   strings.Contains(jobClass, "com.cloudbees.hudson.plugins.folder") {
 				isFolder = true
-				logger.Debug("检测到文件夹类型（通过 Class）",
+				logger.Log(ctx, LevelTrace, "检测到文件夹类型（通过 Class）",
 					"job_name", fullPath,
 					"class", jobClass,
 				)
@@ -206,25 +234,49 @@ func (c *SDKClient) recursiveGetJobsWithPathMap(ctx context.Context, job *gojenk
 	// 方法2: 如果 Raw 为空或 Class 未设置，尝试通过 GetInnerJobs 来判断
 	// 注意：这个方法可能会产生额外的 API 调用，所以只在必要时使用
 	if !isFolder && job.Raw == nil {
-		logger.Debug("job.Raw 为空，无法通过 Class 判断是否为文件夹",
+		logger.Log(ctx, LevelTrace, "job.Raw 为空，无法通过 Class 判断是否为文件夹",
 			"job_name", fullPath,
 			"说明", "将尝试通过其他方式判断",
 		)
 	}
 
+	// 已禁用的文件夹不再递归，避免无用的请求
+	if isFolder && isDisabledFolder(job) {
+		logger.Log(ctx, LevelTrace, "跳过已禁用的文件夹",
+			"folder_name", fullPath,
+		)
+		stats.DisabledFolders++
+		return allJobs, jobPathMap, nil
+	}
+
+	// 超过最大深度的文件夹不再递归
+	if isFolder && exceedsDepth(level, c.folderDepth) {
+		stats.TruncatedFolders = append(stats.TruncatedFolders, fullPath)
+		return allJobs, jobPathMap, nil
+	}
+
 	if isFolder {
-		// 如果是文件夹，获取文件夹下的所有内容
-		// gojenkins 使用 GetInnerJobs(ctx) 获取文件夹下的子项
-		// 注意：即使 job.Raw.Jobs 是 nil，也应该尝试调用 GetInnerJobs
-		// 因为 SDK 可能会在调用时自动获取子项
-		subJobs, err := job.GetInnerJobs(ctx)
-		if err != nil {
-			// 如果获取失败，可能不是文件夹或没有权限
-			logger.Debug("获取文件夹下的子项失败",
-				"folder_name", job.GetName(),
-				"error", err,
-			)
-			return allJobs, jobPathMap, nil // 返回空列表，不中断
+		// 如果是文件夹，逐个获取文件夹下的子项
+		// 不使用 GetInnerJobs，名称包含 "/" 的子项会导致请求路径错误，使整个文件夹失败
+		subJobs := make([]*gojenkins.Job, 0, len(job.GetInnerJobsMetadata()))
+		for _, inner := range job.GetInnerJobsMetadata() {
+			if isAmbiguousName(inner.Name) {
+				logger.Warn("job 名称包含路径分隔符，无法构建 API 路径，跳过",
+					"folder_name", fullPath,
+					"job_name", inner.Name,
+					"url", inner.Url,
+				)
+				stats.AmbiguousNames++
+				continue
+			}
+
+			subJob, err := job.GetInnerJob(ctx, inner.Name)
+			if err != nil {
+				// 如果获取失败，可能没有权限或超时，由调用方跳过并计入失败的文件夹
+				return allJobs, jobPathMap, fmt.Errorf("failed to get inner jobs of folder %s: %w", fullPath, err)
+			}
+
+			subJobs = append(subJobs, subJob)
 		}
 
 		logger.Debug("文件夹下的子项",
@@ -241,36 +293,30 @@ func (c *SDKClient) recursiveGetJobsWithPathMap(ctx context.Context, job *gojenk
 			}
 
 			subJobName := subJob.GetName()
-			// 构建完整路径：如果子 job 的名称不包含父路径，则拼接
-			// gojenkins SDK 的 GetName() 可能返回相对名称，需要拼接父路径
-			var fullSubJobName string
-			if strings.Contains(subJobName, "/") {
-				// 如果已经包含路径分隔符，说明是完整路径
-				fullSubJobName = subJobName
-			} else {
-				// 如果不包含路径分隔符，需要拼接父路径
-				fullSubJobName = parentName + "/" + subJobName
-			}
+			// GetName() 返回相对名称，需要拼接父路径
+			// 包含路径分隔符的名称在获取子项时已经被跳过
+			fullSubJobName := parentName + "/" + subJobName
 
-			logger.Debug("处理子 job",
+			logger.Log(ctx, LevelTrace, "处理子 job",
 				"父路径", parentName,
 				"子 job 名称", subJobName,
 				"完整路径", fullSubJobName,
 			)
 
 			// 递归处理子 job，传递完整路径
-			jobs, paths, err := c.recursiveGetJobsWithPathMap(ctx, subJob, fullSubJobName, jobPathMap, logger)
+			jobs, paths, err := c.recursiveGetJobsWithPathMap(ctx, subJob, fullSubJobName, level+1, jobPathMap, excludedFolders, stats, logger)
 			if err != nil {
 				// 如果是 context canceled，直接返回
 				if errors.Is(err, context.Canceled) || ctx.Err() == context.Canceled {
 					return allJobs, jobPathMap, err
 				}
-				logger.Debug("递归获取子 job 失败",
+				logger.Warn("递归获取子 job 失败",
 					"parent", parentName,
 					"child", subJobName,
 					"full_path", fullSubJobName,
 					"error", err,
 				)
+				stats.FailedFolders++
 				continue
 			}
 			allJobs = append(allJobs, jobs...)
@@ -291,7 +337,7 @@ func (c *SDKClient) recursiveGetJobsWithPathMap(ctx context.Context, job *gojenk
 				   strings.Contains(jobClass, "folder") ||
 				   strings.Contains(jobClass, "com.cloudbees.hudson.plugins.folder") {
 					isActuallyFolder = true
-					logger.Debug("检测到文件夹类型（在非文件夹分支），跳过",
+					logger.Log(ctx, LevelTrace, "检测到文件夹类型（在非文件夹分支），跳过",
 						"job_name", fullPath,
 						"class", jobClass,
 					)
@@ -307,14 +353,14 @@ func (c *SDKClient) recursiveGetJobsWithPathMap(ctx context.Context, job *gojenk
 			if err == nil && len(subJobs) > 0 {
 				// 能获取到子项，说明是文件夹
 				isActuallyFolder = true
-				logger.Debug("通过 GetInnerJobs 检测到文件夹类型，跳过",
+				logger.Log(ctx, LevelTrace, "通过 GetInnerJobs 检测到文件夹类型，跳过",
 					"job_name", fullPath,
 					"子项数量", len(subJobs),
 				)
 			} else if err == nil && len(subJobs) == 0 {
 				// 能调用 GetInnerJobs 但返回空，可能是空文件夹
 				// 但空文件夹也可能被当作文件夹处理
-				logger.Debug("检测到空文件夹，跳过",
+				logger.Log(ctx, LevelTrace, "检测到空文件夹，跳过",
 					"job_name", fullPath,
 				)
 				isActuallyFolder = true
@@ -325,12 +371,12 @@ func (c *SDKClient) recursiveGetJobsWithPathMap(ctx context.Context, job *gojenk
 		if !isActuallyFolder {
 			allJobs = append(allJobs, job)
 			jobPathMap[job] = fullPath
-			logger.Debug("添加构建 job",
+			logger.Log(ctx, LevelTrace, "添加构建 job",
 				"job_name", fullPath,
 			)
 		} else {
 			// 如果是文件夹但没有被正确识别，记录警告但不添加
-			logger.Debug("跳过文件夹类型的 job（在非文件夹分支中被识别）",
+			logger.Log(ctx, LevelTrace, "跳过文件夹类型的 job（在非文件夹分支中被识别）",
 				"job_name", fullPath,
 			)
 		}
@@ -344,7 +390,8 @@ func (c *SDKClient) recursiveGetJobsWithPathMap(ctx context.Context, job *gojenk
 func (c *SDKClient) recursiveGetJobs(ctx context.Context, job *gojenkins.Job, logger *slog.Logger) ([]*gojenkins.Job, error) {
 	jobName := job.GetName()
 	jobPathMap := make(map[*gojenkins.Job]string)
-	jobs, _, err := c.recursiveGetJobsWithPathMap(ctx, job, jobName, jobPathMap, logger)
+	stats := RecursionStats{}
+	jobs, _, err := c.recursiveGetJobsWithPathMap(ctx, job, jobName, 1, jobPathMap, nil, &stats, logger)
 	return jobs, err
 }
 
@@ -456,7 +503,7 @@ func (c *SDKClient) GetAllJobs(ctx context.Context, folderNames []string) ([]*go
 func (c *SDKClient) GetJobByFullName(ctx context.Context, fullName string) (*gojenkins.Job, error) {
 	// 数据库中的路径已经是 SDK 格式（在 Discovery 阶段已转换）
 	// 直接使用，不需要再次转换，提升性能
-	c.logger.Debug("使用 SDK 格式路径获取 job",
+	c.logger.Log(ctx, LevelTrace, "使用 SDK 格式路径获取 job",
 		"SDK 路径", fullName,
 		"说明", "数据库中的路径已经是 SDK 格式（folder/job/job），直接使用",
 	)
@@ -502,13 +549,13 @@ func (c *SDKClient) GetJobByFullName(ctx context.Context, fullName string) (*goj
 	if job.Raw != nil {
 		jobClass := job.Raw.Class
 		if jobClass != "" {
-			c.logger.Debug("job 类型",
+			c.logger.Log(ctx, LevelTrace, "job 类型",
 				"job_name", fullName,
 				"class", jobClass,
 			)
 			// 如果是文件夹类型，可能需要特殊处理
 			if strings.Contains(jobClass, "Folder") {
-				c.logger.Debug("检测到文件夹类型，跳过",
+				c.logger.Log(ctx, LevelTrace, "检测到文件夹类型，跳过",
 					"job_name", fullName,
 				)
 				return nil, fmt.Errorf("job %s 是文件夹类型，不是实际的构建 job", fullName)
@@ -519,6 +566,23 @@ func (c *SDKClient) GetJobByFullName(ctx context.Context, fullName string) (*goj
 	return job, nil
 }
 
+// GetJobConfig gets the raw config.xml of a job by its full name.
+func (c *SDKClient) GetJobConfig(ctx context.Context, fullName string) (string, error) {
+	var data string
+
+	// 直接请求 config.xml，避免额外获取 job 信息
+	resp, err := c.jenkins.Requester.GetXML(ctx, "/job/"+fullName+"/config.xml", &data, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get config of job %s: %w", fullName, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get config of job %s: status %d", fullName, resp.StatusCode)
+	}
+
+	return data, nil
+}
+
 // GetLastCompletedBuild gets the last completed build for a job.
 func (c *SDKClient) GetLastCompletedBuild(ctx context.Context, fullName string) (*gojenkins.Build, int64, error) {
 	// 检查 context 是否已取消
@@ -540,6 +604,16 @@ func (c *SDKClient) GetLastCompletedBuild(ctx context.Context, fullName string)
 		return nil, 0, ctx.Err()
 	}
 
+	// job 存在但从未构建过，与请求失败（例如 404）区分开
+	if job.Raw != nil && job.Raw.LastBuild.Number == 0 && job.Raw.LastCompletedBuild.Number == 0 {
+		return nil, 0, ErrNeverBuilt
+	}
+
+	// 已经有构建但尚未完成（例如首次构建正在进行中）
+	if job.Raw != nil && job.Raw.LastCompletedBuild.Number == 0 {
+		return nil, 0, nil
+	}
+
 	// 获取最后一次完成的构建
 	build, err := job.GetLastCompletedBuild(ctx)
 	if err != nil {
@@ -547,10 +621,6 @@ func (c *SDKClient) GetLastCompletedBuild(ctx context.Context, fullName string)
 		if errors.Is(err, context.Canceled) || ctx.Err() == context.Canceled || strings.Contains(err.Error(), "context canceled") {
 			return nil, 0, context.Canceled
 		}
-		// 如果没有完成的构建，返回 nil
-		if strings.Contains(err.Error(), "404") || strings.Contains(err.Error(), "not found") {
-			return nil, 0, nil
-		}
 		return nil, 0, fmt.Errorf("failed to get last completed build for job %s: %w", fullName, err)
 	}
 
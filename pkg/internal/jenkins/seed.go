@@ -0,0 +1,32 @@
+package jenkins
+
+// seedFromRepo sets the build metrics to the last known status stored within
+// the database, so the first scrape after a restart doesn't return empty
+// results. Commit, branch and the raw result are not persisted and stay empty
+// until the first collection replaces the seeded values.
+func (c *BuildCollector) seedFromRepo() (int, error) {
+	jobs, err := c.repo.ListEnabledJobs()
+	if err != nil {
+		return 0, err
+	}
+
+	seeded := 0
+
+	c.updateMetrics(func() {
+		for _, job := range jobs {
+			if job.LastStatus == "" {
+				continue
+			}
+
+			if c.isExcluded(job.JobName) {
+				continue
+			}
+
+			c.setBuildResult(job.JobName, "", "", job.LastStatus, "", nil)
+			c.buildStatusGauge.WithLabelValues(job.JobName).Set(BuildStatusValue(job.LastStatus))
+			seeded++
+		}
+	})
+
+	return seeded, nil
+}
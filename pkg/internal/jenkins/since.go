@@ -0,0 +1,77 @@
+package jenkins
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// sinceCollector exports the build results only for jobs whose last seen
+// build advanced since the given time.
+type sinceCollector struct {
+	collector *BuildCollector
+	since     time.Time
+}
+
+// Since returns a collector exporting only the build results of jobs which
+// have been built since the given time, based on the tracked build changes.
+func (c *BuildCollector) Since(since time.Time) prometheus.Collector {
+	return &sinceCollector{
+		collector: c,
+		since:     since,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (s *sinceCollector) Describe(ch chan<- *prometheus.Desc) {
+	s.collector.buildResultGauge.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (s *sinceCollector) Collect(ch chan<- prometheus.Metric) {
+	names, err := s.collector.repo.ListJobNamesChangedSince(s.since)
+	if err != nil {
+		s.collector.logger.Error("查询最近变化的 job 失败",
+			"since", s.since,
+			"错误", err,
+		)
+		return
+	}
+
+	changed := make(map[string]bool, len(names))
+	for _, name := range names {
+		changed[name] = true
+	}
+
+	metrics := make(chan prometheus.Metric)
+	go func() {
+		s.collector.mu.RLock()
+		defer s.collector.mu.RUnlock()
+
+		s.collector.buildResultGauge.Collect(metrics)
+		close(metrics)
+	}()
+
+	for metric := range metrics {
+		if changed[jobNameOf(metric)] {
+			ch <- metric
+		}
+	}
+}
+
+// jobNameOf extracts the job_name label value from a metric.
+func jobNameOf(metric prometheus.Metric) string {
+	m := &dto.Metric{}
+	if err := metric.Write(m); err != nil {
+		return ""
+	}
+
+	for _, label := range m.GetLabel() {
+		if label.GetName() == "job_name" {
+			return label.GetValue()
+		}
+	}
+
+	return ""
+}
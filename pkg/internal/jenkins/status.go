@@ -0,0 +1,80 @@
+package jenkins
+
+// BuildStatusHelp defines the help text of the numeric build status metric,
+// it's shared by both collectors to keep the encoding aligned.
+const BuildStatusHelp = "Numeric status of the last build: 0 success, 1 failure, 2 aborted, 3 unstable, 4 in_progress, 5 waiting, 6 not_built, 7 unknown"
+
+// BuildLastResultHelp defines the help text of the last build result metric,
+// it's shared by all collectors exporting it.
+const BuildLastResultHelp = "Last build result: 1 indicates current status, status label contains the actual status (success, failure, aborted, unstable, in_progress, waiting, not_built, unknown)"
+
+// BuildLastResultLabels returns the label names of the last build result
+// metric, all collectors exporting it have to use the same label set. With
+// rawResult the result label carries the unmodified result from Jenkins, the
+// params are appended as additional labels for build parameters.
+func BuildLastResultLabels(rawResult bool, params []string) []string {
+	labels := []string{"job_name", "check_commitID", "gitBranch", "status"}
+
+	if rawResult {
+		labels = append(labels, "result")
+	}
+
+	return append(labels, params...)
+}
+
+// buildStatuses defines the status labels ordered by their numeric value.
+var buildStatuses = []string{
+	"success",
+	"failure",
+	"aborted",
+	"unstable",
+	"in_progress",
+	"waiting",
+	"not_built",
+	"unknown",
+}
+
+// BuildStatusValue converts a status label to its numeric value, labels
+// which are not known get mapped to unknown.
+func BuildStatusValue(status string) float64 {
+	for idx, label := range buildStatuses {
+		if label == status {
+			return float64(idx)
+		}
+	}
+
+	return 7.0
+}
+
+// IsBuildStatus checks if the label is one of the known status labels.
+func IsBuildStatus(status string) bool {
+	for _, label := range buildStatuses {
+		if label == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// EmptyResultStatus classifies a finished build without result. Builds with a
+// recorded duration have been started, e.g. pipelines aborted before their
+// first stage, they get the configured status. All others stay not_built.
+func EmptyResultStatus(duration int64, configured string) string {
+	if duration > 0 && configured != "" {
+		return configured
+	}
+
+	return "not_built"
+}
+
+// BuildStatusLabel converts a numeric status value to its label.
+func BuildStatusLabel(value float64) string {
+	idx := int(value)
+
+	if idx < 0 || idx >= len(buildStatuses) || float64(idx) != value {
+		return "unknown"
+	}
+
+	return buildStatuses[idx]
+}
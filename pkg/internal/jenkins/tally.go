@@ -0,0 +1,66 @@
+package jenkins
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// WithStatusMaxAge configures the aggregated status tallies to skip jobs whose
+// last build started longer ago than the given age, so they only reflect the
+// active jobs. Disabled if 0.
+func WithStatusMaxAge(age time.Duration) BuildCollectorOption {
+	return func(c *BuildCollector) {
+		c.statusMaxAge = age
+	}
+}
+
+// newStatusTally creates the gauge counting the jobs by their last status.
+func newStatusTally() *prometheus.GaugeVec {
+	return prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "jenkins_jobs_by_status",
+			Help: "Number of jobs by the status of their last completed build",
+		},
+		[]string{"status"},
+	)
+}
+
+// updateStatusTally counts the enabled jobs by the last status stored within
+// the database, jobs without status or with a build older than the maximum
+// age are not counted.
+func (c *BuildCollector) updateStatusTally() error {
+	jobs, err := c.repo.ListEnabledJobs()
+	if err != nil {
+		return err
+	}
+
+	now := c.clock.Now()
+	tally := make(map[string]int)
+
+	for _, job := range jobs {
+		if job.LastStatus == "" {
+			continue
+		}
+
+		if c.isExcluded(job.JobName) {
+			continue
+		}
+
+		if c.statusMaxAge > 0 && (job.LastBuildStartedAt == nil || now.Sub(*job.LastBuildStartedAt) > c.statusMaxAge) {
+			continue
+		}
+
+		tally[job.LastStatus]++
+	}
+
+	c.updateMetrics(func() {
+		c.statusTally.Reset()
+
+		for status, count := range tally {
+			c.statusTally.WithLabelValues(status).Set(float64(count))
+		}
+	})
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package jenkins
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// TestReport returns the test report of the last completed build of a job,
+// the report is nil if the job doesn't publish test results.
+func (c *JobClient) TestReport(ctx context.Context, job Job) (*TestReport, error) {
+	url := strings.TrimRight(job.URL, "/")
+	req, err := c.client.NewRequest(ctx, "GET", fmt.Sprintf("%s/lastCompletedBuild/testReport/api/json?tree=totalCount,passCount,failCount,skipCount", url), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TestReport{}
+	res, err := c.client.Do(req, result)
+
+	// 未配置测试报告的作业不存在 testReport
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Total returns the number of executed tests.
+func (r TestReport) Total() int {
+	if r.TotalCount > 0 {
+		return r.TotalCount
+	}
+
+	return r.PassCount + r.FailCount + r.SkipCount
+}
@@ -3,12 +3,21 @@ package jenkins
 
 // Build defines the response from specific builds.
 type Build struct {
-	Timestamp int64    `json:"timestamp"`
-	Duration  int64    `json:"duration"`
-	Result    string   `json:"result"`   // SUCCESS, FAILURE, ABORTED, UNSTABLE, null
-	Building  bool     `json:"building"` // 是否正在构建
-	QueueID   int64    `json:"queueId"`  // 队列ID（如果在队列中）
-	Actions   []Action `json:"actions"`  // 包含参数信息
+	Timestamp         int64      `json:"timestamp"`
+	Duration          int64      `json:"duration"`
+	EstimatedDuration int64      `json:"estimatedDuration"` // Jenkins 根据历史构建估算的耗时
+	Result            string     `json:"result"`            // SUCCESS, FAILURE, ABORTED, UNSTABLE, null
+	Building          bool       `json:"building"`          // 是否正在构建
+	QueueID           int64      `json:"queueId"`           // 队列ID（如果在队列中）
+	Actions           []Action   `json:"actions"`           // 包含参数信息
+	Artifacts         []Artifact `json:"artifacts"`         // 构建归档的制品
+	BuiltOn           string     `json:"builtOn"`           // 运行构建的节点，内置节点为空
+}
+
+// Artifact defines an archived artifact of a build.
+type Artifact struct {
+	FileName     string `json:"fileName"`
+	RelativePath string `json:"relativePath"`
 }
 
 // Action defines an action in the build.
@@ -16,6 +25,9 @@ type Action struct {
 	Class      string      `json:"_class"`
 	Parameters []Parameter `json:"parameters,omitempty"`
 	Causes     []Cause     `json:"causes,omitempty"`
+
+	// 由 metrics 插件的 TimeInQueueAction 提供，构建开始前在队列中等待的毫秒数
+	QueuingDurationMillis int64 `json:"queuingDurationMillis,omitempty"`
 }
 
 // Parameter defines a build parameter.
@@ -28,6 +40,8 @@ type Parameter struct {
 type Cause struct {
 	Class            string `json:"_class"`
 	ShortDescription string `json:"shortDescription"`
+	UpstreamProject  string `json:"upstreamProject,omitempty"` // 仅 UpstreamCause 包含触发构建的上游 job
+	UpstreamBuild    int    `json:"upstreamBuild,omitempty"`
 }
 
 // Folder is a simple type used for folder listings.
@@ -42,9 +56,94 @@ type Folder struct {
 type Hudson struct {
 	Mode         string   `json:"mode"`
 	NumExecutors int      `json:"numExecutors"`
+	QuietingDown bool     `json:"quietingDown"`
 	Folders      []Folder `json:"jobs"`
 }
 
+// Computers defines the response from the computer API.
+type Computers struct {
+	Computers []Computer `json:"computer"`
+}
+
+// Computer defines a single agent of the computer API.
+type Computer struct {
+	Class              string      `json:"_class"`
+	Name               string      `json:"displayName"`
+	Offline            bool        `json:"offline"`
+	OfflineCauseReason string      `json:"offlineCauseReason"`
+	Idle               bool        `json:"idle"`
+	NumExecutors       int         `json:"numExecutors"`
+	Executors          []Executor  `json:"executors"`
+	AssignedLabels     []Label     `json:"assignedLabels"`
+	MonitorData        MonitorData `json:"monitorData"`
+}
+
+// MonitorData defines the results of the node monitors of an agent, monitors
+// without data are nil, e.g. while the agent is offline.
+type MonitorData struct {
+	ResponseTime   *ResponseTimeData `json:"hudson.node_monitors.ResponseTimeMonitor"`
+	DiskSpace      *DiskSpaceData    `json:"hudson.node_monitors.DiskSpaceMonitor"`
+	TemporarySpace *DiskSpaceData    `json:"hudson.node_monitors.TemporarySpaceMonitor"`
+}
+
+// DiskSpaceData defines the result of the disk and temporary space monitors.
+type DiskSpaceData struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"` // 剩余空间，单位为字节
+}
+
+// ResponseTimeData defines the result of the response time monitor.
+type ResponseTimeData struct {
+	Average int64 `json:"average"` // 最近几次检测的平均响应时间，单位为毫秒
+}
+
+// Executor defines a single executor of an agent.
+type Executor struct {
+	Idle bool `json:"idle"`
+}
+
+// Label defines a label assigned to an agent.
+type Label struct {
+	Name string `json:"name"`
+}
+
+// Queue defines the response from the queue API.
+type Queue struct {
+	Items []QueueItem `json:"items"`
+}
+
+// QueueItem defines a single item waiting in the build queue.
+type QueueItem struct {
+	ID           int64     `json:"id"`
+	Why          string    `json:"why"`
+	Blocked      bool      `json:"blocked"`
+	Stuck        bool      `json:"stuck"`        // 等待时间过长，Jenkins 认为无法被调度
+	InQueueSince int64     `json:"inQueueSince"` // 进入队列的时间，毫秒时间戳
+	Task         QueueTask `json:"task"`
+}
+
+// QueueTask defines the task of a queue item.
+type QueueTask struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// OverallLoad defines the response from the overall load API.
+type OverallLoad struct {
+	BusyExecutors LoadStatistic `json:"busyExecutors"`
+	QueueLength   LoadStatistic `json:"queueLength"`
+}
+
+// LoadStatistic defines the time series of a load statistic per time scale.
+type LoadStatistic struct {
+	Sec10 LoadTimeSeries `json:"sec10"`
+}
+
+// LoadTimeSeries defines the moving average of a load statistic.
+type LoadTimeSeries struct {
+	Latest float64 `json:"latest"`
+}
+
 // BuildNumber defines a type for build numbers.
 type BuildNumber struct {
 	Number int    `json:"number"`
@@ -56,6 +155,7 @@ type Job struct {
 	Class                 string       `json:"_class"`
 	Name                  string       `json:"displayName"`
 	Path                  string       `json:"fullName"`
+	Description           string       `json:"description"`
 	URL                   string       `json:"url"`
 	Disabled              bool         `json:"disabled"`
 	Buildable             bool         `json:"buildable"`
@@ -69,3 +169,13 @@ type Job struct {
 	LastUnsuccessfulBuild *BuildNumber `json:"lastUnsuccessfulBuild"`
 	NextBuildNumber       int          `json:"nextBuildNumber"`
 }
+
+// TestReport defines the response from the test report of a build. Aggregated
+// reports of matrix or maven jobs only contain the total count, all others
+// only contain the passed count.
+type TestReport struct {
+	TotalCount int `json:"totalCount"`
+	PassCount  int `json:"passCount"`
+	FailCount  int `json:"failCount"`
+	SkipCount  int `json:"skipCount"`
+}
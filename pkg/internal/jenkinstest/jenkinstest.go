@@ -0,0 +1,73 @@
+// Package jenkinstest provides a fake Jenkins and other helpers shared by the
+// tests of the collectors.
+package jenkinstest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// NewServer starts a fake Jenkins serving the given JSON bodies by path.
+// The placeholder $URL in a body gets replaced with the server URL, a body
+// like "HTTP 403" gets answered with the given status code. Unknown paths get
+// answered with the HTML not found page of Jenkins.
+func NewServer(t testing.TB, routes map[string]string) *httptest.Server {
+	t.Helper()
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := routes[r.URL.Path]
+
+		if !ok {
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, "<html><body>Not Found</body></html>")
+			return
+		}
+
+		if code, ok := strings.CutPrefix(body, "HTTP "); ok {
+			status, _ := strconv.Atoi(code)
+			w.WriteHeader(status)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, strings.ReplaceAll(body, "$URL", srv.URL))
+	}))
+
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// Clock is a manually advanced clock for time based tests.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock creates a clock starting at the given time.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns the current time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Advance moves the clock forward by the given duration.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"fmt"
+)
+
+// Counter represents a persisted counter value, the label contains the
+// job_name of the counter or is empty for counters without labels.
+type Counter struct {
+	Name  string
+	Label string
+	Value float64
+}
+
+// SaveCounters replaces the persisted counter values.
+func (r *JobRepo) SaveCounters(counters []Counter) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`DELETE FROM counters`); err != nil {
+		return fmt.Errorf("failed to clear counters: %w", err)
+	}
+
+	for _, counter := range counters {
+		if _, err := tx.Exec(
+			`INSERT INTO counters (name, label, value) VALUES (?, ?, ?)`,
+			counter.Name,
+			counter.Label,
+			counter.Value,
+		); err != nil {
+			return fmt.Errorf("failed to insert counter %s: %w", counter.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ListCounters returns all persisted counter values.
+func (r *JobRepo) ListCounters() ([]Counter, error) {
+	rows, err := r.db.Query(`SELECT name, label, value FROM counters ORDER BY name, label`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query counters: %w", err)
+	}
+	defer rows.Close()
+
+	var counters []Counter
+	for rows.Next() {
+		var counter Counter
+
+		if err := rows.Scan(&counter.Name, &counter.Label, &counter.Value); err != nil {
+			return nil, fmt.Errorf("failed to scan counter: %w", err)
+		}
+
+		counters = append(counters, counter)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating counters: %w", err)
+	}
+
+	return counters, nil
+}
@@ -14,6 +14,11 @@ type Job struct {
 	LastSeenBuild int64
 	LastSyncTime  *time.Time
 	CreatedAt     time.Time
+
+	// LastStatus and LastBuildStartedAt describe the last completed build,
+	// they are empty until the build collector processed the job.
+	LastStatus         string
+	LastBuildStartedAt *time.Time
 }
 
 // JobRepo provides methods for job data access.
@@ -30,24 +35,58 @@ func NewJobRepo(db *sql.DB, logger *slog.Logger) *JobRepo {
 	}
 }
 
-// ListEnabledJobs returns all enabled jobs from the database.
-func (r *JobRepo) ListEnabledJobs() ([]Job, error) {
-	query := `
-		SELECT job_name, enabled, last_seen_build, last_sync_time, created_at
+// enabledJobsQuery selects all enabled jobs ordered by name.
+const enabledJobsQuery = `
+		SELECT job_name, enabled, last_seen_build, last_sync_time, created_at, last_status, last_build_started_at
 		FROM jobs
 		WHERE enabled = 1
 		ORDER BY job_name`
 
-	rows, err := r.db.Query(query)
+// allJobsQuery selects all jobs including the disabled ones ordered by name.
+const allJobsQuery = `
+		SELECT job_name, enabled, last_seen_build, last_sync_time, created_at, last_status, last_build_started_at
+		FROM jobs
+		ORDER BY job_name`
+
+// ListEnabledJobs returns all enabled jobs from the database. The jobs get
+// read within a deferred transaction, so the list reflects a single state of
+// the database even while Discovery syncs the jobs concurrently.
+func (r *JobRepo) ListEnabledJobs() ([]Job, error) {
+	// BEGIN 默认为 DEFERRED，只在第一次读取时获取读快照，不阻塞写入
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	// 只读事务，回滚即可结束
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.Query(enabledJobsQuery)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query enabled jobs: %w", err)
 	}
 	defer rows.Close()
 
+	return scanJobs(rows)
+}
+
+// ListAllJobs returns all jobs from the database, including the jobs disabled
+// by Discovery.
+func (r *JobRepo) ListAllJobs() ([]Job, error) {
+	rows, err := r.db.Query(allJobsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query jobs: %w", err)
+	}
+	defer rows.Close()
+
+	return scanJobs(rows)
+}
+
+// scanJobs reads the jobs selected by enabledJobsQuery or allJobsQuery.
+func scanJobs(rows *sql.Rows) ([]Job, error) {
 	var jobs []Job
 	for rows.Next() {
 		var job Job
-		var lastSyncTime, createdAt sql.NullInt64
+		var lastSyncTime, createdAt, lastBuildStartedAt sql.NullInt64
 
 		if err := rows.Scan(
 			&job.JobName,
@@ -55,6 +94,8 @@ func (r *JobRepo) ListEnabledJobs() ([]Job, error) {
 			&job.LastSeenBuild,
 			&lastSyncTime,
 			&createdAt,
+			&job.LastStatus,
+			&lastBuildStartedAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan job: %w", err)
 		}
@@ -68,6 +109,11 @@ func (r *JobRepo) ListEnabledJobs() ([]Job, error) {
 			job.CreatedAt = time.Unix(createdAt.Int64, 0)
 		}
 
+		if lastBuildStartedAt.Valid {
+			t := time.Unix(lastBuildStartedAt.Int64, 0)
+			job.LastBuildStartedAt = &t
+		}
+
 		jobs = append(jobs, job)
 	}
 
@@ -78,35 +124,157 @@ func (r *JobRepo) ListEnabledJobs() ([]Job, error) {
 	return jobs, nil
 }
 
-// UpdateLastSeen updates the last_seen_build for a job.
-func (r *JobRepo) UpdateLastSeen(jobName string, buildNumber int64) error {
-	query := `
-		UPDATE jobs
-		SET last_seen_build = ?
-		WHERE job_name = ?`
+// BuildUpdate defines the changes of the last completed build of a job, which
+// get written by UpdateLastSeenBatch.
+type BuildUpdate struct {
+	LastSeenBuild int64  // 新的构建编号，0 表示构建编号没有变化
+	Status        string // 最后一次完成构建的状态
+	Timestamp     int64  // 最后一次完成构建的开始时间，unix 时间戳，0 表示未知
+}
+
+// UpdateLastSeenBatch stores the last_seen_build together with the status and
+// the start time of the last completed build for multiple jobs within a
+// single transaction, the map is keyed by the job name.
+func (r *JobRepo) UpdateLastSeenBatch(updates map[string]BuildUpdate) error {
+	if len(updates) == 0 {
+		return nil
+	}
 
-	result, err := r.db.Exec(query, buildNumber, jobName)
+	tx, err := r.db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to update last_seen_build: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer func() { _ = tx.Rollback() }()
 
-	rowsAffected, err := result.RowsAffected()
+	lastSeen, err := tx.Prepare(`
+		UPDATE jobs
+		SET last_seen_build = ?, last_build_time = ?
+		WHERE job_name = ?`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer lastSeen.Close()
+
+	buildInfo, err := tx.Prepare(`
+		UPDATE jobs
+		SET last_status = ?, last_build_started_at = ?
+		WHERE job_name = ?`)
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return fmt.Errorf("failed to prepare statement: %w", err)
 	}
+	defer buildInfo.Close()
+
+	now := time.Now().Unix()
+
+	for jobName, update := range updates {
+		var timestamp sql.NullInt64
+		if update.Timestamp > 0 {
+			timestamp = sql.NullInt64{Int64: update.Timestamp, Valid: true}
+		}
+
+		result, err := buildInfo.Exec(update.Status, timestamp, jobName)
+		if err != nil {
+			return fmt.Errorf("failed to update build info for %s: %w", jobName, err)
+		}
 
-	if rowsAffected == 0 {
-		r.logger.Warn("更新 last_seen_build 时未找到对应的 job",
-			"job_name", jobName,
-		)
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			r.logger.Warn("更新构建信息时未找到对应的 job",
+				"job_name", jobName,
+			)
+
+			continue
+		}
+
+		if update.LastSeenBuild <= 0 {
+			continue
+		}
+
+		if _, err := lastSeen.Exec(update.LastSeenBuild, now, jobName); err != nil {
+			return fmt.Errorf("failed to update last_seen_build for %s: %w", jobName, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
 }
 
+// ListJobNamesChangedSince returns the enabled jobs whose last seen build
+// advanced at or after the given time.
+func (r *JobRepo) ListJobNamesChangedSince(since time.Time) ([]string, error) {
+	query := `
+		SELECT job_name
+		FROM jobs
+		WHERE enabled = 1 AND last_build_time >= ?
+		ORDER BY job_name`
+
+	rows, err := r.db.Query(query, since.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query changed jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan job name: %w", err)
+		}
+		names = append(names, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating changed jobs: %w", err)
+	}
+
+	return names, nil
+}
+
+// UpdateConfigHash stores the config hash for a job and reports if it differs
+// from a previously stored hash. The first stored hash is not a change.
+func (r *JobRepo) UpdateConfigHash(jobName, hash string) (bool, error) {
+	var stored string
+
+	if err := r.db.QueryRow(`SELECT config_hash FROM jobs WHERE job_name = ?`, jobName).Scan(&stored); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to query config_hash: %w", err)
+	}
+
+	if stored == hash {
+		return false, nil
+	}
+
+	if _, err := r.db.Exec(`UPDATE jobs SET config_hash = ? WHERE job_name = ?`, hash, jobName); err != nil {
+		return false, fmt.Errorf("failed to update config_hash: %w", err)
+	}
+
+	return stored != "", nil
+}
+
 // SyncJobs synchronizes the job list with Jenkins.
 // It adds new jobs, soft-deletes removed jobs, and updates last_sync_time for existing jobs.
 func (r *JobRepo) SyncJobs(jobNames []string) error {
+	return r.syncJobs(jobNames, true)
+}
+
+// SyncJobsPartial synchronizes an incomplete job list with Jenkins.
+// It behaves like SyncJobs but never soft-deletes jobs, since missing jobs
+// may belong to folders which failed during discovery.
+func (r *JobRepo) SyncJobsPartial(jobNames []string) error {
+	return r.syncJobs(jobNames, false)
+}
+
+func (r *JobRepo) syncJobs(jobNames []string, softDelete bool) error {
 	tx, err := r.db.Begin()
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -167,7 +335,7 @@ func (r *JobRepo) SyncJobs(jobNames []string) error {
 
 	// 处理软删除的 job（在数据库中但不在 Jenkins 中）
 	for _, existingJob := range existingJobs {
-		if !jobNameSet[existingJob.JobName] {
+		if softDelete && !jobNameSet[existingJob.JobName] {
 			deleteQuery := `
 				UPDATE jobs
 				SET enabled = 0
@@ -205,6 +373,45 @@ func (r *JobRepo) SyncJobs(jobNames []string) error {
 	return nil
 }
 
+// PruneDisabledJobs hard-deletes soft-deleted jobs which have not been seen
+// by Discovery for longer than the given duration. It returns the number of
+// deleted jobs.
+func (r *JobRepo) PruneDisabledJobs(olderThan time.Duration) (int64, error) {
+	query := `
+		DELETE FROM jobs
+		WHERE enabled = 0 AND COALESCE(last_sync_time, created_at) < ?`
+
+	result, err := r.db.Exec(query, time.Now().Add(-olderThan).Unix())
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune disabled jobs: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// PruneJobChanges deletes the audit log entries older than the given
+// duration. It returns the number of deleted entries.
+func (r *JobRepo) PruneJobChanges(olderThan time.Duration) (int64, error) {
+	query := `DELETE FROM job_changes WHERE event_time < ?`
+
+	result, err := r.db.Exec(query, time.Now().Add(-olderThan).Unix())
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune job changes: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return deleted, nil
+}
+
 // listEnabledJobsInTx lists enabled jobs within a transaction.
 func (r *JobRepo) listEnabledJobsInTx(tx *sql.Tx) ([]Job, error) {
 	query := `SELECT job_name FROM jobs WHERE enabled = 1`
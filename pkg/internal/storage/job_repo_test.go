@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"database/sql"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRepo(tb testing.TB, names ...string) (*JobRepo, *sql.DB) {
+	tb.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	db, err := NewSQLite(filepath.Join(tb.TempDir(), "jobs.db"), logger)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	tb.Cleanup(func() { _ = db.Close() })
+
+	repo := NewJobRepo(db, logger)
+
+	if err := repo.SyncJobs(names); err != nil {
+		tb.Fatal(err)
+	}
+
+	return repo, db
+}
+
+func TestUpdateLastSeenBatch(t *testing.T) {
+	repo, _ := newTestRepo(t, "app", "lib", "rerun")
+
+	// 不存在的 job 只记录警告，重新运行的构建只更新状态
+	assert.NoError(t, repo.UpdateLastSeenBatch(map[string]BuildUpdate{
+		"app":     {LastSeenBuild: 3, Status: "failure", Timestamp: 1700000000},
+		"lib":     {LastSeenBuild: 8, Status: "success"},
+		"rerun":   {Status: "aborted", Timestamp: 1700000100},
+		"missing": {LastSeenBuild: 9, Status: "success"},
+	}))
+	assert.NoError(t, repo.UpdateLastSeenBatch(nil))
+
+	jobs, err := repo.ListEnabledJobs()
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 3)
+
+	assert.Equal(t, "app", jobs[0].JobName)
+	assert.Equal(t, int64(3), jobs[0].LastSeenBuild)
+	assert.Equal(t, "failure", jobs[0].LastStatus)
+
+	if assert.NotNil(t, jobs[0].LastBuildStartedAt) {
+		assert.Equal(t, int64(1700000000), jobs[0].LastBuildStartedAt.Unix())
+	}
+
+	assert.Equal(t, "lib", jobs[1].JobName)
+	assert.Equal(t, int64(8), jobs[1].LastSeenBuild)
+	assert.Equal(t, "success", jobs[1].LastStatus)
+	assert.Nil(t, jobs[1].LastBuildStartedAt)
+
+	assert.Equal(t, "rerun", jobs[2].JobName)
+	assert.Equal(t, int64(0), jobs[2].LastSeenBuild)
+	assert.Equal(t, "aborted", jobs[2].LastStatus)
+}
+
+func TestPruneDisabledJobs(t *testing.T) {
+	repo, db := newTestRepo(t, "active", "recent", "old")
+
+	assert.NoError(t, repo.SyncJobs([]string{"active"}))
+
+	// old 在两天前最后一次被 Discovery 发现
+	past := time.Now().Add(-48 * time.Hour).Unix()
+	_, err := db.Exec(`UPDATE jobs SET last_sync_time = ? WHERE job_name = 'old'`, past)
+	assert.NoError(t, err)
+	_, err = db.Exec(`UPDATE job_changes SET event_time = ? WHERE job_name = 'old' AND action = 'ADD'`, past)
+	assert.NoError(t, err)
+
+	deleted, err := repo.PruneDisabledJobs(24 * time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	jobs, err := repo.ListAllJobs()
+	assert.NoError(t, err)
+
+	names := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		names = append(names, job.JobName)
+	}
+
+	// 最近禁用的 job 保留
+	assert.ElementsMatch(t, []string{"active", "recent"}, names)
+
+	deleted, err = repo.PruneJobChanges(24 * time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DBMetrics exports health information of the SQLite database.
+type DBMetrics struct {
+	path    string
+	walSize *prometheus.Desc
+}
+
+// NewDBMetrics creates a new DBMetrics instance for the database at path.
+func NewDBMetrics(path string) *DBMetrics {
+	return &DBMetrics{
+		path: path,
+		walSize: prometheus.NewDesc(
+			"jenkins_db_wal_size_bytes",
+			"Size of the SQLite write-ahead log in bytes",
+			nil,
+			nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (m *DBMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- m.walSize
+}
+
+// Collect implements prometheus.Collector, the WAL file gets checked on every
+// scrape.
+func (m *DBMetrics) Collect(ch chan<- prometheus.Metric) {
+	info, err := os.Stat(m.path + "-wal")
+
+	// 检查点之后 WAL 文件可能不存在，此时大小为 0
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		ch <- prometheus.NewInvalidMetric(m.walSize, err)
+		return
+	}
+
+	var size float64
+	if info != nil {
+		size = float64(info.Size())
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		m.walSize,
+		prometheus.GaugeValue,
+		size,
+	)
+}
@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBMetricsWALSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.db")
+	m := NewDBMetrics(path)
+
+	// WAL 文件不存在时导出 0
+	assert.Equal(t, 0.0, testutil.ToFloat64(m))
+
+	assert.NoError(t, os.WriteFile(path+"-wal", make([]byte, 4096), 0o600))
+
+	expected := `
+# HELP jenkins_db_wal_size_bytes Size of the SQLite write-ahead log in bytes
+# TYPE jenkins_db_wal_size_bytes gauge
+jenkins_db_wal_size_bytes 4096
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(m, strings.NewReader(expected)))
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+)
+
+// execer is implemented by both *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// migration defines a single schema change, the version gets stored within
+// PRAGMA user_version once it has been applied.
+type migration struct {
+	version     int
+	description string
+	apply       func(tx *sql.Tx, logger *slog.Logger) error
+}
+
+// migrations defines all schema changes ordered by version, existing entries
+// must never be changed, add a new migration instead.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "initial schema",
+		apply: func(tx *sql.Tx, logger *slog.Logger) error {
+			// 版本化之前创建的数据库可能缺少部分列，这里的语句都是幂等的
+			if err := createTables(tx, logger); err != nil {
+				return err
+			}
+
+			return createIndexes(tx, logger)
+		},
+	},
+	{
+		version:     2,
+		description: "last build status",
+		apply: func(tx *sql.Tx, _ *slog.Logger) error {
+			return addMissingColumns(tx, "jobs", map[string]string{
+				"last_status":          "TEXT NOT NULL DEFAULT ''",
+				"last_build_timestamp": "INTEGER",
+			})
+		},
+	},
+	{
+		version:     3,
+		description: "rename last build timestamp",
+		apply: func(tx *sql.Tx, _ *slog.Logger) error {
+			// last_build_started_at 是最后一次完成构建在 Jenkins 中的开始时间，
+			// last_build_time 是 Collector 发现构建编号增加的时间，用于 ?since= 查询，
+			// 两个列名太接近，容易在查询中混淆
+			if _, err := tx.Exec(`ALTER TABLE jobs RENAME COLUMN last_build_timestamp TO last_build_started_at`); err != nil {
+				return fmt.Errorf("failed to rename last_build_timestamp column: %w", err)
+			}
+
+			return nil
+		},
+	},
+}
+
+// migrate applies all migrations newer than the schema version of the
+// database, every migration runs within its own transaction.
+func migrate(db *sql.DB, logger *slog.Logger) error {
+	current, err := schemaVersion(db)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		logger.Info("正在执行数据库迁移",
+			"版本", m.version,
+			"说明", m.description,
+		)
+
+		if err := applyMigration(db, m, logger); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.version, m.description, err)
+		}
+	}
+
+	return nil
+}
+
+// applyMigration applies a single migration and stores its version.
+func applyMigration(db *sql.DB, m migration, logger *slog.Logger) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := m.apply(tx, logger); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", m.version)); err != nil {
+		return fmt.Errorf("failed to set schema version: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// schemaVersion returns the version of the last applied migration.
+func schemaVersion(db *sql.DB) (int, error) {
+	var version int
+
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"database/sql"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateOldSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.db")
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// 版本化之前创建的数据库，缺少后续新增的列和表
+	old, err := sql.Open("sqlite", path)
+	assert.NoError(t, err)
+
+	_, err = old.Exec(`
+	CREATE TABLE jobs (
+		job_name        TEXT PRIMARY KEY,
+		enabled         INTEGER NOT NULL DEFAULT 1,
+		last_seen_build INTEGER NOT NULL DEFAULT 0,
+		last_sync_time  INTEGER,
+		created_at      INTEGER NOT NULL
+	);
+	INSERT INTO jobs (job_name, last_seen_build, created_at) VALUES ('app', 7, 1700000000);`)
+	assert.NoError(t, err)
+	assert.NoError(t, old.Close())
+
+	db, err := NewSQLite(path, logger)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	version, err := schemaVersion(db)
+	assert.NoError(t, err)
+	assert.Equal(t, migrations[len(migrations)-1].version, version)
+
+	// 已有数据保留，新增的列可以正常使用
+	repo := NewJobRepo(db, logger)
+
+	changed, err := repo.UpdateConfigHash("app", "abc")
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	jobs, err := repo.ListEnabledJobs()
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, int64(7), jobs[0].LastSeenBuild)
+
+	assert.NoError(t, repo.SaveCounters([]Counter{{Name: "jenkins_collector_panics_total", Label: "app", Value: 1}}))
+	assert.NoError(t, db.Close())
+
+	// 再次打开时不会重复执行迁移
+	db, err = NewSQLite(path, logger)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Close())
+}
+
+func TestMigrateRenameLastBuildTimestamp(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.db")
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	db, err := sql.Open("sqlite", path)
+	assert.NoError(t, err)
+
+	// 数据库停留在第 2 个迁移，开始时间仍然保存在 last_build_timestamp 中
+	for _, m := range migrations[:2] {
+		assert.NoError(t, applyMigration(db, m, logger))
+	}
+
+	_, err = db.Exec(`INSERT INTO jobs (job_name, created_at, last_status, last_build_timestamp) VALUES ('app', 1700000000, 'failure', 1700000100)`)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Close())
+
+	db, err = NewSQLite(path, logger)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	jobs, err := NewJobRepo(db, logger).ListEnabledJobs()
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, "failure", jobs[0].LastStatus)
+
+	if assert.NotNil(t, jobs[0].LastBuildStartedAt) {
+		assert.Equal(t, int64(1700000100), jobs[0].LastBuildStartedAt.Unix())
+	}
+}
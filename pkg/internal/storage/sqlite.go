@@ -32,14 +32,9 @@ func NewSQLite(path string, logger *slog.Logger) (*sql.DB, error) {
 		}
 	}
 
-	// 创建表结构
-	if err := createTables(db, logger); err != nil {
-		return nil, fmt.Errorf("failed to create tables: %w", err)
-	}
-
-	// 创建索引
-	if err := createIndexes(db, logger); err != nil {
-		return nil, fmt.Errorf("failed to create indexes: %w", err)
+	// 按顺序执行尚未应用的迁移
+	if err := migrate(db, logger); err != nil {
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
 	logger.Info("SQLite 数据库初始化完成",
@@ -50,7 +45,7 @@ func NewSQLite(path string, logger *slog.Logger) (*sql.DB, error) {
 }
 
 // createTables creates the required database tables.
-func createTables(db *sql.DB, logger *slog.Logger) error {
+func createTables(db execer, logger *slog.Logger) error {
 	// 创建 jobs 表
 	jobsTable := `
 	CREATE TABLE IF NOT EXISTS jobs (
@@ -58,7 +53,9 @@ func createTables(db *sql.DB, logger *slog.Logger) error {
 		enabled         INTEGER NOT NULL DEFAULT 1,
 		last_seen_build INTEGER NOT NULL DEFAULT 0,
 		last_sync_time  INTEGER,
-		created_at      INTEGER NOT NULL
+		created_at      INTEGER NOT NULL,
+		config_hash     TEXT NOT NULL DEFAULT '',
+		last_build_time INTEGER
 	);`
 
 	if _, err := db.Exec(jobsTable); err != nil {
@@ -77,12 +74,76 @@ func createTables(db *sql.DB, logger *slog.Logger) error {
 		return fmt.Errorf("failed to create job_changes table: %w", err)
 	}
 
+	// 创建 counters 表，用于在重启后恢复计数器
+	countersTable := `
+	CREATE TABLE IF NOT EXISTS counters (
+		name  TEXT NOT NULL,
+		label TEXT NOT NULL DEFAULT '',
+		value REAL NOT NULL DEFAULT 0,
+		PRIMARY KEY (name, label)
+	);`
+
+	if _, err := db.Exec(countersTable); err != nil {
+		return fmt.Errorf("failed to create counters table: %w", err)
+	}
+
+	// 旧版本创建的 jobs 表缺少后续新增的列，需要补齐
+	if err := addMissingColumns(db, "jobs", map[string]string{
+		"config_hash":     "TEXT NOT NULL DEFAULT ''",
+		"last_build_time": "INTEGER",
+	}); err != nil {
+		return err
+	}
+
 	logger.Debug("数据库表创建完成")
 	return nil
 }
 
+// addMissingColumns adds columns which don't exist yet within the table.
+func addMissingColumns(db execer, table string, columns map[string]string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to read columns of %s table: %w", table, err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return fmt.Errorf("failed to scan column of %s table: %w", table, err)
+		}
+
+		existing[name] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating columns of %s table: %w", table, err)
+	}
+
+	for name, definition := range columns {
+		if existing[name] {
+			continue
+		}
+
+		if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, name, definition)); err != nil {
+			return fmt.Errorf("failed to add column %s to %s table: %w", name, table, err)
+		}
+	}
+
+	return nil
+}
+
 // createIndexes creates the required database indexes.
-func createIndexes(db *sql.DB, logger *slog.Logger) error {
+func createIndexes(db execer, logger *slog.Logger) error {
 	indexes := []string{
 		"CREATE INDEX IF NOT EXISTS idx_jobs_enabled ON jobs(enabled)",
 		"CREATE INDEX IF NOT EXISTS idx_jobs_enabled_lastseen ON jobs(enabled, last_seen_build)",